@@ -20,6 +20,7 @@ import (
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/config"
 	"golang.org/x/pkgsite/internal/dcensus"
+	"golang.org/x/pkgsite/internal/fetch"
 	"golang.org/x/pkgsite/internal/frontend"
 	"golang.org/x/pkgsite/internal/localdatasource"
 	"golang.org/x/pkgsite/internal/log"
@@ -28,7 +29,7 @@ import (
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/proxydatasource"
 	"golang.org/x/pkgsite/internal/queue"
-	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
 )
 
 var (
@@ -46,6 +47,7 @@ var (
 	localPaths         = flag.String("local", "", "run locally, accepts a GOPATH-like collection of local paths for modules to load to memory")
 	gopathMode         = flag.Bool("gopath_mode", false, "assume that local modules' paths are relative to GOPATH/src, used only with -local")
 	bypassLicenseCheck = flag.Bool("bypass_license_check", false, "display all information, even for non-redistributable paths")
+	zipCacheDir        = flag.String("zip_cache_dir", "", "directory in which to cache module zips for the source browser and code search")
 )
 
 func main() {
@@ -65,8 +67,9 @@ func main() {
 	log.SetLevel(cfg.LogLevel)
 
 	var (
-		dsg        func(context.Context) internal.DataSource
-		fetchQueue queue.Queue
+		dsg         func(context.Context) internal.DataSource
+		fetchQueue  queue.Queue
+		proxyClient *proxy.Client
 	)
 	if *bypassLicenseCheck {
 		log.Info(ctx, "BYPASSING LICENSE CHECKING: DISPLAYING NON-REDISTRIBUTABLE INFORMATION")
@@ -75,12 +78,18 @@ func main() {
 	log.Infof(ctx, "cmd/frontend: initializing cmdconfig.ExperimentGetter")
 	expg := cmdconfig.ExperimentGetter(ctx, cfg)
 	log.Infof(ctx, "cmd/frontend: initialized cmdconfig.ExperimentGetter")
+	cmdconfig.AddSourceHosts(ctx, cfg)
+	cmdconfig.SetLicensePolicy(ctx, cfg, time.Minute)
+	stdlib.LocalRoots = cfg.StdlibRoots
+	stdlib.ZipDir = cfg.StdlibZipDir
+	fetch.ShowUnexportedDocs = cfg.ShowUnexportedDocs
+	fetch.FallBackToVCS = cfg.FallBackToVCS
 
 	if *localPaths != "" {
 		lds := localdatasource.New()
 		dsg = func(context.Context) internal.DataSource { return lds }
 	} else {
-		proxyClient, err := proxy.New(*proxyURL)
+		proxyClient, err = proxy.New(*proxyURL)
 		if err != nil {
 			log.Fatal(ctx, err)
 		}
@@ -100,7 +109,7 @@ func main() {
 			}
 			defer db.Close()
 			dsg = func(context.Context) internal.DataSource { return db }
-			sourceClient := source.NewClient(config.SourceTimeout)
+			sourceClient := cmdconfig.SourceClient(ctx, cfg, db)
 			// The closure passed to queue.New is only used for testing and local
 			// execution, not in production. So it's okay that it doesn't use a
 			// per-request connection.
@@ -131,6 +140,10 @@ func main() {
 		AppVersionLabel:      cfg.AppVersionLabel(),
 		GoogleTagManagerID:   cfg.GoogleTagManagerID,
 		ServeStats:           cfg.ServeStats,
+		ProxyClient:          proxyClient,
+		SourceZipCacheDir:    *zipCacheDir,
+		ShowUnexportedDocs:   cfg.ShowUnexportedDocs,
+		SiteURL:              cfg.SiteURL,
 	})
 	if err != nil {
 		log.Fatalf(ctx, "frontend.NewServer: %v", err)
@@ -191,6 +204,7 @@ func main() {
 	mw := middleware.Chain(
 		middleware.RequestLog(cmdconfig.Logger(ctx, cfg, "frontend-log")),
 		middleware.AcceptRequests(http.MethodGet, http.MethodPost), // accept only GETs and POSTs
+		middleware.VanityImports(cmdconfig.VanityImports(ctx, cfg), cfg.VanityDocsURL),
 		middleware.Quota(cfg.Quota, cacheClient),
 		middleware.GodocURL(),                           // potentially redirects so should be early in chain
 		middleware.SecureHeaders(!*disableCSP),          // must come before any caching for nonces to work
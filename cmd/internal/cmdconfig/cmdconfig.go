@@ -8,6 +8,7 @@ package cmdconfig
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -19,9 +20,12 @@ import (
 	"golang.org/x/pkgsite/internal/config/dynconfig"
 	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/poller"
 	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/source"
 )
 
 // Logger configures a middleware.Logger.
@@ -92,6 +96,113 @@ func ExperimentGetter(ctx context.Context, cfg *config.Config) middleware.Experi
 	}
 }
 
+// AddSourceHosts adds the code hosts described by the source hosts file and the
+// dynamic config, if any, to the source package. It exits if either cannot be
+// read or describes an invalid host, so that misconfiguration is detected at
+// startup.
+func AddSourceHosts(ctx context.Context, cfg *config.Config) {
+	var hosts []*source.HostConfig
+	for _, loc := range []string{cfg.SourceHostsLocation, cfg.DynamicConfigLocation} {
+		if loc == "" {
+			continue
+		}
+		dc, err := dynconfig.Read(ctx, loc)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		hosts = append(hosts, dc.SourceHosts...)
+	}
+	if len(hosts) == 0 {
+		return
+	}
+	if err := source.AddHosts(hosts); err != nil {
+		log.Fatal(ctx, err)
+	}
+	log.Infof(ctx, "added %d source hosts", len(hosts))
+}
+
+// SetLicensePolicy sets the license policy described by the license policy file
+// or, if there is none, the dynamic config, and re-reads it every period until
+// ctx is done. If the policy cannot be read or is invalid, the error is logged
+// and the current policy, initially the default one, is kept.
+func SetLicensePolicy(ctx context.Context, cfg *config.Config, period time.Duration) {
+	var current *licenses.Policy
+	p := poller.New(nil, func(ctx context.Context) (interface{}, error) {
+		loc, lp, err := readLicensePolicy(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.DeepEqual(lp, current) {
+			return nil, nil
+		}
+		if err := licenses.SetPolicy(lp); err != nil {
+			return nil, err
+		}
+		current = lp
+		if loc == "" {
+			log.Infof(ctx, "using the default license policy")
+		} else {
+			log.Infof(ctx, "using license policy from %s", loc)
+		}
+		return nil, nil
+	}, func(err error) {
+		log.Errorf(ctx, "keeping the current license policy: %v", err)
+	})
+	p.Poll(ctx)
+	p.Start(ctx, period)
+}
+
+// readLicensePolicy reads the license policy from the license policy file or,
+// if there is none, the dynamic config. It returns the location it was read
+// from, or a nil policy and an empty location if there is none.
+func readLicensePolicy(ctx context.Context, cfg *config.Config) (loc string, _ *licenses.Policy, err error) {
+	for _, loc := range []string{cfg.LicensePolicyLocation, cfg.DynamicConfigLocation} {
+		if loc == "" {
+			continue
+		}
+		dc, err := dynconfig.Read(ctx, loc)
+		if err != nil {
+			return "", nil, err
+		}
+		if dc.LicensePolicy != nil {
+			return loc, dc.LicensePolicy, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// VanityImports returns the vanity import paths described by cfg. It exits if
+// any are malformed.
+func VanityImports(ctx context.Context, cfg *config.Config) []*source.VanityImport {
+	var imports []*source.VanityImport
+	for _, s := range cfg.VanityImports {
+		v, err := source.ParseVanityImport(s)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+		imports = append(imports, v)
+	}
+	return imports
+}
+
+// SourceClient returns a source.Client that authenticates to the hosts in
+// cfg.SourceCredentials. If db is non-nil, the client caches the results of
+// fetching meta tags in it.
+func SourceClient(ctx context.Context, cfg *config.Config, db *postgres.DB) *source.Client {
+	client := source.NewClient(config.SourceTimeout)
+	for host, c := range cfg.SourceCredentials {
+		if c.Token != "" {
+			client.SetHostCredentials(host, "", c.Token)
+		} else {
+			client.SetHostCredentials(host, c.User, c.Password)
+		}
+	}
+	if db != nil {
+		client.SetDiscoveryCache(db, cfg.SourceDiscoveryTTL)
+	}
+	return client
+}
+
 // OpenDB opens the postgres database specified by the config.
 // It first tries the main connection info (DBConnInfo), and if that fails, it uses backup
 // connection info it if exists (DBSecondaryConnInfo).
@@ -29,7 +29,7 @@ import (
 	"golang.org/x/pkgsite/internal/postgres"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/queue"
-	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/worker"
 )
 
@@ -70,6 +70,7 @@ func main() {
 	defer db.Close()
 
 	populateExcluded(ctx, db)
+	worker.PollLicenseExceptions(ctx, db, time.Minute)
 
 	indexClient, err := index.New(cfg.IndexURL)
 	if err != nil {
@@ -79,7 +80,13 @@ func main() {
 	if err != nil {
 		log.Fatal(ctx, err)
 	}
-	sourceClient := source.NewClient(config.SourceTimeout)
+	cmdconfig.AddSourceHosts(ctx, cfg)
+	cmdconfig.SetLicensePolicy(ctx, cfg, time.Minute)
+	stdlib.LocalRoots = cfg.StdlibRoots
+	stdlib.ZipDir = cfg.StdlibZipDir
+	fetch.ShowUnexportedDocs = cfg.ShowUnexportedDocs
+	fetch.FallBackToVCS = cfg.FallBackToVCS
+	sourceClient := cmdconfig.SourceClient(ctx, cfg, db)
 	expg := cmdconfig.ExperimentGetter(ctx, cfg)
 	fetchQueue, err := queue.New(ctx, cfg, queueName, *workers, expg,
 		func(ctx context.Context, modulePath, version string) (int, error) {
@@ -138,8 +145,8 @@ func main() {
 	}
 
 	iap := middleware.Identity()
-	if aud := os.Getenv("GO_DISCOVERY_IAP_AUDIENCE"); aud != "" {
-		iap = middleware.ValidateIAPHeader(aud)
+	if cfg.IAPAudience != "" {
+		iap = middleware.ValidateIAPHeader(cfg.IAPAudience)
 	}
 
 	mw := middleware.Chain(
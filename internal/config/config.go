@@ -70,6 +70,42 @@ func GetEnvFloat64(key string, fallback float64) float64 {
 	return fallback
 }
 
+// ShowUnexportedDocs reports whether the unexported declarations of the
+// packages in the module with the given path are kept at fetch time, so that
+// documentation for unexported identifiers can be displayed on request (with
+// ?m=all). Function bodies are not kept.
+//
+// A prefix in c.UnexportedDocModules matches the module with that path and
+// all modules below it. The value "*" matches every module, which is useful
+// for deployments that serve only internal code.
+//
+// The declarations are kept only by fetches made while the module matches, so
+// module versions fetched before a prefix was added must be reprocessed for
+// their unexported identifiers to be displayed.
+func (c *Config) ShowUnexportedDocs(modulePath string) bool {
+	return matchesModulePrefix(modulePath, c.UnexportedDocModules)
+}
+
+// FallBackToVCS reports whether the module with the given path is fetched
+// directly from its version control repository when the module proxy can't
+// serve it, like a module that is private or that the proxy has not seen.
+// Prefixes in c.VCSFallbackModules match modules as for ShowUnexportedDocs.
+func (c *Config) FallBackToVCS(modulePath string) bool {
+	return matchesModulePrefix(modulePath, c.VCSFallbackModules)
+}
+
+// matchesModulePrefix reports whether modulePath is equal to, or a path below,
+// one of prefixes. The prefix "*" matches any module path.
+func matchesModulePrefix(modulePath string, prefixes []string) bool {
+	for _, p := range prefixes {
+		p = strings.TrimSuffix(p, "/")
+		if p == "*" || modulePath == p || strings.HasPrefix(modulePath, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // AppVersionFormat is the expected format of the app version timestamp.
 const AppVersionFormat = "20060102t150405"
 
@@ -133,6 +169,11 @@ type Config struct {
 	// IAP that is gating access to the worker.
 	QueueAudience string
 
+	// IAPAudience is the audience of the JWTs with which the Identity-Aware
+	// Proxy that is gating access to the worker signs requests. If it is
+	// empty, requests to the worker are not checked.
+	IAPAudience string
+
 	// GoogleTagManagerID is the ID used for GoogleTagManager. It has the
 	// structure GTM-XXXX.
 	GoogleTagManagerID string
@@ -179,6 +220,67 @@ type Config struct {
 	// dynamic configuration.
 	DynamicConfigLocation string
 
+	// SourceHostsLocation is the location (either a file or gs://bucket/object)
+	// of a YAML file describing additional code hosts, in the same format as
+	// the SourceHosts field of the dynamic configuration.
+	SourceHostsLocation string
+
+	// LicensePolicyLocation is the location (either a file or
+	// gs://bucket/object) of a YAML file describing the license policy, in the
+	// same format as the LicensePolicy field of the dynamic configuration.
+	LicensePolicyLocation string
+
+	// VulnDBDir is a directory holding a vulnerability database in the OSV
+	// format, like a checkout of github.com/golang/vulndb. The worker loads
+	// its advisories into the database; if it is empty, vulnerabilities are
+	// not shown.
+	VulnDBDir string
+
+	// UnexportedDocModules are the module path prefixes of the modules whose
+	// packages keep their unexported declarations at fetch time, so that
+	// documentation for unexported identifiers can be displayed on request
+	// (with ?m=all). See ShowUnexportedDocs.
+	UnexportedDocModules []string
+
+	// VCSFallbackModules are the module path prefixes of the modules that are
+	// fetched from their repositories when the module proxy can't serve them.
+	// See FallBackToVCS.
+	VCSFallbackModules []string
+
+	// SourceCredentials are credentials for fetching go-import and go-source
+	// meta tags from private hosts, keyed by host. A host may begin with "*."
+	// to match its subdomains. They are read from a JSON object in
+	// GO_DISCOVERY_SOURCE_CREDENTIALS.
+	SourceCredentials map[string]SourceCredential `json:"-"`
+
+	// SiteURL is the canonical URL of the frontend, like
+	// "https://pkg.go.dev". It is used to build absolute URLs that must not
+	// depend on the host that a request was sent to.
+	SiteURL string
+
+	// VanityImports describe vanity import path prefixes whose go-import and
+	// go-source meta tags are served by the frontend, each in the form of the
+	// content of a go-import meta tag: "prefix vcs repoURL". The repo URL may
+	// contain "{name}"; see source.VanityImport.
+	VanityImports []string
+
+	// VanityDocsURL is the base URL to which browsers that visit a vanity
+	// import path are redirected. If it is empty, they are not redirected.
+	VanityDocsURL string
+
+	// StdlibRoots lists directories from which the standard library is read
+	// instead of from the Go repo; see stdlib.LocalRoots.
+	StdlibRoots []string
+
+	// StdlibZipDir is a directory in which the worker saves the zips of the
+	// standard library it creates, and from which the frontend's source
+	// browser reads them; see stdlib.ZipDir.
+	StdlibZipDir string
+
+	// SourceDiscoveryTTL is how long the results of fetching meta tags are
+	// cached in the database.
+	SourceDiscoveryTTL time.Duration
+
 	// ServeStats determines whether the server has an endpoint that serves statistics for
 	// benchmarking or other purposes.
 	ServeStats bool
@@ -367,6 +469,7 @@ func Init(ctx context.Context) (_ *Config, err error) {
 		GoogleTagManagerID: os.Getenv("GO_DISCOVERY_GOOGLE_TAG_MANAGER_ID"),
 		QueueURL:           os.Getenv("GO_DISCOVERY_QUEUE_URL"),
 		QueueAudience:      os.Getenv("GO_DISCOVERY_QUEUE_AUDIENCE"),
+		IAPAudience:        os.Getenv("GO_DISCOVERY_IAP_AUDIENCE"),
 
 		// LocationID is essentially hard-coded until we figure out a good way to
 		// determine it programmatically, but we check an environment variable in
@@ -412,9 +515,24 @@ func Init(ctx context.Context) (_ *Config, err error) {
 			SuccsToGreen:     GetEnvInt("GO_DISCOVERY_TEEPROXY_SUCCS_TO_GREEN", 20),
 		},
 		LogLevel:              os.Getenv("GO_DISCOVERY_LOG_LEVEL"),
+		SourceHostsLocation:   os.Getenv("GO_DISCOVERY_SOURCE_HOSTS"),
+		LicensePolicyLocation: os.Getenv("GO_DISCOVERY_LICENSE_POLICY"),
+		VulnDBDir:             os.Getenv("GO_DISCOVERY_VULN_DB_DIR"),
+		UnexportedDocModules:  parseCommaList(os.Getenv("GO_DISCOVERY_UNEXPORTED_DOC_MODULES")),
+		VCSFallbackModules:    parseCommaList(os.Getenv("GO_DISCOVERY_VCS_FALLBACK_MODULES")),
+		SiteURL:               GetEnv("GO_DISCOVERY_SITE_URL", "https://pkg.go.dev"),
+		VanityImports:         parseCommaList(os.Getenv("GO_DISCOVERY_VANITY_IMPORTS")),
+		VanityDocsURL:         os.Getenv("GO_DISCOVERY_VANITY_DOCS_URL"),
+		StdlibRoots:           parseCommaList(os.Getenv("GO_DISCOVERY_STDLIB_ROOTS")),
+		StdlibZipDir:          os.Getenv("GO_DISCOVERY_STDLIB_ZIP_DIR"),
+		SourceDiscoveryTTL:    time.Duration(GetEnvInt("GO_DISCOVERY_SOURCE_DISCOVERY_TTL_HOURS", 24)) * time.Hour,
 		ServeStats:            os.Getenv("GO_DISCOVERY_SERVE_STATS") == "true",
 		DisableErrorReporting: os.Getenv("GO_DISCOVERY_DISABLE_ERROR_REPORTING") == "true",
 	}
+	cfg.SourceCredentials, err = parseSourceCredentials(os.Getenv("GO_DISCOVERY_SOURCE_CREDENTIALS"))
+	if err != nil {
+		return nil, err
+	}
 	bucket := os.Getenv("GO_DISCOVERY_CONFIG_BUCKET")
 	object := os.Getenv("GO_DISCOVERY_CONFIG_DYNAMIC")
 	if bucket != "" {
@@ -617,6 +735,37 @@ func gceMetadata(ctx context.Context, name string) (_ string, err error) {
 	return string(bytes), nil
 }
 
+// SourceCredential is a credential for a private source host. Either Token,
+// which is sent as a bearer token, or User and Password, which are sent with
+// basic authentication, must be set.
+type SourceCredential struct {
+	Token    string `json:"token"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// parseSourceCredentials parses a JSON object mapping hosts to credentials,
+// like
+//
+//	{"git.example.com": {"token": "t"}, "*.corp.example.com": {"user": "u", "password": "p"}}
+//
+// The error it returns never contains the credentials.
+func parseSourceCredentials(s string) (map[string]SourceCredential, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var creds map[string]SourceCredential
+	if err := json.Unmarshal([]byte(s), &creds); err != nil {
+		return nil, errors.New("GO_DISCOVERY_SOURCE_CREDENTIALS is not a JSON object of host credentials")
+	}
+	for host, c := range creds {
+		if host == "" || (c.Token == "") == (c.User == "" || c.Password == "") {
+			return nil, fmt.Errorf("GO_DISCOVERY_SOURCE_CREDENTIALS: credential for host %q must have either a token or a user and password", host)
+		}
+	}
+	return creds, nil
+}
+
 func parseCommaList(s string) []string {
 	var a []string
 	for _, p := range strings.Split(s, ",") {
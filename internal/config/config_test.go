@@ -96,6 +96,56 @@ func TestParseCommaList(t *testing.T) {
 	}
 }
 
+func TestParseSourceCredentials(t *testing.T) {
+	got, err := parseSourceCredentials(`{
+		"git.example.com": {"token": "a,b=c"},
+		"*.corp.example.com": {"user": "u", "password": "p:q,r"}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]SourceCredential{
+		"git.example.com":    {Token: "a,b=c"},
+		"*.corp.example.com": {User: "u", Password: "p:q,r"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	for _, bad := range []string{
+		`git.example.com=token`,
+		`{"git.example.com": {}}`,
+		`{"git.example.com": {"user": "u"}}`,
+		`{"git.example.com": {"token": "t", "user": "u", "password": "p"}}`,
+		`{"": {"token": "t"}}`,
+	} {
+		if _, err := parseSourceCredentials(bad); err == nil {
+			t.Errorf("%s: got nil error", bad)
+		}
+	}
+}
+
+func TestMatchesModulePrefix(t *testing.T) {
+	prefixes := []string{"corp.example.com", "github.com/org/"}
+	for _, test := range []struct {
+		modulePath string
+		prefixes   []string
+		want       bool
+	}{
+		{"corp.example.com", prefixes, true},
+		{"corp.example.com/a/b", prefixes, true},
+		{"corp.example.community", prefixes, false},
+		{"github.com/org/repo", prefixes, true},
+		{"github.com/other/repo", prefixes, false},
+		{"golang.org/x/tools", nil, false},
+		{"golang.org/x/tools", []string{"*"}, true},
+	} {
+		if got := matchesModulePrefix(test.modulePath, test.prefixes); got != test.want {
+			t.Errorf("matchesModulePrefix(%q, %q) = %t, want %t", test.modulePath, test.prefixes, got, test.want)
+		}
+	}
+}
+
 func TestEnvAndApp(t *testing.T) {
 	for _, test := range []struct {
 		serviceID string
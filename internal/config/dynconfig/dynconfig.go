@@ -19,7 +19,9 @@ import (
 	"github.com/ghodss/yaml"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/source"
 )
 
 // DynamicConfig holds configuration that can change over the lifetime of the
@@ -29,6 +31,14 @@ type DynamicConfig struct {
 	// requires careful coordination with the config file contents.
 
 	Experiments []*internal.Experiment
+
+	// SourceHosts describes code hosts, in addition to the ones built into
+	// the source package, for which source links are constructed.
+	SourceHosts []*source.HostConfig
+
+	// LicensePolicy, if non-nil, replaces the default policy for deciding
+	// which licenses allow redistribution.
+	LicensePolicy *licenses.Policy
 }
 
 // Read reads dynamic configuration from the given location.
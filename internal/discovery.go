@@ -41,6 +41,17 @@ type ModuleInfo struct {
 	IsRedistributable bool
 	HasGoMod          bool // whether the module zip has a go.mod file
 	SourceInfo        *source.Info
+
+	// Deprecated reports whether the module is deprecated by a "Deprecated:"
+	// comment in the go.mod file of its latest version.
+	Deprecated bool
+	// DeprecationComment is the text following "Deprecated:", if any.
+	DeprecationComment string
+	// Retracted reports whether this version is retracted by the go.mod file
+	// of the latest version of the module.
+	Retracted bool
+	// RetractionRationale is the comment on the retract directive, if any.
+	RetractionRationale string
 }
 
 // VersionMap holds metadata associated with module queries for a version.
@@ -102,6 +113,9 @@ type Module struct {
 	// that may be contained in nested subdirectories.
 	Licenses []*licenses.License
 	Units    []*Unit
+	// GoMod holds the contents of the module's go.mod file. It is nil if the
+	// module zip has no go.mod file.
+	GoMod *GoMod
 }
 
 // Packages returns all of the units for a module that are packages.
@@ -184,6 +198,8 @@ type SearchResult struct {
 	Version     string
 	Synopsis    string
 	Licenses    []string
+	// Deprecated reports whether the package or its module is deprecated.
+	Deprecated bool
 
 	CommitTime time.Time
 	// Score is used to sort items in an array of SearchResult.
@@ -102,7 +102,9 @@ type FetchResult struct {
 
 // FetchModule queries the proxy or the Go repo for the requested module
 // version, downloads the module zip, and processes the contents to return an
-// *internal.Module and related information.
+// *internal.Module and related information. If the proxy can't serve a module
+// for which FallBackToVCS is true, the module is fetched from its repository
+// with FetchVCSModule instead.
 //
 // Even if err is non-nil, the result may contain useful information, like the go.mod path.
 //
@@ -158,6 +160,10 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 		}
 		info, err := getInfo(ctx, modulePath, requestedVersion)
 		if err != nil {
+			if shouldFallBackToVCS(ctx, modulePath, err) {
+				log.Infof(ctx, "proxy can't serve %s@%s (%v); fetching from its repository", modulePath, requestedVersion, err)
+				return fetchDirect(ctx, modulePath, requestedVersion, sourceClient)
+			}
 			fr.Error = err
 			return fr
 		}
@@ -200,15 +206,21 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 	}
 	startFetchInfo(fi)
 
+	var goModBytes []byte
 	if modulePath == stdlib.ModulePath {
-		zipReader, commitTime, err = stdlib.Zip(requestedVersion)
+		zipReader, commitTime, err = stdlib.Zip(fr.ResolvedVersion)
 		if err != nil {
 			fr.Error = err
 			return fr
 		}
+		// Save the zip for the frontend's source browser, which doesn't
+		// clone the Go repo itself.
+		if err := stdlib.SaveZip(zipReader, fr.ResolvedVersion); err != nil {
+			log.Errorf(ctx, "%v", err)
+		}
 		fr.GoModPath = stdlib.ModulePath
 	} else {
-		goModBytes, err := proxyClient.GetMod(ctx, modulePath, fr.ResolvedVersion)
+		goModBytes, err = proxyClient.GetMod(ctx, modulePath, fr.ResolvedVersion)
 		if err != nil {
 			fr.Error = err
 			return fr
@@ -232,7 +244,11 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 			return fr
 		}
 	}
-	mod, pvs, err := processZipFile(ctx, modulePath, fr.ResolvedVersion, commitTime, zipReader, sourceClient)
+	sourceInfo, err := source.ModuleInfo(ctx, sourceClient, modulePath, fr.ResolvedVersion)
+	if err != nil {
+		log.Infof(ctx, "error getting source info: %v", err)
+	}
+	mod, pvs, err := processZipFile(ctx, modulePath, fr.ResolvedVersion, commitTime, zipReader, sourceInfo)
 	if err != nil {
 		fr.Error = err
 		return fr
@@ -242,6 +258,11 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 	if modulePath == stdlib.ModulePath {
 		fr.Module.HasGoMod = true
 	}
+	// The proxy synthesizes a go.mod file for modules that don't have one, so
+	// only record the go.mod contents if the module zip contains the file.
+	if fr.Module.HasGoMod && goModBytes != nil {
+		addGoMod(ctx, fr.Module, goModBytes)
+	}
 	for _, state := range fr.PackageVersionStates {
 		if state.Status != http.StatusOK {
 			fr.Status = derrors.ToStatus(derrors.HasIncompletePackages)
@@ -250,17 +271,47 @@ func FetchModule(ctx context.Context, modulePath, requestedVersion string, proxy
 	return fr
 }
 
-// processZipFile extracts information from the module version zip.
-func processZipFile(ctx context.Context, modulePath string, resolvedVersion string, commitTime time.Time, zipReader *zip.Reader, sourceClient *source.Client) (_ *internal.Module, _ []*internal.PackageVersionState, err error) {
+// FallBackToVCS reports whether the module with the given path is fetched from
+// its repository when the proxy can't serve it. It is meant to be set at
+// startup, to config.Config.FallBackToVCS.
+var FallBackToVCS = func(modulePath string) bool { return false }
+
+// shouldFallBackToVCS reports whether the module with the given path should be
+// fetched from its repository after the proxy failed with err. Modules that
+// the proxy has not fetched only because fetching was disabled, and invalid
+// requests, are not.
+func shouldFallBackToVCS(ctx context.Context, modulePath string, err error) bool {
+	if !FallBackToVCS(modulePath) || ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, derrors.NotFetched) && !errors.Is(err, derrors.InvalidArgument)
+}
+
+// fetchDirect locates the repository of the module using its source
+// information and fetches the module from it with FetchVCSModule.
+func fetchDirect(ctx context.Context, modulePath, requestedVersion string, sourceClient *source.Client) *FetchResult {
+	info, err := source.ModuleInfo(ctx, sourceClient, modulePath, requestedVersion)
+	if err != nil {
+		err = fmt.Errorf("finding repository: %w", err)
+		return &FetchResult{
+			ModulePath:       modulePath,
+			RequestedVersion: requestedVersion,
+			Status:           derrors.ToStatus(err),
+			Error:            err,
+			Defer:            func() {},
+		}
+	}
+	return FetchVCSModule(ctx, modulePath, requestedVersion, info.RepoURL(), info.ModuleDir(), sourceClient)
+}
+
+// processZipFile extracts information from the module version zip. sourceInfo,
+// which may be nil, is used for links to the module's source.
+func processZipFile(ctx context.Context, modulePath string, resolvedVersion string, commitTime time.Time, zipReader *zip.Reader, sourceInfo *source.Info) (_ *internal.Module, _ []*internal.PackageVersionState, err error) {
 	defer derrors.Wrap(&err, "processZipFile(%q, %q)", modulePath, resolvedVersion)
 
 	ctx, span := trace.StartSpan(ctx, "fetch.processZipFile")
 	defer span.End()
 
-	sourceInfo, err := source.ModuleInfo(ctx, sourceClient, modulePath, resolvedVersion)
-	if err != nil {
-		log.Infof(ctx, "error getting source info: %v", err)
-	}
 	readmes, err := extractReadmesFromZip(modulePath, resolvedVersion, zipReader)
 	if err != nil {
 		return nil, nil, fmt.Errorf("extractReadmesFromZip(%q, %q, zipReader): %v", modulePath, resolvedVersion, err)
@@ -293,6 +344,18 @@ func processZipFile(ctx context.Context, modulePath string, resolvedVersion stri
 	}, packageVersionStates, nil
 }
 
+// addGoMod parses goModBytes and sets the GoMod field of m. A go.mod file that
+// cannot be parsed is logged but otherwise ignored, since the rest of the
+// module can still be served.
+func addGoMod(ctx context.Context, m *internal.Module, goModBytes []byte) {
+	gm, err := ParseGoMod(goModBytes)
+	if err != nil {
+		log.Infof(ctx, "%s@%s: %v", m.ModulePath, m.Version, err)
+		return
+	}
+	m.GoMod = gm
+}
+
 // moduleVersionDir formats the content subdirectory for the given
 // modulePath and version.
 func moduleVersionDir(modulePath, version string) string {
@@ -106,7 +106,7 @@ func TestFetchModule(t *testing.T) {
 				sortFetchResult(fr)
 				sortFetchResult(got)
 				opts := []cmp.Option{
-					cmpopts.IgnoreFields(internal.Documentation{}, "Source"),
+					cmpopts.IgnoreFields(internal.Documentation{}, "Source", "Coverage"),
 					cmpopts.IgnoreFields(internal.PackageVersionState{}, "Error"),
 					cmpopts.IgnoreFields(FetchResult{}, "Defer"),
 					cmp.AllowUnexported(source.Info{}),
@@ -104,6 +104,7 @@ var moduleMultiPackage = &testModule{
 				HasGoMod:   true,
 				SourceInfo: source.NewGitHubInfo("https://github.com/my/module", "", "v1.0.0"),
 			},
+			GoMod: &internal.GoMod{GoVersion: "1.12"},
 			Units: []*internal.Unit{
 				{
 					UnitMeta: internal.UnitMeta{
@@ -381,6 +382,7 @@ var moduleNonRedist = &testModule{
 				ModulePath: "nonredistributable.mod/module",
 				HasGoMod:   true,
 			},
+			GoMod: &internal.GoMod{GoVersion: "1.13"},
 			Units: []*internal.Unit{
 				{
 					UnitMeta: internal.UnitMeta{
@@ -35,7 +35,10 @@ var (
 // if the module has a go.mod file, but if both exist, then they must match.
 // FetchResult.Error should be checked to verify that the fetch succeeded. Even if the
 // error is non-nil the result may contain useful data.
-func FetchLocalModule(ctx context.Context, modulePath, localPath string, sourceClient *source.Client) *FetchResult {
+//
+// Source links are derived from the git repository containing localPath, if
+// any, and otherwise refer to the local files; see source.LocalInfo.
+func FetchLocalModule(ctx context.Context, modulePath, localPath string) *FetchResult {
 	fr := &FetchResult{
 		ModulePath:       modulePath,
 		RequestedVersion: LocalVersion,
@@ -85,7 +88,8 @@ func FetchLocalModule(ctx context.Context, modulePath, localPath string, sourceC
 	// Errors:
 	//   - Both are given and are different.
 	//   - Neither is given.
-	if goModBytes, err := ioutil.ReadFile(filepath.Join(localPath, "go.mod")); err != nil {
+	goModBytes, err := ioutil.ReadFile(filepath.Join(localPath, "go.mod"))
+	if err != nil {
 		fr.GoModPath = modulePath
 	} else {
 		fr.GoModPath = modfile.ModulePath(goModBytes)
@@ -107,7 +111,11 @@ func FetchLocalModule(ctx context.Context, modulePath, localPath string, sourceC
 		return fr
 	}
 
-	mod, pvs, err := processZipFile(ctx, fr.GoModPath, LocalVersion, LocalCommitTime, zipReader, sourceClient)
+	sourceInfo, err := source.LocalInfo(localPath)
+	if err != nil {
+		log.Infof(ctx, "error getting source info: %v", err)
+	}
+	mod, pvs, err := processZipFile(ctx, fr.GoModPath, LocalVersion, LocalCommitTime, zipReader, sourceInfo)
 	if err != nil {
 		fr.Error = err
 		return fr
@@ -115,7 +123,9 @@ func FetchLocalModule(ctx context.Context, modulePath, localPath string, sourceC
 
 	fr.Module = mod
 	fr.PackageVersionStates = pvs
-	fr.Module.SourceInfo = nil // version is not known, so even if info is found it most likely is wrong.
+	if goModBytes != nil {
+		addGoMod(ctx, fr.Module, goModBytes)
+	}
 	for _, state := range fr.PackageVersionStates {
 		if state.Status != http.StatusOK {
 			fr.Status = derrors.ToStatus(derrors.HasIncompletePackages)
@@ -0,0 +1,600 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	modzip "golang.org/x/mod/zip"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// vcsTimeout bounds the time to list the refs of a remote repository and to
+// clone it.
+const vcsTimeout = 5 * time.Minute
+
+// maxPseudoVersionDepth is the number of commits of the default branch that
+// are cloned to find the commit named by a pseudo-version. Older commits can't
+// be fetched directly from their repositories. It is a variable for testing.
+var maxPseudoVersionDepth = 1000
+
+// FetchVCSModule fetches a module version directly from its git repository,
+// the way the go command does with GOPROXY=direct, and processes its contents
+// to return an internal.Module and other related information.
+//
+// The module is in the directory moduleDir of the repository at repoURL, which
+// may also be the path of a local repository. requestedVersion may be a
+// semantic version, which must be tagged in the repository, a pseudo-version,
+// or "latest". The module zip is built according to the module zip
+// specification, using golang.org/x/mod/zip.
+//
+// A remote repository is cloned shallowly, with only the ref needed to
+// resolve requestedVersion, using the credentials for its host set on
+// sourceClient. The clone must complete within vcsTimeout. sourceClient is also used to construct source links for the
+// resolved version. FetchResult.Error should be checked to verify that the
+// fetch succeeded.
+func FetchVCSModule(ctx context.Context, modulePath, requestedVersion, repoURL, moduleDir string, sourceClient *source.Client) *FetchResult {
+	fr := &FetchResult{
+		ModulePath:       modulePath,
+		RequestedVersion: requestedVersion,
+		Defer:            func() {},
+	}
+	var fi *FetchInfo
+	defer func() {
+		if fr.Error != nil {
+			derrors.Wrap(&fr.Error, "FetchVCSModule(%q, %q, %q)", modulePath, requestedVersion, repoURL)
+			fr.Status = derrors.ToStatus(fr.Error)
+		}
+		if fr.Status == 0 {
+			fr.Status = http.StatusOK
+		}
+		if fi != nil {
+			finishFetchInfo(fi, fr.Status, fr.Error)
+		}
+		log.Debugf(ctx, "memory after fetch of %s@%s: %dM", modulePath, requestedVersion, allocMeg())
+	}()
+
+	tagPrefix, pathMajor, err := vcsTagPrefix(modulePath, moduleDir)
+	if err != nil {
+		fr.Error = err
+		return fr
+	}
+	repo, err := openVCSRepo(ctx, repoURL, tagPrefix, pathMajor, requestedVersion, sourceClient)
+	if err != nil {
+		fr.Error = err
+		return fr
+	}
+	resolvedVersion, commit, err := resolveVCSVersion(repo, tagPrefix, pathMajor, requestedVersion)
+	if err != nil {
+		fr.Error = err
+		return fr
+	}
+	fr.ResolvedVersion = resolvedVersion
+
+	fi = &FetchInfo{
+		ModulePath: modulePath,
+		Version:    resolvedVersion,
+		Start:      time.Now(),
+	}
+	startFetchInfo(fi)
+
+	tree, err := moduleTree(commit, modulePath, moduleDir)
+	if err != nil {
+		fr.Error = err
+		return fr
+	}
+	goModBytes, err := readTreeFile(tree, "go.mod")
+	if err != nil && !errors.Is(err, object.ErrFileNotFound) {
+		fr.Error = err
+		return fr
+	}
+	fr.GoModPath = modulePath
+	if goModBytes != nil {
+		fr.GoModPath = modfile.ModulePath(goModBytes)
+		if fr.GoModPath != modulePath {
+			fr.Error = fmt.Errorf("module path=%s, go.mod path=%s: %w", modulePath, fr.GoModPath, derrors.AlternativeModule)
+			return fr
+		}
+	}
+	zipReader, err := createZipFromTree(tree, modulePath, resolvedVersion)
+	if err != nil {
+		fr.Error = fmt.Errorf("%v: %w", err, derrors.BadModule)
+		return fr
+	}
+
+	sourceInfo, err := source.ModuleInfo(ctx, sourceClient, modulePath, resolvedVersion)
+	if err != nil {
+		log.Infof(ctx, "error getting source info: %v", err)
+	}
+	mod, pvs, err := processZipFile(ctx, modulePath, resolvedVersion, commit.Committer.When, zipReader, sourceInfo)
+	if err != nil {
+		fr.Error = err
+		return fr
+	}
+	fr.Module = mod
+	fr.PackageVersionStates = pvs
+	if goModBytes != nil {
+		addGoMod(ctx, fr.Module, goModBytes)
+	}
+	for _, state := range fr.PackageVersionStates {
+		if state.Status != http.StatusOK {
+			fr.Status = derrors.ToStatus(derrors.HasIncompletePackages)
+		}
+	}
+	return fr
+}
+
+// vcsTagPrefix returns the prefix of the tags of the module in directory
+// moduleDir of its repository, and the major version suffix of its path, as
+// returned by module.SplitPathVersion.
+//
+// As with the go command, the tags of a module in a subdirectory begin with
+// the directory, without any major version suffix.
+func vcsTagPrefix(modulePath, moduleDir string) (tagPrefix, pathMajor string, err error) {
+	_, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return "", "", fmt.Errorf("invalid module path %q: %w", modulePath, derrors.InvalidArgument)
+	}
+	tagPrefix = codeDir(moduleDir, pathMajor)
+	if tagPrefix != "" {
+		tagPrefix += "/"
+	}
+	return tagPrefix, pathMajor, nil
+}
+
+// openVCSRepo opens the git repository at repoURL. If repoURL is the path of a
+// local directory, the repository there, which may be bare, is opened in
+// place. Otherwise only the ref needed to resolve requestedVersion is cloned
+// into memory: the tag of a semantic version or of the latest version, with
+// no history, or the default branch for a module without tags, or for a
+// pseudo-version, with the last maxPseudoVersionDepth commits.
+func openVCSRepo(ctx context.Context, repoURL, tagPrefix, pathMajor, requestedVersion string, sourceClient *source.Client) (_ *git.Repository, err error) {
+	defer derrors.Wrap(&err, "openVCSRepo(%q, %q)", repoURL, requestedVersion)
+
+	if !strings.Contains(repoURL, "://") {
+		if fi, err := os.Stat(repoURL); err == nil && fi.IsDir() {
+			return git.PlainOpen(repoURL)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, vcsTimeout)
+	defer cancel()
+	auth := vcsAuth(repoURL, sourceClient)
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	refs, err := listRefs(ctx, remote, &git.ListOptions{Auth: auth})
+	if errors.Is(err, transport.ErrRepositoryNotFound) {
+		return nil, fmt.Errorf("%v: %w", err, derrors.NotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ref, depth, err := cloneRef(refs, tagPrefix, pathMajor, requestedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:           repoURL,
+		Auth:          auth,
+		ReferenceName: ref,
+		SingleBranch:  true,
+		Depth:         depth,
+		Tags:          git.NoTags,
+		NoCheckout:    true,
+	})
+}
+
+// listRefs returns the refs of remote. Remote.List doesn't take a context in
+// this version of go-git, so it runs in a goroutine, which is abandoned if ctx
+// is done first.
+func listRefs(ctx context.Context, remote *git.Remote, opts *git.ListOptions) ([]*plumbing.Reference, error) {
+	type result struct {
+		refs []*plumbing.Reference
+		err  error
+	}
+	c := make(chan result, 1)
+	go func() {
+		refs, err := remote.List(opts)
+		c <- result{refs, err}
+	}()
+	select {
+	case r := <-c:
+		return r.refs, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("listing refs: %w", ctx.Err())
+	}
+}
+
+// cloneRef returns the ref of a repository with the given refs to clone to
+// resolve requestedVersion, and the number of commits of history to clone.
+func cloneRef(refs []*plumbing.Reference, tagPrefix, pathMajor, requestedVersion string) (_ plumbing.ReferenceName, depth int, err error) {
+	switch {
+	case semver.IsValid(requestedVersion) && !version.IsPseudo(requestedVersion):
+		name := plumbing.NewTagReferenceName(tagPrefix + requestedVersion)
+		for _, r := range refs {
+			if r.Name() == name {
+				return name, 1, nil
+			}
+		}
+		return "", 0, fmt.Errorf("no tag %q: %w", tagPrefix+requestedVersion, derrors.NotFound)
+	case requestedVersion == "latest":
+		tags := map[string]*plumbing.Reference{}
+		for _, r := range refs {
+			if r.Name().IsTag() {
+				addModuleTag(tags, r, tagPrefix, pathMajor)
+			}
+		}
+		if latest := latestTag(tags); latest != "" {
+			return tags[latest].Name(), 1, nil
+		}
+		branch, err := defaultBranch(refs)
+		return branch, 1, err
+	default:
+		// A pseudo-version may name any commit on the default branch, but
+		// only recent ones are looked for.
+		branch, err := defaultBranch(refs)
+		return branch, maxPseudoVersionDepth, err
+	}
+}
+
+// defaultBranch returns the branch that HEAD refers to in refs.
+func defaultBranch(refs []*plumbing.Reference) (plumbing.ReferenceName, error) {
+	var head *plumbing.Reference
+	for _, r := range refs {
+		if r.Name() == plumbing.HEAD {
+			head = r
+		}
+	}
+	if head == nil {
+		return "", fmt.Errorf("no HEAD: %w", derrors.NotFound)
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target(), nil
+	}
+	for _, r := range refs {
+		if r.Name().IsBranch() && r.Hash() == head.Hash() {
+			return r.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("no branch at HEAD %s: %w", head.Hash(), derrors.NotFound)
+}
+
+// vcsAuth returns the credentials set on sourceClient for the host of
+// repoURL, or nil if there are none. As for meta tag requests, credentials are
+// only sent over HTTPS.
+func vcsAuth(repoURL string, sourceClient *source.Client) transport.AuthMethod {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Scheme != "https" {
+		return nil
+	}
+	user, password, ok := sourceClient.HostCredentials(u.Hostname())
+	if !ok {
+		return nil
+	}
+	if user == "" {
+		return &githttp.TokenAuth{Token: password}
+	}
+	return &githttp.BasicAuth{Username: user, Password: password}
+}
+
+// resolveVCSVersion resolves requestedVersion of the module with the given
+// tag prefix and major version suffix in repo to a semantic version and the
+// commit it refers to.
+func resolveVCSVersion(repo *git.Repository, tagPrefix, pathMajor, requestedVersion string) (_ string, _ *object.Commit, err error) {
+	defer derrors.Wrap(&err, "resolveVCSVersion(%q, %q, %q)", tagPrefix, pathMajor, requestedVersion)
+
+	switch {
+	case requestedVersion == "latest":
+		tags, err := moduleTags(repo, tagPrefix, pathMajor)
+		if err != nil {
+			return "", nil, err
+		}
+		if latest := latestTag(tags); latest != "" {
+			c, err := commitForRef(repo, tags[latest])
+			return latest, c, err
+		}
+		// There are no tags: use a pseudo-version for HEAD.
+		head, err := repo.Head()
+		if err != nil {
+			return "", nil, err
+		}
+		c, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return "", nil, err
+		}
+		major := "v0"
+		if pathMajor != "" {
+			major = module.PathMajorPrefix(pathMajor)
+		}
+		v := fmt.Sprintf("%s.0.0-%s-%s", major, c.Committer.When.UTC().Format("20060102150405"), c.Hash.String()[:12])
+		return v, c, nil
+
+	case version.IsPseudo(requestedVersion):
+		c, err := pseudoVersionCommit(repo, requestedVersion)
+		return requestedVersion, c, err
+
+	case semver.IsValid(requestedVersion):
+		if err := module.CheckPathMajor(requestedVersion, pathMajor); err != nil {
+			return "", nil, fmt.Errorf("%v: %w", err, derrors.InvalidArgument)
+		}
+		ref, err := repo.Tag(tagPrefix + requestedVersion)
+		if errors.Is(err, git.ErrTagNotFound) {
+			return "", nil, fmt.Errorf("no tag %q: %w", tagPrefix+requestedVersion, derrors.NotFound)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		c, err := commitForRef(repo, ref)
+		return requestedVersion, c, err
+
+	default:
+		return "", nil, fmt.Errorf("unsupported version %q: %w", requestedVersion, derrors.InvalidArgument)
+	}
+}
+
+// pseudoVersionCommit returns the commit named by pseudoVersion that is
+// reachable from HEAD. Commits are visited newest first, stopping at those
+// older than the pseudo-version's timestamp, which is the commit's time, or
+// at the oldest commit of a shallow clone.
+func pseudoVersionCommit(repo *git.Repository, pseudoVersion string) (_ *object.Commit, err error) {
+	i := strings.LastIndex(pseudoVersion, "-")
+	rev := pseudoVersion[i+1:]
+	stamp := pseudoVersion[:i]
+	if len(stamp) < 14 {
+		return nil, fmt.Errorf("malformed pseudo-version %q: %w", pseudoVersion, derrors.InvalidArgument)
+	}
+	t, err := time.Parse("20060102150405", stamp[len(stamp)-14:])
+	if err != nil {
+		return nil, fmt.Errorf("malformed pseudo-version %q: %w", pseudoVersion, derrors.InvalidArgument)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	// The parents of the oldest commits of a shallow clone are missing, so
+	// they must not be visited.
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, err
+	}
+	var missing []plumbing.Hash
+	for _, h := range shallow {
+		c, err := repo.CommitObject(h)
+		if err != nil {
+			return nil, err
+		}
+		missing = append(missing, c.ParentHashes...)
+	}
+	iter := object.NewCommitIterCTime(headCommit, nil, missing)
+	defer iter.Close()
+	for {
+		c, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c.Committer.When.Before(t) {
+			break
+		}
+		if strings.HasPrefix(c.Hash.String(), rev) {
+			return c, nil
+		}
+	}
+	if len(shallow) > 0 {
+		return nil, fmt.Errorf("no commit %q in the last %d commits of the default branch: %w", rev, maxPseudoVersionDepth, derrors.NotFound)
+	}
+	return nil, fmt.Errorf("no commit %q: %w", rev, derrors.NotFound)
+}
+
+// latestTag returns the latest version in tags, preferring releases to
+// prereleases as the go command does, or "" if tags is empty.
+func latestTag(tags map[string]*plumbing.Reference) string {
+	var latest string
+	for v := range tags {
+		isRelease := semver.Prerelease(v) == ""
+		latestIsRelease := latest != "" && semver.Prerelease(latest) == ""
+		if latest == "" || (isRelease && !latestIsRelease) ||
+			(isRelease == latestIsRelease && semver.Compare(v, latest) > 0) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// moduleTags returns the tags of repo that are canonical semantic versions of
+// the module with the given tag prefix and major version suffix, keyed by
+// version.
+func moduleTags(repo *git.Repository, tagPrefix, pathMajor string) (map[string]*plumbing.Reference, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]*plumbing.Reference{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		addModuleTag(tags, ref, tagPrefix, pathMajor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// addModuleTag adds ref to tags, keyed by version, if it is the tag of a
+// canonical semantic version of the module with the given tag prefix and major
+// version suffix.
+func addModuleTag(tags map[string]*plumbing.Reference, ref *plumbing.Reference, tagPrefix, pathMajor string) {
+	name := ref.Name().Short()
+	if !strings.HasPrefix(name, tagPrefix) {
+		return
+	}
+	v := strings.TrimPrefix(name, tagPrefix)
+	if semver.Canonical(v) == v && !version.IsPseudo(v) && module.CheckPathMajor(v, pathMajor) == nil {
+		tags[v] = ref
+	}
+}
+
+// codeDir returns moduleDir without the major version suffix pathMajor, as
+// returned by module.SplitPathVersion.
+func codeDir(moduleDir, pathMajor string) string {
+	if !strings.HasPrefix(pathMajor, "/") {
+		return moduleDir
+	}
+	if moduleDir == pathMajor[1:] {
+		return ""
+	}
+	return strings.TrimSuffix(moduleDir, pathMajor)
+}
+
+// commitForRef returns the commit that ref refers to, following annotated
+// tags.
+func commitForRef(repo *git.Repository, ref *plumbing.Reference) (*object.Commit, error) {
+	if tag, err := repo.TagObject(ref.Hash()); err == nil {
+		return tag.Commit()
+	}
+	return repo.CommitObject(ref.Hash())
+}
+
+// moduleTree returns the tree of the module's directory at commit. For a
+// module with a major version suffix, like example.com/m/v2, the module may
+// be in the major version subdirectory, or at moduleDir without the suffix
+// (the "major branch" convention); the subdirectory is used if it has a
+// go.mod file.
+func moduleTree(commit *object.Commit, modulePath, moduleDir string) (_ *object.Tree, err error) {
+	defer derrors.Wrap(&err, "moduleTree(%q, %q)", modulePath, moduleDir)
+
+	root, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	_, pathMajor, _ := module.SplitPathVersion(modulePath)
+	dirs := []string{moduleDir}
+	if d := codeDir(moduleDir, pathMajor); d != moduleDir {
+		dirs = append(dirs, d)
+	}
+	for i, dir := range dirs {
+		t := root
+		if dir != "" {
+			t, err = root.Tree(dir)
+			if errors.Is(err, object.ErrDirectoryNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if i < len(dirs)-1 {
+			if _, err := t.File("go.mod"); err != nil {
+				continue
+			}
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("directory %q not found: %w", moduleDir, derrors.NotFound)
+}
+
+// readTreeFile returns the contents of the file at name in t.
+func readTreeFile(t *object.Tree, name string) ([]byte, error) {
+	f, err := t.File(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// createZipFromTree creates a module zip for the files in t and returns a
+// reader for it. Files that don't belong in a module zip, like those in
+// nested modules and vendor directories, are omitted.
+func createZipFromTree(t *object.Tree, modulePath, resolvedVersion string) (_ *zip.Reader, err error) {
+	defer derrors.Wrap(&err, "createZipFromTree(%q, %q)", modulePath, resolvedVersion)
+
+	var files []modzip.File
+	err = t.Files().ForEach(func(f *object.File) error {
+		files = append(files, treeFile{f})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := modzip.Create(&buf, module.Version{Path: modulePath, Version: resolvedVersion}, files); err != nil {
+		return nil, err
+	}
+	br := bytes.NewReader(buf.Bytes())
+	return zip.NewReader(br, br.Size())
+}
+
+// treeFile adapts a file in a git tree to modzip.File.
+type treeFile struct {
+	f *object.File
+}
+
+func (t treeFile) Path() string { return t.f.Name }
+
+func (t treeFile) Lstat() (os.FileInfo, error) { return treeFileInfo{t.f}, nil }
+
+func (t treeFile) Open() (io.ReadCloser, error) { return t.f.Reader() }
+
+// treeFileInfo implements os.FileInfo for a file in a git tree.
+type treeFileInfo struct {
+	f *object.File
+}
+
+func (i treeFileInfo) Name() string { return path.Base(i.f.Name) }
+
+func (i treeFileInfo) Size() int64 { return i.f.Size }
+
+func (i treeFileInfo) Mode() os.FileMode {
+	// Symbolic links and submodules are reported as irregular files, which
+	// modzip omits.
+	m, err := i.f.Mode.ToOSFileMode()
+	if err != nil {
+		return os.ModeIrregular
+	}
+	return m
+}
+
+func (i treeFileInfo) ModTime() time.Time { return time.Time{} }
+
+func (i treeFileInfo) IsDir() bool { return false }
+
+func (i treeFileInfo) Sys() interface{} { return nil }
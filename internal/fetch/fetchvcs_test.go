@@ -0,0 +1,271 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
+)
+
+// testRepo builds a bare git repository in a temporary directory. It returns
+// the directory and the hashes of the commits.
+func testRepo(t *testing.T) (dir string, hashes []string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "fetchvcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault())
+	wtfs := memfs.New()
+	repo, err := git.Init(st, wtfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	commit := func(files map[string]string, tags ...string) {
+		t.Helper()
+		for name, contents := range files {
+			if err := util.WriteFile(wtfs, name, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := wt.Add("."); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: "Joe Random", Email: "joe@example.com", When: when}
+		h, err := wt.Commit("commit", &git.CommitOptions{All: true, Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, h.String())
+		for i, tag := range tags {
+			var opts *git.CreateTagOptions
+			if i%2 == 1 {
+				// Make every other tag annotated.
+				opts = &git.CreateTagOptions{Tagger: sig, Message: tag}
+			}
+			if _, err := repo.CreateTag(tag, h, opts); err != nil {
+				t.Fatal(err)
+			}
+		}
+		when = when.Add(time.Hour)
+	}
+	commit(map[string]string{
+		"go.mod":             "module example.com/m\n",
+		"a.go":               "// Package m is a module.\npackage m\n",
+		"sub/go.mod":         "module example.com/m/sub\n",
+		"sub/b.go":           "package sub\n",
+		"vendor/x/x.go":      "package x\n",
+		"vendor/modules.txt": "",
+	}, "v1.0.0", "sub/v1.0.0")
+	commit(map[string]string{
+		"c/c.go": "package c\n",
+	}, "v1.1.0-pre", "v1.1.0")
+	commit(map[string]string{
+		"d/d.go": "package d\n",
+	})
+	return dir, hashes
+}
+
+func TestFetchVCSModule(t *testing.T) {
+	dochtml.LoadTemplates(templateSource)
+	dir, hashes := testRepo(t)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		modulePath, moduleDir, version string
+		wantVersion                    string
+		wantUnits                      []string
+	}{
+		{
+			"example.com/m", "", "v1.0.0",
+			"v1.0.0",
+			[]string{"example.com/m"},
+		},
+		{
+			"example.com/m", "", "latest",
+			"v1.1.0",
+			[]string{"example.com/m", "example.com/m/c"},
+		},
+		{
+			"example.com/m", "", "v0.0.0-20200102050405-" + hashes[2][:12],
+			"v0.0.0-20200102050405-" + hashes[2][:12],
+			[]string{"example.com/m", "example.com/m/c", "example.com/m/d"},
+		},
+		{
+			"example.com/m/sub", "sub", "latest",
+			"v1.0.0",
+			[]string{"example.com/m/sub"},
+		},
+	} {
+		// Open the local repository in place, and also clone it.
+		for name, repoURL := range map[string]string{"open": dir, "clone": "file://" + dir} {
+			t.Run(name+"/"+test.modulePath+"@"+test.version, func(t *testing.T) {
+				fr := FetchVCSModule(ctx, test.modulePath, test.version, repoURL, test.moduleDir, nil)
+				if fr.Error != nil {
+					t.Fatal(fr.Error)
+				}
+				if fr.ResolvedVersion != test.wantVersion {
+					t.Errorf("got version %q, want %q", fr.ResolvedVersion, test.wantVersion)
+				}
+				var got []string
+				for _, u := range fr.Module.Units {
+					got = append(got, u.Path)
+				}
+				sort.Strings(got)
+				if diff := cmp.Diff(test.wantUnits, got); diff != "" {
+					t.Errorf("units mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestFetchVCSModuleErrors(t *testing.T) {
+	dir, _ := testRepo(t)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		modulePath, version string
+		wantStatus          int
+	}{
+		{"example.com/m", "v1.2.3", http.StatusNotFound},
+		{"example.com/m", "v0.0.0-20200102030405-000000000000", http.StatusNotFound},
+		{"example.com/m", "master", http.StatusBadRequest},
+		{"example.com/m/v2", "v1.0.0", http.StatusBadRequest},
+		{"example.com/other", "v1.0.0", derrors.ToStatus(derrors.AlternativeModule)},
+	} {
+		for _, repoURL := range []string{dir, "file://" + dir} {
+			fr := FetchVCSModule(ctx, test.modulePath, test.version, repoURL, "", nil)
+			if fr.Status != test.wantStatus {
+				t.Errorf("%s %s@%s: got status %d, want %d (err=%v)", repoURL, test.modulePath, test.version, fr.Status, test.wantStatus, fr.Error)
+			}
+		}
+	}
+}
+
+func TestFetchVCSModulePseudoVersionDepth(t *testing.T) {
+	dochtml.LoadTemplates(templateSource)
+	dir, hashes := testRepo(t)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	defer func(d int) { maxPseudoVersionDepth = d }(maxPseudoVersionDepth)
+	maxPseudoVersionDepth = 2
+
+	// Only the last two commits are cloned.
+	for _, test := range []struct {
+		version    string
+		wantStatus int
+	}{
+		{"v0.0.0-20200102050405-" + hashes[2][:12], http.StatusOK},
+		{"v0.0.0-20200102040405-" + hashes[1][:12], http.StatusOK},
+		{"v0.0.0-20200102030405-" + hashes[0][:12], http.StatusNotFound},
+	} {
+		fr := FetchVCSModule(ctx, "example.com/m", test.version, "file://"+dir, "", nil)
+		if fr.Status != test.wantStatus {
+			t.Errorf("%s: got status %d, want %d (err=%v)", test.version, fr.Status, test.wantStatus, fr.Error)
+		}
+	}
+}
+
+func TestCloneRef(t *testing.T) {
+	hash := plumbing.NewHash("0123456789012345678901234567890123456789")
+	refs := []*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main"),
+		plumbing.NewHashReference("refs/heads/main", hash),
+		plumbing.NewHashReference("refs/tags/v1.0.0", hash),
+		plumbing.NewHashReference("refs/tags/v1.1.0-pre", hash),
+		plumbing.NewHashReference("refs/tags/sub/v1.2.0", hash),
+		plumbing.NewHashReference("refs/tags/v2.0.0", hash),
+	}
+	for _, test := range []struct {
+		tagPrefix, pathMajor, version string
+		wantRef                       plumbing.ReferenceName
+		wantDepth                     int
+	}{
+		{"", "", "v1.0.0", "refs/tags/v1.0.0", 1},
+		{"", "", "latest", "refs/tags/v1.0.0", 1},
+		{"sub/", "", "latest", "refs/tags/sub/v1.2.0", 1},
+		{"", "/v2", "latest", "refs/tags/v2.0.0", 1},
+		{"", "/v3", "latest", "refs/heads/main", 1},
+		{"", "", "v0.0.0-20200102050405-0123456789ab", "refs/heads/main", maxPseudoVersionDepth},
+	} {
+		ref, depth, err := cloneRef(refs, test.tagPrefix, test.pathMajor, test.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref != test.wantRef || depth != test.wantDepth {
+			t.Errorf("%q, %q, %q: got %s, %d; want %s, %d", test.tagPrefix, test.pathMajor, test.version, ref, depth, test.wantRef, test.wantDepth)
+		}
+	}
+	if _, _, err := cloneRef(refs, "", "", "v1.2.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("missing tag: got %v, want NotFound", err)
+	}
+}
+
+func TestShouldFallBackToVCS(t *testing.T) {
+	defer func(f func(string) bool) { FallBackToVCS = f }(FallBackToVCS)
+	FallBackToVCS = func(modulePath string) bool { return modulePath == "private.example.com/m" }
+
+	ctx := context.Background()
+	for _, test := range []struct {
+		modulePath string
+		err        error
+		want       bool
+	}{
+		{"private.example.com/m", derrors.NotFound, true},
+		{"private.example.com/m", derrors.ProxyTimedOut, true},
+		{"private.example.com/m", derrors.NotFetched, false},
+		{"private.example.com/m", derrors.InvalidArgument, false},
+		{"example.com/m", derrors.NotFound, false},
+	} {
+		if got := shouldFallBackToVCS(ctx, test.modulePath, test.err); got != test.want {
+			t.Errorf("%s, %v: got %t, want %t", test.modulePath, test.err, got, test.want)
+		}
+	}
+}
+
+func TestCodeDir(t *testing.T) {
+	for _, test := range []struct {
+		moduleDir, pathMajor, want string
+	}{
+		{"", "", ""},
+		{"sub", "", "sub"},
+		{"v2", "/v2", ""},
+		{"sub/v2", "/v2", "sub"},
+		{"subv2", "/v2", "subv2"},
+		{"", ".v2", ""},
+	} {
+		if got := codeDir(test.moduleDir, test.pathMajor); got != test.want {
+			t.Errorf("codeDir(%q, %q) = %q, want %q", test.moduleDir, test.pathMajor, got, test.want)
+		}
+	}
+}
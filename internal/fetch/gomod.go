@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// ParseGoMod parses the contents of a go.mod file into an internal.GoMod.
+//
+// The go.mod file is parsed strictly, so that replace and exclude directives
+// are retained. If that fails (for instance, because the file uses a
+// directive that is newer than our copy of x/mod), it is parsed again
+// leniently, which drops unknown and main-module-only directives.
+func ParseGoMod(contents []byte) (_ *internal.GoMod, err error) {
+	defer derrors.Wrap(&err, "ParseGoMod")
+
+	f, err := modfile.Parse("go.mod", contents, nil)
+	if err != nil {
+		f, err = modfile.ParseLax("go.mod", contents, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	gm := &internal.GoMod{}
+	if f.Go != nil {
+		gm.GoVersion = f.Go.Version
+	}
+	if f.Module != nil {
+		gm.Deprecated, gm.DeprecationComment = parseDeprecation(f.Module.Syntax)
+	}
+	for _, r := range f.Require {
+		gm.Requires = append(gm.Requires, &internal.GoModRequire{
+			ModulePath: r.Mod.Path,
+			Version:    r.Mod.Version,
+			Indirect:   r.Indirect,
+		})
+	}
+	for _, r := range f.Replace {
+		gm.Replaces = append(gm.Replaces, &internal.GoModReplace{
+			OldPath:    r.Old.Path,
+			OldVersion: r.Old.Version,
+			NewPath:    r.New.Path,
+			NewVersion: r.New.Version,
+		})
+	}
+	for _, e := range f.Exclude {
+		gm.Excludes = append(gm.Excludes, &internal.GoModExclude{
+			ModulePath: e.Mod.Path,
+			Version:    e.Mod.Version,
+		})
+	}
+	for _, r := range f.Retract {
+		gm.Retracts = append(gm.Retracts, &internal.GoModRetract{
+			Low:       r.Low,
+			High:      r.High,
+			Rationale: r.Rationale,
+		})
+	}
+	return gm, nil
+}
+
+// deprecatedRegexp matches a paragraph beginning with "Deprecated:", capturing
+// the rest of the paragraph.
+var deprecatedRegexp = regexp.MustCompile(`(?s)(?:^|\n\n)Deprecated:\s*(.*?)(?:$|\n\n)`)
+
+// parseDeprecation reports whether the comments before and after the module
+// directive line contain a deprecation paragraph, and if so returns its text.
+// This follows the convention the go command uses for deprecated modules.
+func parseDeprecation(line *modfile.Line) (deprecated bool, comment string) {
+	if line == nil {
+		return false, ""
+	}
+	var lines []string
+	for _, cs := range [][]modfile.Comment{line.Comments.Before, line.Comments.Suffix} {
+		for _, c := range cs {
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(c.Token, "//")))
+		}
+	}
+	m := deprecatedRegexp.FindStringSubmatch(strings.Join(lines, "\n"))
+	if m == nil {
+		return false, ""
+	}
+	return true, strings.TrimSpace(m[1])
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestParseGoMod(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		contents string
+		want     *internal.GoMod
+	}{
+		{
+			name:     "module only",
+			contents: "module example.com/m",
+			want:     &internal.GoMod{},
+		},
+		{
+			name: "all directives",
+			contents: `
+				module example.com/m
+
+				go 1.15
+
+				require (
+					example.com/a v1.2.3
+					example.com/b v0.1.0 // indirect
+				)
+
+				replace example.com/a => ../a
+
+				replace example.com/b v0.1.0 => example.com/c v0.2.0
+
+				exclude example.com/a v1.2.2
+
+				retract (
+					v1.0.1 // Published accidentally.
+					[v1.1.0, v1.1.5]
+				)`,
+			want: &internal.GoMod{
+				GoVersion: "1.15",
+				Requires: []*internal.GoModRequire{
+					{ModulePath: "example.com/a", Version: "v1.2.3"},
+					{ModulePath: "example.com/b", Version: "v0.1.0", Indirect: true},
+				},
+				Replaces: []*internal.GoModReplace{
+					{OldPath: "example.com/a", NewPath: "../a"},
+					{OldPath: "example.com/b", OldVersion: "v0.1.0", NewPath: "example.com/c", NewVersion: "v0.2.0"},
+				},
+				Excludes: []*internal.GoModExclude{
+					{ModulePath: "example.com/a", Version: "v1.2.2"},
+				},
+				Retracts: []*internal.GoModRetract{
+					{Low: "v1.0.1", High: "v1.0.1", Rationale: "Published accidentally."},
+					{Low: "v1.1.0", High: "v1.1.5"},
+				},
+			},
+		},
+		{
+			name: "deprecated",
+			contents: `
+				// Deprecated: use example.com/m/v2 instead.
+				module example.com/m`,
+			want: &internal.GoMod{
+				Deprecated:         true,
+				DeprecationComment: "use example.com/m/v2 instead.",
+			},
+		},
+		{
+			name: "deprecated in later paragraph",
+			contents: `
+				// Package m does things.
+				//
+				// Deprecated: this module is
+				// no longer maintained.
+				module example.com/m`,
+			want: &internal.GoMod{
+				Deprecated:         true,
+				DeprecationComment: "this module is\nno longer maintained.",
+			},
+		},
+		{
+			name:     "deprecated suffix comment",
+			contents: `module example.com/m // Deprecated: do not use.`,
+			want: &internal.GoMod{
+				Deprecated:         true,
+				DeprecationComment: "do not use.",
+			},
+		},
+		{
+			name: "not deprecated",
+			contents: `
+				// This module is not Deprecated: really.
+				module example.com/m`,
+			want: &internal.GoMod{},
+		},
+		{
+			name: "unknown directive",
+			contents: `
+				module example.com/m
+
+				go 1.15
+
+				toolchain go1.21
+
+				require example.com/a v1.2.3`,
+			want: &internal.GoMod{
+				GoVersion: "1.15",
+				Requires: []*internal.GoModRequire{
+					{ModulePath: "example.com/a", Version: "v1.2.3"},
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseGoMod([]byte(test.contents))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseGoModError(t *testing.T) {
+	if _, err := ParseGoMod([]byte("module example.com/m\nrequire (")); err == nil {
+		t.Error("got nil, want error")
+	}
+}
@@ -159,8 +159,7 @@ func localFetcher(t *testing.T, withLicenseDetector bool, ctx context.Context, m
 	defer os.RemoveAll(directory)
 
 	modulePath := mod.mod.ModulePath
-	sourceClient := source.NewClient(sourceTimeout)
-	got := FetchLocalModule(ctx, modulePath, directory, sourceClient)
+	got := FetchLocalModule(ctx, modulePath, directory)
 	if !withLicenseDetector {
 		return got, nil
 	}
@@ -45,6 +45,12 @@ type BadPackageError struct {
 
 func (bpe *BadPackageError) Error() string { return bpe.Err.Error() }
 
+// ShowUnexportedDocs reports whether the unexported declarations of the
+// packages in the module with the given path are kept, so that documentation
+// for unexported identifiers can be displayed. It is meant to be set at
+// startup, to config.Config.ShowUnexportedDocs.
+var ShowUnexportedDocs = func(modulePath string) bool { return false }
+
 // Go environments used to construct build contexts in loadPackage.
 var goEnvs = []struct{ GOOS, GOARCH string }{
 	{"linux", "amd64"},
@@ -105,14 +111,18 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 	}
 	docPkg := godoc.NewPackage(fset, goos, goarch, modInfo.ModulePackages)
 	for _, pf := range goFiles {
-		var removeNodes bool
-		removeNodes = true
-		// Don't strip the seemingly unexported functions from the builtin package;
-		// they are actually Go builtins like make, new, etc.
-		if modulePath == stdlib.ModulePath && innerPath == "builtin" {
-			removeNodes = false
+		switch {
+		case modulePath == stdlib.ModulePath && innerPath == "builtin":
+			// Don't strip the seemingly unexported functions from the builtin package;
+			// they are actually Go builtins like make, new, etc.
+			docPkg.AddFile(pf, false)
+		case ShowUnexportedDocs(modulePath):
+			// Keep unexported declarations, but not function bodies, for
+			// modules whose unexported documentation can be viewed.
+			docPkg.AddFileWithUnexported(pf)
+		default:
+			docPkg.AddFile(pf, true)
 		}
-		docPkg.AddFile(pf, removeNodes)
 	}
 
 	// Encode before rendering: both operations mess with the AST, but Encode restores
@@ -122,7 +132,7 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 		return nil, err
 	}
 
-	synopsis, imports, _, err := docPkg.Render(ctx, innerPath, sourceInfo, modInfo, goos, goarch)
+	synopsis, imports, info, _, err := docPkg.Render(ctx, innerPath, sourceInfo, modInfo, goos, goarch)
 	if err != nil && !errors.Is(err, godoc.ErrTooLarge) {
 		return nil, err
 	}
@@ -131,15 +141,25 @@ func loadPackageWithBuildContext(ctx context.Context, goos, goarch string, zipGo
 		importPath = innerPath
 	}
 	v1path := internal.V1Path(importPath, modulePath)
+	var (
+		deprecated bool
+		coverage   *internal.DocCoverage
+	)
+	if info != nil {
+		deprecated = info.Deprecated
+		coverage = info.Coverage
+	}
 	return &goPackage{
-		path:     importPath,
-		name:     packageName,
-		synopsis: synopsis,
-		v1path:   v1path,
-		imports:  imports,
-		goos:     goos,
-		goarch:   goarch,
-		source:   src,
+		path:       importPath,
+		name:       packageName,
+		synopsis:   synopsis,
+		deprecated: deprecated,
+		coverage:   coverage,
+		v1path:     v1path,
+		imports:    imports,
+		goos:       goos,
+		goarch:     goarch,
+		source:     src,
 	}, err
 }
 
@@ -31,6 +31,8 @@ type goPackage struct {
 	path              string
 	name              string
 	synopsis          string
+	deprecated        bool // the package documentation has a "Deprecated:" paragraph
+	coverage          *internal.DocCoverage
 	imports           []string
 	isRedistributable bool
 	licenseMeta       []*licenses.Metadata // metadata of applicable licenses
@@ -62,10 +62,12 @@ func moduleUnits(modulePath, version string,
 			dir.Name = pkg.name
 			dir.Imports = pkg.imports
 			dir.Documentation = &internal.Documentation{
-				GOOS:     pkg.goos,
-				GOARCH:   pkg.goarch,
-				Synopsis: pkg.synopsis,
-				Source:   pkg.source,
+				GOOS:       pkg.goos,
+				GOARCH:     pkg.goarch,
+				Synopsis:   pkg.synopsis,
+				Deprecated: pkg.deprecated,
+				Coverage:   pkg.coverage,
+				Source:     pkg.source,
 			}
 		}
 		units = append(units, dir)
@@ -19,6 +19,9 @@ type badgePage struct {
 	LinkPath string
 	// BadgePath is the URL path of the badge SVG.
 	BadgePath string
+	// CoverageBadgePath is the URL path of the documentation coverage badge
+	// SVG.
+	CoverageBadgePath string
 }
 
 // badgeHandler serves a Go SVG badge image for requests to /badge/<path>
@@ -39,10 +42,11 @@ func (s *Server) badgeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	page := badgePage{
-		basePage:  s.newBasePage(r, "Badge generation tool"),
-		SiteURL:   "https://" + r.Host,
-		LinkPath:  path,
-		BadgePath: "badge/" + path + ".svg",
+		basePage:          s.newBasePage(r, "Badge generation tool"),
+		SiteURL:           "https://" + r.Host,
+		LinkPath:          path,
+		BadgePath:         "badge/" + path + ".svg",
+		CoverageBadgePath: "coverage/" + path + ".svg",
 	}
 	s.servePage(r.Context(), w, "badge.tmpl", page)
 }
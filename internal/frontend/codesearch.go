@@ -0,0 +1,183 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/godoc"
+)
+
+// Code search constraints.
+const (
+	// maxCodeSearchQueryLength is the maximum length of a code search query.
+	maxCodeSearchQueryLength = 200
+
+	// maxCodeSearchMatches is the maximum number of matching lines returned
+	// by a code search.
+	maxCodeSearchMatches = 500
+
+	// codeSearchTimeout bounds the time spent searching the files of a
+	// module. It does not include the time to download the module zip.
+	codeSearchTimeout = 10 * time.Second
+
+	// maxMatchContext is the number of characters displayed on either side
+	// of a match.
+	maxMatchContext = 100
+)
+
+// CodeSearch holds a code search query and its results.
+type CodeSearch struct {
+	Query      string
+	Regexp     bool // Query is a regular expression, not a literal string
+	IgnoreCase bool
+
+	Matches []*SourceMatch
+
+	// Incomplete reports whether the search stopped early, because it found
+	// too many matches or took too long.
+	Incomplete bool
+
+	// Message, if non-empty, explains why the search could not be run.
+	Message string
+}
+
+// SourceMatch is a line of a file that matches a code search.
+type SourceMatch struct {
+	File string // path relative to the module root
+	Line int
+	URL  string
+
+	// The line is split into the text before the first match, the match, and
+	// the text after it.
+	Before, Match, After string
+}
+
+// newCodeSearch returns a CodeSearch for the given query and options. It does
+// not run the search.
+func newCodeSearch(q string, isRegexp, ignoreCase bool) *CodeSearch {
+	return &CodeSearch{Query: q, Regexp: isRegexp, IgnoreCase: ignoreCase}
+}
+
+// regexp returns the regular expression that matches the query. If the query
+// is invalid, it returns nil and a message for the user.
+func (cs *CodeSearch) regexp() (*regexp.Regexp, string) {
+	if len(cs.Query) > maxCodeSearchQueryLength {
+		return nil, fmt.Sprintf("The search query is longer than %d characters.", maxCodeSearchQueryLength)
+	}
+	expr := cs.Query
+	if !cs.Regexp {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if cs.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Sprintf("Invalid regular expression: %v", err)
+	}
+	return re, ""
+}
+
+// searchSourceFiles runs the code search cs over the files of the given
+// module version whose paths are equal to or under scope, and stores the
+// results in cs. files maps paths relative to the module root to zip files.
+//
+// Files larger than maxSourceFileSize and files that are not UTF-8 text are
+// skipped.
+func searchSourceFiles(ctx context.Context, cs *CodeSearch, files map[string]*zip.File, modulePath, version, scope string) (err error) {
+	defer derrors.Wrap(&err, "searchSourceFiles(%q, %q, %q, %q)", cs.Query, modulePath, version, scope)
+
+	re, msg := cs.regexp()
+	if re == nil {
+		cs.Message = msg
+		return nil
+	}
+	var names []string
+	for name := range files {
+		if scope == "" || name == scope || strings.HasPrefix(name, scope+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(ctx, codeSearchTimeout)
+	defer cancel()
+	for _, name := range names {
+		if ctx.Err() != nil {
+			cs.Incomplete = true
+			return nil
+		}
+		f := files[name]
+		if f.UncompressedSize64 > maxSourceFileSize {
+			continue
+		}
+		contents, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(contents) || !re.Match(contents) {
+			continue
+		}
+		for i, line := range strings.Split(string(contents), "\n") {
+			loc := re.FindStringIndex(line)
+			if loc == nil || loc[0] == loc[1] {
+				continue
+			}
+			if len(cs.Matches) >= maxCodeSearchMatches {
+				cs.Incomplete = true
+				return nil
+			}
+			cs.Matches = append(cs.Matches, newSourceMatch(modulePath, version, name, i+1, line, loc))
+		}
+	}
+	return nil
+}
+
+// newSourceMatch returns a SourceMatch for line number lineNum of file, whose
+// text is line and which matches at loc.
+func newSourceMatch(modulePath, version, file string, lineNum int, line string, loc []int) *SourceMatch {
+	before, match, after := line[:loc[0]], line[loc[0]:loc[1]], line[loc[1]:]
+	if len(before) > maxMatchContext {
+		before = "…" + trimInvalidPrefix(before[len(before)-maxMatchContext:])
+	}
+	if len(after) > maxMatchContext {
+		after = trimInvalidSuffix(after[:maxMatchContext]) + "…"
+	}
+	return &SourceMatch{
+		File:   file,
+		Line:   lineNum,
+		URL:    fmt.Sprintf("%s#L%d", godoc.HostedSourceURL(modulePath, version, file), lineNum),
+		Before: strings.TrimLeft(before, " \t"),
+		Match:  match,
+		After:  after,
+	}
+}
+
+// trimInvalidPrefix removes the bytes of a partial UTF-8 encoding from the
+// start of s.
+func trimInvalidPrefix(s string) string {
+	for len(s) > 0 && !utf8.RuneStart(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+// trimInvalidSuffix removes the bytes of a partial UTF-8 encoding from the end
+// of s.
+func trimInvalidSuffix(s string) string {
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
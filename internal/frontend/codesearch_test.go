@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"archive/zip"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSearchSourceFiles(t *testing.T) {
+	zr := newTestZip(t, map[string]string{
+		"example.com/m@v1.0.0/a/a.go":  "package a\n\n// WithTimeout sets a timeout.\nfunc WithTimeout() Option { return nil }\n",
+		"example.com/m@v1.0.0/b/b.go":  "package b\n\nvar _ = a.WithTimeout()\n",
+		"example.com/m@v1.0.0/README":  "Use withtimeout.\n",
+		"example.com/m@v1.0.0/bin/bin": "WithTimeout\xff",
+	})
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "example.com/m@v1.0.0/")] = f
+	}
+	for _, test := range []struct {
+		name  string
+		cs    *CodeSearch
+		scope string
+		want  []*SourceMatch
+	}{
+		{
+			name: "literal",
+			cs:   newCodeSearch("WithTimeout()", false, false),
+			want: []*SourceMatch{
+				{File: "a/a.go", Line: 4, URL: "/src/example.com/m@v1.0.0/a/a.go#L4", Before: "func ", Match: "WithTimeout()", After: " Option { return nil }"},
+				{File: "b/b.go", Line: 3, URL: "/src/example.com/m@v1.0.0/b/b.go#L3", Before: "var _ = a.", Match: "WithTimeout()"},
+			},
+		},
+		{
+			name:  "scoped",
+			cs:    newCodeSearch("WithTimeout", false, false),
+			scope: "b",
+			want: []*SourceMatch{
+				{File: "b/b.go", Line: 3, URL: "/src/example.com/m@v1.0.0/b/b.go#L3", Before: "var _ = a.", Match: "WithTimeout", After: "()"},
+			},
+		},
+		{
+			name: "regexp ignoring case",
+			cs:   newCodeSearch(`use \w+`, true, true),
+			want: []*SourceMatch{
+				{File: "README", Line: 1, URL: "/src/example.com/m@v1.0.0/README#L1", Match: "Use withtimeout", After: "."},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if err := searchSourceFiles(context.Background(), test.cs, files, "example.com/m", "v1.0.0", test.scope); err != nil {
+				t.Fatal(err)
+			}
+			if test.cs.Message != "" || test.cs.Incomplete {
+				t.Errorf("got message %q, incomplete %t; want neither", test.cs.Message, test.cs.Incomplete)
+			}
+			if diff := cmp.Diff(test.want, test.cs.Matches, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		cs := newCodeSearch("(", true, false)
+		if err := searchSourceFiles(context.Background(), cs, files, "example.com/m", "v1.0.0", ""); err != nil {
+			t.Fatal(err)
+		}
+		if cs.Message == "" || len(cs.Matches) != 0 {
+			t.Errorf("got message %q and %d matches, want a message and no matches", cs.Message, len(cs.Matches))
+		}
+	})
+}
+
+func TestNewSourceMatchTruncation(t *testing.T) {
+	line := strings.Repeat("é", 100) + "X" + strings.Repeat("ü", 100)
+	i := strings.Index(line, "X")
+	m := newSourceMatch("example.com/m", "v1.0.0", "f", 1, line, []int{i, i + 1})
+	if !strings.HasPrefix(m.Before, "…") || !strings.HasSuffix(m.After, "…") {
+		t.Errorf("context not truncated: %q %q", m.Before, m.After)
+	}
+	if len(m.Before) > maxMatchContext+len("…") || len(m.After) > maxMatchContext+len("…") {
+		t.Errorf("context too long: %d, %d", len(m.Before), len(m.After))
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// DocCoverage is the documentation coverage of a unit. For a package, it
+// describes only that package. For a module or directory, it is the sum of the
+// coverage of all packages under the unit's path.
+type DocCoverage struct {
+	Path       string `json:"path"`
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+
+	// Percent is the percentage of exported identifiers that are documented,
+	// rounded down.
+	Percent               int `json:"percent"`
+	NumPackages           int `json:"numPackages"`
+	NumMissingPackageDocs int `json:"numMissingPackageDocs"`
+	NumExported           int `json:"numExported"`
+	NumDocumented         int `json:"numDocumented"`
+
+	// Packages holds the coverage of each package that was counted, sorted
+	// by path.
+	Packages []*PackageDocCoverage `json:"packages"`
+}
+
+// PackageDocCoverage is the documentation coverage of a single package.
+type PackageDocCoverage struct {
+	Path          string `json:"path"`
+	Suffix        string `json:"-"` // path relative to the unit, for display
+	HasPackageDoc bool   `json:"hasPackageDoc"`
+	Percent       int    `json:"percent"`
+	NumExported   int    `json:"numExported"`
+	NumDocumented int    `json:"numDocumented"`
+}
+
+// MissingPackageDocs returns the packages without a package comment.
+func (c *DocCoverage) MissingPackageDocs() []*PackageDocCoverage {
+	var pkgs []*PackageDocCoverage
+	for _, p := range c.Packages {
+		if !p.HasPackageDoc {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+// newDocCoverage rolls up the coverage of the packages in pkgs, keyed by
+// package path, that belong to the unit described by um. It returns nil if
+// none of those packages have coverage information.
+func newDocCoverage(um *internal.UnitMeta, pkgs map[string]*internal.DocCoverage) *DocCoverage {
+	var (
+		total    internal.DocCoverage
+		packages []*PackageDocCoverage
+	)
+	for path, c := range pkgs {
+		if c == nil {
+			continue
+		}
+		if um.IsPackage() {
+			if path != um.Path {
+				continue
+			}
+		} else if path != um.Path && !strings.HasPrefix(path, um.Path+"/") {
+			continue
+		}
+		total.Add(c)
+		packages = append(packages, &PackageDocCoverage{
+			Path:          path,
+			Suffix:        internal.Suffix(path, um.Path),
+			HasPackageDoc: c.NumMissingPackageDocs == 0,
+			Percent:       percent(c.Fraction()),
+			NumExported:   c.NumExported,
+			NumDocumented: c.NumDocumented,
+		})
+	}
+	if len(packages) == 0 {
+		return nil
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	return &DocCoverage{
+		Path:                  um.Path,
+		ModulePath:            um.ModulePath,
+		Version:               um.Version,
+		Percent:               percent(total.Fraction()),
+		NumPackages:           total.NumPackages,
+		NumMissingPackageDocs: total.NumMissingPackageDocs,
+		NumExported:           total.NumExported,
+		NumDocumented:         total.NumDocumented,
+		Packages:              packages,
+	}
+}
+
+// percent converts a fraction to a percentage, rounding down so that
+// incomplete coverage is never displayed as 100%.
+func percent(f float64) int {
+	return int(math.Floor(f * 100))
+}
+
+// unitDocCoverage returns the documentation coverage of unit, computed from
+// the package metadata in its Subdirectories. It returns nil if coverage is
+// not available, which is the case for data sources other than postgres.
+func unitDocCoverage(unit *internal.Unit) *DocCoverage {
+	pkgs := map[string]*internal.DocCoverage{}
+	for _, pm := range unit.Subdirectories {
+		pkgs[pm.Path] = pm.Coverage
+	}
+	if unit.Documentation != nil && unit.Documentation.Coverage != nil {
+		pkgs[unit.Path] = unit.Documentation.Coverage
+	}
+	return newDocCoverage(&unit.UnitMeta, pkgs)
+}
+
+// serveDocCoverage handles requests for /coverage/<path>[@version]. It serves
+// the documentation coverage of the unit as JSON, or as an SVG badge if the
+// request path ends in ".svg".
+func (s *Server) serveDocCoverage(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveDocCoverage(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not store documentation coverage.
+		return proxydatasourceNotSupportedErr()
+	}
+	ctx := r.Context()
+	urlPath := strings.TrimPrefix(r.URL.Path, "/coverage")
+	badge := strings.HasSuffix(urlPath, ".svg")
+	urlPath = strings.TrimSuffix(urlPath, ".svg")
+	info, err := extractURLPathInfo(urlPath)
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	um, err := ds.GetUnitMeta(ctx, info.fullPath, info.modulePath, info.requestedVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	pkgs, err := db.GetDocCoverage(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return err
+	}
+	cov := newDocCoverage(um, pkgs)
+	if badge {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		if _, err := w.Write(docCoverageBadge(cov)); err != nil {
+			log.Errorf(ctx, "Error writing coverage badge: %v", err)
+		}
+		return nil
+	}
+	if cov == nil {
+		return &serverError{
+			status:       http.StatusNotFound,
+			responseText: "documentation coverage is not available for this version; try refetching it",
+		}
+	}
+	return writeJSON(ctx, w, cov)
+}
+
+// docCoverageBadgeTemplate is an SVG badge. Its arguments are the total width,
+// the width of the value section, its color, the x position of the value text,
+// and the value text.
+const docCoverageBadgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="godoc: %[5]s">` +
+	`<title>godoc: %[5]s</title>` +
+	`<rect width="46" height="20" fill="#555"/>` +
+	`<rect x="46" width="%[2]d" height="20" fill="%[3]s"/>` +
+	`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` +
+	`<text x="23" y="14">godoc</text>` +
+	`<text x="%[4]d" y="14">%[5]s</text>` +
+	`</g></svg>`
+
+// docCoverageBadge returns an SVG badge displaying the coverage percentage.
+func docCoverageBadge(cov *DocCoverage) []byte {
+	value, color := "unknown", "#9f9f9f"
+	if cov != nil {
+		value = fmt.Sprintf("%d%%", cov.Percent)
+		switch {
+		case cov.Percent >= 80:
+			color = "#4c1"
+		case cov.Percent >= 50:
+			color = "#dfb317"
+		default:
+			color = "#e05d44"
+		}
+	}
+	valueWidth := 10 + 7*len(value)
+	return []byte(fmt.Sprintf(docCoverageBadgeTemplate, 46+valueWidth, valueWidth, color, 46+valueWidth/2, value))
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestNewDocCoverage(t *testing.T) {
+	pkgs := map[string]*internal.DocCoverage{
+		"example.com/m": {NumPackages: 1, NumExported: 4, NumDocumented: 4},
+		"example.com/m/a": {
+			NumPackages: 1, NumMissingPackageDocs: 1, NumExported: 3, NumDocumented: 1,
+		},
+		"example.com/m/a/b": {NumPackages: 1, NumExported: 0, NumDocumented: 0},
+		"example.com/m/ab":  nil,
+	}
+	for _, test := range []struct {
+		name string
+		um   *internal.UnitMeta
+		want *DocCoverage
+	}{
+		{
+			name: "module",
+			um:   &internal.UnitMeta{Path: "example.com/m", ModulePath: "example.com/m", Version: "v1.0.0"},
+			want: &DocCoverage{
+				Path:                  "example.com/m",
+				ModulePath:            "example.com/m",
+				Version:               "v1.0.0",
+				Percent:               71,
+				NumPackages:           3,
+				NumMissingPackageDocs: 1,
+				NumExported:           7,
+				NumDocumented:         5,
+				Packages: []*PackageDocCoverage{
+					{Path: "example.com/m", HasPackageDoc: true, Percent: 100, NumExported: 4, NumDocumented: 4},
+					{Path: "example.com/m/a", Suffix: "a", Percent: 33, NumExported: 3, NumDocumented: 1},
+					{Path: "example.com/m/a/b", Suffix: "a/b", HasPackageDoc: true, Percent: 100},
+				},
+			},
+		},
+		{
+			name: "package",
+			um:   &internal.UnitMeta{Path: "example.com/m/a", Name: "a", ModulePath: "example.com/m", Version: "v1.0.0"},
+			want: &DocCoverage{
+				Path:                  "example.com/m/a",
+				ModulePath:            "example.com/m",
+				Version:               "v1.0.0",
+				Percent:               33,
+				NumPackages:           1,
+				NumMissingPackageDocs: 1,
+				NumExported:           3,
+				NumDocumented:         1,
+				Packages: []*PackageDocCoverage{
+					{Path: "example.com/m/a", Percent: 33, NumExported: 3, NumDocumented: 1},
+				},
+			},
+		},
+		{
+			name: "no coverage",
+			um:   &internal.UnitMeta{Path: "example.com/m/ab", ModulePath: "example.com/m", Version: "v1.0.0"},
+			want: nil,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := newDocCoverage(test.um, pkgs)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDocCoverageBadge(t *testing.T) {
+	for _, test := range []struct {
+		cov  *DocCoverage
+		want []string
+	}{
+		{&DocCoverage{Percent: 95}, []string{"95%", "#4c1"}},
+		{&DocCoverage{Percent: 60}, []string{"60%", "#dfb317"}},
+		{&DocCoverage{Percent: 10}, []string{"10%", "#e05d44"}},
+		{nil, []string{"unknown"}},
+	} {
+		got := string(docCoverageBadge(test.cov))
+		for _, w := range test.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("docCoverageBadge(%+v) = %q, missing %q", test.cov, got, w)
+			}
+		}
+	}
+}
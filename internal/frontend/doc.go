@@ -20,7 +20,7 @@ import (
 	"golang.org/x/pkgsite/internal/stdlib"
 )
 
-func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package) (_ *dochtml.Parts, err error) {
+func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package, hostedSource bool) (_ *dochtml.Parts, err error) {
 	defer derrors.Wrap(&err, "renderDocParts")
 	defer middleware.ElapsedStat(ctx, "renderDocParts")()
 
@@ -35,19 +35,24 @@ func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package
 	} else if u.Path != u.ModulePath {
 		innerPath = u.Path[len(u.ModulePath)+1:]
 	}
-	return docPkg.RenderParts(ctx, innerPath, u.SourceInfo, modInfo)
+	return docPkg.RenderParts(ctx, innerPath, u.SourceInfo, modInfo, hostedSource)
 }
 
 // sourceFiles returns the .go files for a package.
-func sourceFiles(u *internal.Unit, docPkg *godoc.Package) []*File {
+func sourceFiles(u *internal.Unit, docPkg *godoc.Package, hostedSource bool) []*File {
 	var files []*File
 	for _, f := range docPkg.Files {
 		if strings.HasSuffix(f.Name, "_test.go") {
 			continue
 		}
+		filePath := path.Join(internal.Suffix(u.Path, u.ModulePath), f.Name)
+		url := u.SourceInfo.FileURL(filePath)
+		if u.SourceInfo == nil && hostedSource {
+			url = godoc.HostedSourceURL(u.ModulePath, u.Version, filePath)
+		}
 		files = append(files, &File{
 			Name: f.Name,
-			URL:  u.SourceInfo.FileURL(path.Join(internal.Suffix(u.Path, u.ModulePath), f.Name)),
+			URL:  url,
 		})
 	}
 	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
@@ -71,5 +76,9 @@ func fileSource(modulePath, version, filePath string) string {
 		log.Errorf(context.TODO(), "fileSource: %v", err)
 		return fmt.Sprintf("%s/+/refs/heads/master/%s", root, filePath)
 	}
+	if !strings.HasPrefix(tag, "go") {
+		// The version is a pseudo-version, and the tag is a commit hash.
+		return fmt.Sprintf("%s/+/%s/%s", root, tag, filePath)
+	}
 	return fmt.Sprintf("%s/+/refs/tags/%s/%s", root, tag, filePath)
 }
@@ -28,6 +28,12 @@ func TestFileSource(t *testing.T) {
 			filePath:   "README.md",
 			want:       fmt.Sprintf("go.googlesource.com/go/+/refs/tags/%s/%s", "go1.13", "README.md"),
 		},
+		{
+			modulePath: stdlib.ModulePath,
+			version:    "v0.0.0-20201015141213-0123456789ab",
+			filePath:   "README.md",
+			want:       fmt.Sprintf("go.googlesource.com/go/+/%s/%s", "0123456789ab", "README.md"),
+		},
 		{
 			modulePath: stdlib.ModulePath,
 			version:    "v1.13.invalid",
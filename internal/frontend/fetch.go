@@ -107,7 +107,7 @@ func (s *Server) serveFetch(w http.ResponseWriter, r *http.Request, ds internal.
 	}
 	if !isSupportedVersion(urlInfo.fullPath, urlInfo.requestedVersion) ||
 		// TODO(https://golang.org/issue/39973): add support for fetching the
-		// latest and master versions of the standard library.
+		// latest version of the standard library.
 		(stdlib.Contains(urlInfo.fullPath) && urlInfo.requestedVersion == internal.LatestVersion) {
 		return &serverError{status: http.StatusBadRequest}
 	}
@@ -135,7 +135,7 @@ func (s *Server) fetchAndPoll(ctx context.Context, ds internal.DataSource, modul
 
 	if !isSupportedVersion(fullPath, requestedVersion) ||
 		// TODO(https://golang.org/issue/39973): add support for fetching the
-		// latest and master versions of the standard library
+		// latest version of the standard library
 		(stdlib.Contains(fullPath) && requestedVersion == internal.LatestVersion) {
 		return http.StatusBadRequest, http.StatusText(http.StatusBadRequest)
 	}
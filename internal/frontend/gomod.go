@@ -0,0 +1,135 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// GoModDetails contains the directives of a module's go.mod file, for display
+// on the go.mod tab.
+type GoModDetails struct {
+	ModulePath string
+	GoVersion  string
+	Requires   []*GoModRequirement
+	Replaces   []*GoModReplacement
+	Excludes   []*GoModExclusion
+	Retracts   []*GoModRetraction
+
+	// LicenseReportURL links to the licenses of the module's dependencies.
+	LicenseReportURL string
+	// GraphURL links to the requirement graph of the module.
+	GraphURL string
+	// SBOMURL is the URL of the module's software bill of materials, without
+	// the suffix that selects its format.
+	SBOMURL string
+}
+
+// GoModRequirement is a require directive, with a link to the required
+// module.
+type GoModRequirement struct {
+	ModulePath string
+	Version    string
+	Indirect   bool
+	URL        string
+}
+
+// GoModReplacement is a replace directive, with an explanation of its
+// effect.
+type GoModReplacement struct {
+	Old         string // path, or path@version
+	New         string // path, directory, or path@version
+	URL         string // link to the replacement module; empty for directories
+	Explanation string
+}
+
+// GoModExclusion is an exclude directive, with a link to the excluded
+// module version.
+type GoModExclusion struct {
+	ModulePath string
+	Version    string
+	URL        string
+}
+
+// GoModRetraction is a retract directive.
+type GoModRetraction struct {
+	Versions  string // a single version, or an interval "[low, high]"
+	Rationale string
+}
+
+// fetchGoModDetails returns the go.mod directives for the module version
+// specified by um.
+func fetchGoModDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (*GoModDetails, error) {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not support the go.mod page.
+		return nil, proxydatasourceNotSupportedErr()
+	}
+	gm, err := db.GetGoMod(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return nil, err
+	}
+	d := goModDetails(um.ModulePath, gm)
+	d.LicenseReportURL = "/license-report/" + um.ModulePath + "@" + um.Version
+	d.GraphURL = "/mod-graph/" + um.ModulePath + "@" + um.Version
+	d.SBOMURL = "/sbom/" + um.ModulePath + "@" + um.Version
+	return d, nil
+}
+
+// goModDetails converts gm into a GoModDetails for the module modulePath.
+func goModDetails(modulePath string, gm *internal.GoMod) *GoModDetails {
+	d := &GoModDetails{
+		ModulePath: modulePath,
+		GoVersion:  gm.GoVersion,
+	}
+	for _, r := range gm.Requires {
+		d.Requires = append(d.Requires, &GoModRequirement{
+			ModulePath: r.ModulePath,
+			Version:    r.Version,
+			Indirect:   r.Indirect,
+			URL:        constructUnitURL(r.ModulePath, r.ModulePath, r.Version),
+		})
+	}
+	for _, r := range gm.Replaces {
+		d.Replaces = append(d.Replaces, goModReplacement(r))
+	}
+	for _, e := range gm.Excludes {
+		d.Excludes = append(d.Excludes, &GoModExclusion{
+			ModulePath: e.ModulePath,
+			Version:    e.Version,
+			URL:        constructUnitURL(e.ModulePath, e.ModulePath, e.Version),
+		})
+	}
+	for _, r := range gm.Retracts {
+		vs := r.Low
+		if r.Low != r.High {
+			vs = fmt.Sprintf("[%s, %s]", r.Low, r.High)
+		}
+		d.Retracts = append(d.Retracts, &GoModRetraction{Versions: vs, Rationale: r.Rationale})
+	}
+	return d
+}
+
+// goModReplacement describes the replace directive r.
+func goModReplacement(r *internal.GoModReplace) *GoModReplacement {
+	gr := &GoModReplacement{Old: r.OldPath, New: r.NewPath}
+	which := "All versions of " + r.OldPath + " are"
+	if r.OldVersion != "" {
+		gr.Old = r.OldPath + "@" + r.OldVersion
+		which = gr.Old + " is"
+	}
+	if r.IsLocal() {
+		gr.Explanation = fmt.Sprintf("%s replaced by the directory %s, relative to this module's root.", which, r.NewPath)
+		return gr
+	}
+	gr.New = r.NewPath + "@" + r.NewVersion
+	gr.URL = constructUnitURL(r.NewPath, r.NewPath, r.NewVersion)
+	gr.Explanation = fmt.Sprintf("%s replaced by the module %s.", which, gr.New)
+	return gr
+}
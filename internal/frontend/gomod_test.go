@@ -0,0 +1,65 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestGoModDetails(t *testing.T) {
+	gm := &internal.GoMod{
+		GoVersion: "1.15",
+		Requires: []*internal.GoModRequire{
+			{ModulePath: "example.com/a", Version: "v1.2.3"},
+			{ModulePath: "example.com/b", Version: "v0.1.0", Indirect: true},
+		},
+		Replaces: []*internal.GoModReplace{
+			{OldPath: "example.com/a", NewPath: "../a"},
+			{OldPath: "example.com/b", OldVersion: "v0.1.0", NewPath: "example.com/c", NewVersion: "v0.2.0"},
+		},
+		Excludes: []*internal.GoModExclude{
+			{ModulePath: "example.com/a", Version: "v1.2.2"},
+		},
+		Retracts: []*internal.GoModRetract{
+			{Low: "v1.0.1", High: "v1.0.1", Rationale: "Published accidentally."},
+			{Low: "v1.1.0", High: "v1.1.5"},
+		},
+	}
+	want := &GoModDetails{
+		ModulePath: "example.com/m",
+		GoVersion:  "1.15",
+		Requires: []*GoModRequirement{
+			{ModulePath: "example.com/a", Version: "v1.2.3", URL: "/example.com/a@v1.2.3"},
+			{ModulePath: "example.com/b", Version: "v0.1.0", Indirect: true, URL: "/example.com/b@v0.1.0"},
+		},
+		Replaces: []*GoModReplacement{
+			{
+				Old:         "example.com/a",
+				New:         "../a",
+				Explanation: "All versions of example.com/a are replaced by the directory ../a, relative to this module's root.",
+			},
+			{
+				Old:         "example.com/b@v0.1.0",
+				New:         "example.com/c@v0.2.0",
+				URL:         "/example.com/c@v0.2.0",
+				Explanation: "example.com/b@v0.1.0 is replaced by the module example.com/c@v0.2.0.",
+			},
+		},
+		Excludes: []*GoModExclusion{
+			{ModulePath: "example.com/a", Version: "v1.2.2", URL: "/example.com/a@v1.2.2"},
+		},
+		Retracts: []*GoModRetraction{
+			{Versions: "v1.0.1", Rationale: "Published accidentally."},
+			{Versions: "[v1.1.0, v1.1.5]"},
+		},
+	}
+	got := goModDetails("example.com/m", gm)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// SourceLine is a line of a source file, split into tokens for display.
+type SourceLine struct {
+	Number int
+	Tokens []*SourceToken
+}
+
+// SourceToken is a span of text on a single line of a source file.
+type SourceToken struct {
+	Text string
+	// Class is the CSS class used to highlight the token, or empty for
+	// plain text.
+	Class string
+	// Href, if non-empty, is the target of a link for the token.
+	Href string
+}
+
+// plainSourceLines splits contents into lines without highlighting.
+func plainSourceLines(contents []byte) []*SourceLine {
+	return splitSourceLines([]*SourceToken{{Text: string(contents)}})
+}
+
+// goSourceLines splits the Go source in contents into highlighted lines.
+// links maps the byte offsets of identifiers to link targets for them.
+func goSourceLines(contents []byte, links map[int]string) []*SourceLine {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(contents))
+	var s scanner.Scanner
+	// Errors are ignored: malformed source is still displayed, just with
+	// less accurate highlighting.
+	s.Init(file, contents, nil, scanner.ScanComments)
+
+	var (
+		toks []*SourceToken
+		last int // offset of the end of the last token
+	)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit != ";" {
+			// An automatically inserted semicolon, which doesn't appear in
+			// the source.
+			continue
+		}
+		start := file.Offset(pos)
+		end := tokenEnd(contents, start, tok, lit)
+		if start < last || end <= start {
+			continue
+		}
+		if start > last {
+			toks = append(toks, &SourceToken{Text: string(contents[last:start])})
+		}
+		st := &SourceToken{Text: string(contents[start:end]), Class: tokenClass(tok)}
+		if tok == token.IDENT {
+			st.Href = links[start]
+		}
+		toks = append(toks, st)
+		last = end
+	}
+	if last < len(contents) {
+		toks = append(toks, &SourceToken{Text: string(contents[last:])})
+	}
+	return splitSourceLines(toks)
+}
+
+// tokenEnd returns the offset in contents of the end of the token that starts
+// at offset start. The scanner removes carriage returns from the literals of
+// comments and raw strings, so the ends of those are found in contents.
+func tokenEnd(contents []byte, start int, tok token.Token, lit string) int {
+	rest := contents[start:]
+	switch {
+	case tok == token.COMMENT && bytes.HasPrefix(rest, []byte("//")):
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			return start + i
+		}
+		return len(contents)
+	case tok == token.COMMENT:
+		if i := bytes.Index(rest[2:], []byte("*/")); i >= 0 {
+			return start + 2 + i + 2
+		}
+		return len(contents)
+	case tok == token.STRING && bytes.HasPrefix(rest, []byte("`")):
+		if i := bytes.IndexByte(rest[1:], '`'); i >= 0 {
+			return start + 1 + i + 1
+		}
+		return len(contents)
+	case lit != "":
+		return start + len(lit)
+	default:
+		return start + len(tok.String())
+	}
+}
+
+// tokenClass returns the CSS class used to highlight tok.
+func tokenClass(tok token.Token) string {
+	switch {
+	case tok == token.COMMENT:
+		return "comment"
+	case tok == token.STRING || tok == token.CHAR:
+		return "string"
+	case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+		return "number"
+	case tok.IsKeyword():
+		return "keyword"
+	default:
+		return ""
+	}
+}
+
+// splitSourceLines splits toks at newlines and groups them into numbered
+// lines.
+func splitSourceLines(toks []*SourceToken) []*SourceLine {
+	line := &SourceLine{Number: 1}
+	lines := []*SourceLine{line}
+	for _, t := range toks {
+		parts := strings.Split(t.Text, "\n")
+		for i, p := range parts {
+			if i > 0 {
+				line = &SourceLine{Number: line.Number + 1}
+				lines = append(lines, line)
+			}
+			if p != "" {
+				line.Tokens = append(line.Tokens, &SourceToken{Text: p, Class: t.Class, Href: t.Href})
+			}
+		}
+	}
+	// Don't display an empty line after a trailing newline.
+	if len(lines) > 1 && len(line.Tokens) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// goSourceLinks returns links for the identifiers in file, keyed by byte
+// offset. files holds all the files of file's package, including file itself,
+// keyed by file name; they must have been parsed with fset.
+//
+// Declarations of exported package-level identifiers link to their
+// documentation, at docURL. Uses of package-level identifiers link to their
+// declarations, whose URLs are computed by lineURL.
+func goSourceLinks(fset *token.FileSet, files map[string]*ast.File, file *ast.File, docURL string, lineURL func(filename string, line int) string) map[int]string {
+	// Collect the positions of package-level declarations.
+	decls := map[string]token.Position{}
+	for _, f := range files {
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					decls[d.Name.Name] = fset.Position(d.Name.Pos())
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						decls[spec.Name.Name] = fset.Position(spec.Name.Pos())
+					case *ast.ValueSpec:
+						for _, n := range spec.Names {
+							decls[n.Name] = fset.Position(n.Pos())
+						}
+					}
+				}
+			}
+		}
+	}
+
+	links := map[int]string{}
+	skip := map[*ast.Ident]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// The selector refers to a field, method or imported name.
+			skip[n.Sel] = true
+		case *ast.FuncDecl:
+			if n.Recv != nil && len(n.Recv.List) > 0 {
+				skip[n.Name] = true
+				if recv := receiverTypeName(n.Recv.List[0].Type); recv != "" && ast.IsExported(recv) && n.Name.IsExported() {
+					links[fset.Position(n.Name.Pos()).Offset] = docURL + "#" + recv + "." + n.Name.Name
+				}
+			}
+		case *ast.Ident:
+			if skip[n] {
+				return true
+			}
+			if n.Obj != nil && file.Scope.Lookup(n.Name) != n.Obj {
+				// A local declaration.
+				return true
+			}
+			dpos, ok := decls[n.Name]
+			if !ok {
+				return true
+			}
+			pos := fset.Position(n.Pos())
+			if pos == dpos {
+				if n.IsExported() {
+					links[pos.Offset] = docURL + "#" + n.Name
+				}
+			} else {
+				links[pos.Offset] = lineURL(dpos.Filename, dpos.Line)
+			}
+		}
+		return true
+	})
+	return links
+}
+
+// receiverTypeName returns the name of the type of a method receiver, or the
+// empty string if it cannot be determined.
+func receiverTypeName(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(x.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(x.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(x.X)
+	case *ast.Ident:
+		return x.Name
+	default:
+		return ""
+	}
+}
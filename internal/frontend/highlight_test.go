@@ -0,0 +1,107 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGoSourceLines(t *testing.T) {
+	src := "package p\n\n// C is a constant.\nconst C = `a\nb` + \"c\"\n\nvar x = 1 /* one */\n"
+	got := goSourceLines([]byte(src), map[int]string{37: "#C"})
+	want := []*SourceLine{
+		{Number: 1, Tokens: []*SourceToken{{Text: "package", Class: "keyword"}, {Text: " "}, {Text: "p"}}},
+		{Number: 2},
+		{Number: 3, Tokens: []*SourceToken{{Text: "// C is a constant.", Class: "comment"}}},
+		{Number: 4, Tokens: []*SourceToken{
+			{Text: "const", Class: "keyword"}, {Text: " "}, {Text: "C", Href: "#C"}, {Text: " "}, {Text: "="}, {Text: " "},
+			{Text: "`a", Class: "string"},
+		}},
+		{Number: 5, Tokens: []*SourceToken{
+			{Text: "b`", Class: "string"}, {Text: " "}, {Text: "+"}, {Text: " "}, {Text: `"c"`, Class: "string"},
+		}},
+		{Number: 6},
+		{Number: 7, Tokens: []*SourceToken{
+			{Text: "var", Class: "keyword"}, {Text: " "}, {Text: "x"}, {Text: " "}, {Text: "="}, {Text: " "},
+			{Text: "1", Class: "number"}, {Text: " "}, {Text: "/* one */", Class: "comment"},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlainSourceLines(t *testing.T) {
+	got := plainSourceLines([]byte("a <b>\n\nc"))
+	want := []*SourceLine{
+		{Number: 1, Tokens: []*SourceToken{{Text: "a <b>"}}},
+		{Number: 2},
+		{Number: 3, Tokens: []*SourceToken{{Text: "c"}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGoSourceLinks(t *testing.T) {
+	const (
+		a = `package p
+
+type T int
+
+func (T) M() {}
+
+func F(x T) T {
+	var G int
+	_ = G
+	return H(x)
+}
+`
+		b = `package p
+
+func H(t T) T { return t }
+`
+	)
+	fset := token.NewFileSet()
+	files := map[string]*ast.File{}
+	for name, src := range map[string]string{"a.go": a, "b.go": b} {
+		f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = f
+	}
+	lineURL := func(filename string, line int) string { return fmt.Sprintf("%s#L%d", filename, line) }
+	links := goSourceLinks(fset, files, files["a.go"], "/p", lineURL)
+
+	// Convert offsets to identifier names and lines for comparison.
+	got := map[string]string{}
+	for off, href := range links {
+		pos := fset.File(files["a.go"].Pos()).Pos(off)
+		p := fset.Position(pos)
+		end := off
+		for end < len(a) && (a[end] == '_' || 'a' <= a[end] && a[end] <= 'z' || 'A' <= a[end] && a[end] <= 'Z') {
+			end++
+		}
+		got[fmt.Sprintf("%s:%d", a[off:end], p.Line)] = href
+	}
+	want := map[string]string{
+		"T:3":  "/p#T",
+		"T:5":  "a.go#L3",
+		"M:5":  "/p#T.M",
+		"F:7":  "/p#F",
+		"T:7":  "a.go#L3",
+		"H:10": "b.go#L3",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
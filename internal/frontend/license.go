@@ -9,6 +9,7 @@ import (
 	"context"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/google/safehtml"
 	"golang.org/x/pkgsite/internal"
@@ -20,11 +21,27 @@ type License struct {
 	*licenses.License
 	Anchor safehtml.Identifier
 	Source string
+	// Segments holds the contents of the license divided into the parts
+	// that match the canonical texts of the licenses found in it and the
+	// parts that deviate from them. It is nil if the contents do not
+	// deviate, or could not be compared.
+	Segments []LicenseSegment
+}
+
+// LicenseSegment is a part of the contents of a license, or text of a
+// canonical license that is missing from it.
+type LicenseSegment struct {
+	Text string
+	// Class is the CSS class of the segment, or the empty string if the text
+	// matches the canonical license text.
+	Class string
 }
 
 // LicensesDetails contains license information for a package or module.
 type LicensesDetails struct {
 	Licenses []License
+	// Policy explains how the license policy applies to the licenses.
+	Policy licenses.Decision
 }
 
 // LicenseMetadata contains license metadata that is used in the package
@@ -41,7 +58,14 @@ func fetchLicensesDetails(ctx context.Context, ds internal.DataSource, um *inter
 	if err != nil {
 		return nil, err
 	}
-	return &LicensesDetails{Licenses: transformLicenses(um.ModulePath, um.Version, u.LicenseContents)}, nil
+	var types []string
+	for _, l := range u.LicenseContents {
+		types = append(types, l.Types...)
+	}
+	return &LicensesDetails{
+		Licenses: transformLicenses(um.ModulePath, um.Version, u.LicenseContents),
+		Policy:   licenses.CurrentPolicy().Decide(um.ModulePath, types),
+	}, nil
 }
 
 // transformLicenses transforms licenses.License into a License
@@ -54,16 +78,52 @@ func transformLicenses(modulePath, requestedVersion string, dbLicenses []*licens
 	}
 	anchors := licenseAnchors(filePaths)
 	for i, l := range dbLicenses {
+		// The offsets of the coverage matches are relative to the original
+		// contents, so they are compared before removing carriage returns.
+		segments := licenseSegments(l)
 		l.Contents = bytes.ReplaceAll(l.Contents, []byte("\r"), nil)
 		licenses[i] = License{
-			Anchor:  anchors[i],
-			License: l,
-			Source:  fileSource(modulePath, requestedVersion, l.FilePath),
+			Anchor:   anchors[i],
+			License:  l,
+			Source:   fileSource(modulePath, requestedVersion, l.FilePath),
+			Segments: segments,
 		}
 	}
 	return licenses
 }
 
+// licenseSegments compares the contents of l with the canonical texts of the
+// licenses that were found in it. It returns nil if they do not deviate, or
+// if the contents of l are not available.
+func licenseSegments(l *licenses.License) []LicenseSegment {
+	if len(l.Contents) == 0 {
+		// Without the contents, every canonical text would appear to be
+		// missing.
+		return nil
+	}
+	var (
+		segs      []LicenseSegment
+		deviation bool
+	)
+	for _, ts := range licenses.CompareText(l.Contents, l.Coverage) {
+		var class string
+		switch ts.Kind {
+		case licenses.SegmentExtra:
+			class = "License-extra"
+		case licenses.SegmentMissing:
+			class = "License-missing"
+		}
+		if class != "" {
+			deviation = true
+		}
+		segs = append(segs, LicenseSegment{Text: strings.ReplaceAll(ts.Text, "\r", ""), Class: class})
+	}
+	if !deviation {
+		return nil
+	}
+	return segs
+}
+
 // transformLicenseMetadata transforms licenses.Metadata into a LicenseMetadata
 // by adding an anchor field.
 func transformLicenseMetadata(dbLicenses []*licenses.Metadata) []LicenseMetadata {
@@ -0,0 +1,222 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// maxRecentLicenseChanges is the number of license changes served for
+// /license-changes.atom and /license-changes.json.
+const maxRecentLicenseChanges = 100
+
+// licenseChangeJSON is the JSON form of an internal.LicenseChange.
+type licenseChangeJSON struct {
+	ModulePath         string    `json:"modulePath"`
+	Version            string    `json:"version"`
+	PreviousModulePath string    `json:"previousModulePath"`
+	PreviousVersion    string    `json:"previousVersion"`
+	FilePath           string    `json:"filePath"`
+	OldTypes           []string  `json:"oldTypes"`
+	NewTypes           []string  `json:"newTypes"`
+	DetectedAt         time.Time `json:"detectedAt"`
+}
+
+// serveLicenseChanges handles requests for /license-changes.atom and
+// /license-changes.json, which serve the most recently detected license
+// changes of all modules, and for /license-changes/<path>.atom and
+// /license-changes/<path>.json, which serve the license changes of every
+// version in the series of the module at path. The changes are served as an
+// Atom feed or as a JSON array.
+func (s *Server) serveLicenseChanges(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveLicenseChanges(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not record license changes.
+		return proxydatasourceNotSupportedErr()
+	}
+	ctx := r.Context()
+	urlPath := r.URL.Path
+	var ext string
+	for _, e := range []string{".atom", ".json"} {
+		if strings.HasSuffix(urlPath, e) {
+			ext = e
+		}
+	}
+	if ext == "" {
+		return &serverError{status: http.StatusNotFound}
+	}
+	urlPath = strings.TrimSuffix(urlPath, ext)
+
+	var (
+		changes []*internal.LicenseChange
+		title   string
+	)
+	if urlPath == "/license-changes" {
+		changes, err = db.GetRecentLicenseChanges(ctx, maxRecentLicenseChanges)
+		if err != nil {
+			return err
+		}
+		title = "License changes"
+	} else {
+		fullPath := strings.TrimPrefix(urlPath, "/license-changes/")
+		if fullPath == "" || strings.Contains(fullPath, "@") {
+			return &serverError{status: http.StatusBadRequest}
+		}
+		um, err := ds.GetUnitMeta(ctx, fullPath, internal.UnknownModulePath, internal.LatestVersion)
+		if err != nil {
+			if errors.Is(err, derrors.NotFound) {
+				return &serverError{status: http.StatusNotFound, err: err}
+			}
+			return err
+		}
+		changes, err = db.GetLicenseChanges(ctx, internal.SeriesPathForModule(um.ModulePath))
+		if err != nil {
+			return err
+		}
+		title = "License changes of " + um.ModulePath
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	switch ext {
+	case ".json":
+		lcs := []*licenseChangeJSON{}
+		for _, c := range changes {
+			lcs = append(lcs, &licenseChangeJSON{
+				ModulePath:         c.ModulePath,
+				Version:            c.Version,
+				PreviousModulePath: c.PreviousModulePath,
+				PreviousVersion:    c.PreviousVersion,
+				FilePath:           c.FilePath,
+				OldTypes:           c.OldTypes,
+				NewTypes:           c.NewTypes,
+				DetectedAt:         c.CreatedAt,
+			})
+		}
+		return writeJSON(ctx, w, lcs)
+	case ".atom":
+		feed := newLicenseChangeFeed(title, s.siteURL, r.URL.Path, changes)
+		body, err = xml.MarshalIndent(feed, "", "  ")
+		body = append([]byte(xml.Header), body...)
+		contentType = "application/atom+xml"
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(body); err != nil {
+		log.Errorf(ctx, "Error writing license changes: %v", err)
+	}
+	return nil
+}
+
+// describeLicenseChange returns a short description of c.
+func describeLicenseChange(c *internal.LicenseChange) string {
+	switch {
+	case c.Added():
+		return fmt.Sprintf("%s added (%s)", c.FilePath, strings.Join(c.NewTypes, ", "))
+	case c.Removed():
+		return fmt.Sprintf("%s removed (%s)", c.FilePath, strings.Join(c.OldTypes, ", "))
+	default:
+		return fmt.Sprintf("%s changed from %s to %s", c.FilePath,
+			strings.Join(c.OldTypes, ", "), strings.Join(c.NewTypes, ", "))
+	}
+}
+
+// The following types are the subset of the Atom format (RFC 4287) used for
+// feeds of license changes.
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Author  atomPerson   `xml:"author"`
+	Link    atomLink     `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+// newLicenseChangeFeed returns an Atom feed with the given title, served from
+// feedPath on the site at baseURL. It has an entry for each module version in
+// changes, whose changes must be adjacent.
+func newLicenseChangeFeed(title, baseURL, feedPath string, changes []*internal.LicenseChange) *atomFeed {
+	feed := &atomFeed{
+		Title:  title,
+		ID:     baseURL + feedPath,
+		Author: atomPerson{Name: "pkgsite"},
+		Link:   atomLink{Rel: "self", Href: baseURL + feedPath},
+	}
+	var (
+		entry       *atomEntry
+		descs       []string
+		lastVersion string
+	)
+	for _, c := range changes {
+		mv := c.ModulePath + "@" + c.Version
+		if mv != lastVersion {
+			if entry != nil {
+				entry.Summary = strings.Join(descs, "\n")
+			}
+			url := baseURL + "/" + mv + "?tab=licenses"
+			entry = &atomEntry{
+				Title:   fmt.Sprintf("%s: licenses changed since %s@%s", mv, c.PreviousModulePath, c.PreviousVersion),
+				ID:      url,
+				Updated: c.CreatedAt.UTC().Format(time.RFC3339),
+				Link:    atomLink{Href: url},
+			}
+			feed.Entries = append(feed.Entries, entry)
+			descs = nil
+			lastVersion = mv
+		}
+		descs = append(descs, describeLicenseChange(c))
+	}
+	if entry != nil {
+		entry.Summary = strings.Join(descs, "\n")
+	}
+	// The feed was last updated when its most recent change was detected.
+	for _, e := range feed.Entries {
+		if e.Updated > feed.Updated {
+			feed.Updated = e.Updated
+		}
+	}
+	if feed.Updated == "" {
+		feed.Updated = time.Now().UTC().Format(time.RFC3339)
+	}
+	return feed
+}
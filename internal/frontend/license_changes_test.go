@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestDescribeLicenseChange(t *testing.T) {
+	for _, test := range []struct {
+		change *internal.LicenseChange
+		want   string
+	}{
+		{
+			&internal.LicenseChange{FilePath: "LICENSE", OldTypes: []string{"MIT"}, NewTypes: []string{"BUSL-1.1"}},
+			"LICENSE changed from MIT to BUSL-1.1",
+		},
+		{
+			&internal.LicenseChange{FilePath: "COPYING", NewTypes: []string{"Apache-2.0", "MIT"}},
+			"COPYING added (Apache-2.0, MIT)",
+		},
+		{
+			&internal.LicenseChange{FilePath: "sub/LICENSE", OldTypes: []string{"BSD-3-Clause"}},
+			"sub/LICENSE removed (BSD-3-Clause)",
+		},
+	} {
+		if got := describeLicenseChange(test.change); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestNewLicenseChangeFeed(t *testing.T) {
+	t1 := time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	changes := []*internal.LicenseChange{
+		{
+			ModulePath: "example.com/a", Version: "v1.1.0",
+			PreviousModulePath: "example.com/a", PreviousVersion: "v1.0.0",
+			FilePath: "COPYING", NewTypes: []string{"Apache-2.0"}, CreatedAt: t2,
+		},
+		{
+			ModulePath: "example.com/a", Version: "v1.1.0",
+			PreviousModulePath: "example.com/a", PreviousVersion: "v1.0.0",
+			FilePath: "LICENSE", OldTypes: []string{"MIT"}, NewTypes: []string{"BUSL-1.1"}, CreatedAt: t2,
+		},
+		{
+			ModulePath: "example.com/b/v2", Version: "v2.0.0",
+			PreviousModulePath: "example.com/b", PreviousVersion: "v1.3.0",
+			FilePath: "LICENSE", OldTypes: []string{"MIT"}, CreatedAt: t1,
+		},
+	}
+	got := newLicenseChangeFeed("License changes", "https://pkg.go.dev", "/license-changes.atom", changes)
+	want := &atomFeed{
+		Title:   "License changes",
+		ID:      "https://pkg.go.dev/license-changes.atom",
+		Updated: "2020-11-01T11:00:00Z",
+		Author:  atomPerson{Name: "pkgsite"},
+		Link:    atomLink{Rel: "self", Href: "https://pkg.go.dev/license-changes.atom"},
+		Entries: []*atomEntry{
+			{
+				Title:   "example.com/a@v1.1.0: licenses changed since example.com/a@v1.0.0",
+				ID:      "https://pkg.go.dev/example.com/a@v1.1.0?tab=licenses",
+				Updated: "2020-11-01T11:00:00Z",
+				Link:    atomLink{Href: "https://pkg.go.dev/example.com/a@v1.1.0?tab=licenses"},
+				Summary: "COPYING added (Apache-2.0)\nLICENSE changed from MIT to BUSL-1.1",
+			},
+			{
+				Title:   "example.com/b/v2@v2.0.0: licenses changed since example.com/b@v1.3.0",
+				ID:      "https://pkg.go.dev/example.com/b/v2@v2.0.0?tab=licenses",
+				Updated: "2020-11-01T10:00:00Z",
+				Link:    atomLink{Href: "https://pkg.go.dev/example.com/b/v2@v2.0.0?tab=licenses"},
+				Summary: "LICENSE removed (MIT)",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := xml.Marshal(got); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+// maxReportModules is the largest number of module versions that are visited
+// when computing a license report.
+const maxReportModules = 2000
+
+// LicenseReport lists the licenses of every module in the build list of a
+// module version: the versions of its dependencies, direct and indirect, that
+// the go command selects when building it.
+type LicenseReport struct {
+	basePage `json:"-"`
+
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+
+	Dependencies []*DependencyLicense `json:"dependencies"`
+
+	// NumViolations is the number of dependencies whose licenses are not
+	// accepted by the license policy.
+	NumViolations int `json:"numViolations"`
+
+	// Unprocessed lists the module versions that are part of the requirement
+	// graph but have not been processed, as path@version. Their licenses and
+	// requirements are unknown, so the report is incomplete until they are
+	// processed.
+	Unprocessed []string `json:"unprocessed"`
+
+	// Truncated reports whether the requirement graph was too large to be
+	// visited completely.
+	Truncated bool `json:"truncated"`
+
+	// URL is the URL of the report, to which requests to process the
+	// unprocessed modules are posted.
+	URL string `json:"-"`
+	// NumEnqueued is the number of modules that were just enqueued for
+	// processing.
+	NumEnqueued int `json:"-"`
+}
+
+// DependencyLicense describes the licenses of one module in a LicenseReport.
+type DependencyLicense struct {
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+	URL        string `json:"-"`
+
+	// Replacement is the module version, as path@version, that replaces this
+	// one according to the main module's go.mod file, or the directory that
+	// replaces it. The licenses are those of the replacement module.
+	Replacement string `json:"replacement,omitempty"`
+
+	// Processed reports whether pkgsite has processed the module version.
+	// If not, its licenses are unknown.
+	Processed    bool     `json:"processed"`
+	LicenseTypes []string `json:"licenseTypes"`
+	// Violation reports whether the license policy does not accept the
+	// module's licenses.
+	Violation   bool   `json:"violation"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// serveLicenseReport handles requests for /license-report/<path>[@version].
+// It serves the license report of the module containing path as HTML, or as
+// JSON if the request path ends in ".json". A POST request enqueues the
+// unprocessed modules of the report to be fetched.
+func (s *Server) serveLicenseReport(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveLicenseReport(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not store go.mod files.
+		return proxydatasourceNotSupportedErr()
+	}
+	ctx := r.Context()
+	urlPath := strings.TrimPrefix(r.URL.Path, "/license-report")
+	asJSON := strings.HasSuffix(urlPath, ".json")
+	urlPath = strings.TrimSuffix(urlPath, ".json")
+	info, err := extractURLPathInfo(urlPath)
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	um, err := ds.GetUnitMeta(ctx, info.fullPath, info.modulePath, info.requestedVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	report, err := newLicenseReport(ctx, db.GetDependencyInfo, um.ModulePath, um.Version, licenses.CurrentPolicy())
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{
+				status:       http.StatusNotFound,
+				responseText: "the go.mod file of this version is not available; try refetching it",
+			}
+		}
+		return err
+	}
+	if r.Method == http.MethodPost {
+		report.NumEnqueued = s.enqueueUnprocessed(ctx, report.Unprocessed)
+	}
+	if asJSON {
+		return writeJSON(ctx, w, report)
+	}
+	report.URL = "/license-report/" + um.ModulePath + "@" + um.Version
+	report.basePage = s.newBasePage(r, um.ModulePath+" - License report")
+	s.servePage(ctx, w, "license_report.tmpl", report)
+	return nil
+}
+
+// maxEnqueuePerRequest is the largest number of unprocessed module versions
+// that a single request can enqueue for processing.
+const maxEnqueuePerRequest = 20
+
+// enqueueUnprocessed schedules the module versions in mods, which are of the
+// form path@version, to be fetched. It returns the number that were enqueued.
+//
+// At most maxEnqueuePerRequest module versions are scheduled, and none once
+// s.enqueueLimiter, which is shared by all requests, runs out of tokens. The
+// rest can be enqueued by a later request.
+func (s *Server) enqueueUnprocessed(ctx context.Context, mods []string) int {
+	n := 0
+	for i, mv := range mods {
+		if i >= maxEnqueuePerRequest || !s.enqueueLimiter.Allow() {
+			break
+		}
+		j := strings.LastIndex(mv, "@")
+		enqueued, err := s.queue.ScheduleFetch(ctx, mv[:j], mv[j+1:], "", false)
+		if err != nil {
+			log.Errorf(ctx, "enqueueUnprocessed: ScheduleFetch(%q): %v", mv, err)
+			continue
+		}
+		if enqueued {
+			n++
+		}
+	}
+	return n
+}
+
+// newLicenseReport computes the license report for the module version
+// modulePath@version, using getInfo to look up the licenses and go.mod
+// requirements of module versions, and p to decide whether their licenses
+// are acceptable. It returns a NotFound error if the go.mod requirements of
+// modulePath@version are not known.
+//
+// The build list is computed as the go command does for a main module, using
+// the replace directives of the main module's go.mod file. Exclude directives
+// are ignored.
+func newLicenseReport(ctx context.Context,
+	getInfo func(context.Context, []module.Version) (map[module.Version]*postgres.DependencyInfo, error),
+	modulePath, version string, p *licenses.Policy) (_ *LicenseReport, err error) {
+	defer derrors.Wrap(&err, "newLicenseReport(%q, %q)", modulePath, version)
+
+	root := module.Version{Path: modulePath, Version: version}
+	infos, err := getInfo(ctx, []module.Version{root})
+	if err != nil {
+		return nil, err
+	}
+	rootInfo := infos[root]
+	if rootInfo == nil || rootInfo.GoMod == nil {
+		return nil, derrors.NotFound
+	}
+	g := &requirementGraph{
+		main:    modulePath,
+		replace: replacements(rootInfo.GoMod.Replaces),
+		infos:   map[module.Version]*postgres.DependencyInfo{root: rootInfo},
+		visited: map[module.Version]bool{},
+	}
+	report := &LicenseReport{ModulePath: modulePath, Version: version}
+	unprocessed := map[string]bool{}
+
+	// Visit the graph breadth-first, looking up each level at once.
+	level := g.requirements(rootInfo)
+	for len(level) > 0 {
+		var targets []module.Version
+		for _, m := range level {
+			if t, ok := g.target(m); ok {
+				targets = append(targets, t)
+			}
+		}
+		infos, err := getInfo(ctx, targets)
+		if err != nil {
+			return nil, err
+		}
+		for t, info := range infos {
+			g.infos[t] = info
+		}
+		var next []module.Version
+		for _, m := range level {
+			t, ok := g.target(m)
+			if !ok {
+				continue
+			}
+			info := g.infos[t]
+			if info == nil {
+				unprocessed[t.String()] = true
+				continue
+			}
+			next = append(next, g.requirements(info)...)
+		}
+		level = next
+	}
+	for mv := range unprocessed {
+		report.Unprocessed = append(report.Unprocessed, mv)
+	}
+	sort.Strings(report.Unprocessed)
+	report.Truncated = g.truncated
+
+	// Select the highest version of each module path, as the go command does.
+	selected := map[string]string{}
+	for m := range g.visited {
+		if v, ok := selected[m.Path]; !ok || semver.Compare(m.Version, v) > 0 {
+			selected[m.Path] = m.Version
+		}
+	}
+	for path, v := range selected {
+		report.Dependencies = append(report.Dependencies, g.dependencyLicense(module.Version{Path: path, Version: v}, p))
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool {
+		return report.Dependencies[i].ModulePath < report.Dependencies[j].ModulePath
+	})
+	for _, d := range report.Dependencies {
+		if d.Violation {
+			report.NumViolations++
+		}
+	}
+	return report, nil
+}
+
+// A requirementGraph holds the state of a traversal of the requirement graph
+// of a main module.
+type requirementGraph struct {
+	// main is the path of the main module. Requirements on other versions of
+	// it are ignored, since the main module is always selected.
+	main string
+	// replace maps module versions, or module paths with an empty version, to
+	// their replacements in the main module's go.mod file. A replacement with
+	// an empty version is a directory.
+	replace map[module.Version]module.Version
+	infos   map[module.Version]*postgres.DependencyInfo
+	visited map[module.Version]bool
+	// truncated reports whether some requirements were not visited because
+	// maxReportModules was reached.
+	truncated bool
+}
+
+// replacements converts replace directives into the form used by
+// requirementGraph.
+func replacements(rs []*internal.GoModReplace) map[module.Version]module.Version {
+	m := map[module.Version]module.Version{}
+	for _, r := range rs {
+		m[module.Version{Path: r.OldPath, Version: r.OldVersion}] = module.Version{Path: r.NewPath, Version: r.NewVersion}
+	}
+	return m
+}
+
+// requirements returns the requirements of info that have not been visited,
+// and marks them visited. No more than maxReportModules module versions are
+// visited.
+func (g *requirementGraph) requirements(info *postgres.DependencyInfo) []module.Version {
+	if info.GoMod == nil {
+		return nil
+	}
+	var reqs []module.Version
+	for _, r := range info.GoMod.Requires {
+		m := module.Version{Path: r.ModulePath, Version: r.Version}
+		if m.Path == g.main || g.visited[m] {
+			continue
+		}
+		if len(g.visited) >= maxReportModules {
+			g.truncated = true
+			break
+		}
+		g.visited[m] = true
+		reqs = append(reqs, m)
+	}
+	return reqs
+}
+
+// replacement returns the replacement of m, if any.
+func (g *requirementGraph) replacement(m module.Version) (module.Version, bool) {
+	if r, ok := g.replace[m]; ok {
+		return r, true
+	}
+	r, ok := g.replace[module.Version{Path: m.Path}]
+	return r, ok
+}
+
+// target returns the module version whose contents are used for m. It
+// returns false if m is replaced by a directory.
+func (g *requirementGraph) target(m module.Version) (module.Version, bool) {
+	r, ok := g.replacement(m)
+	if !ok {
+		return m, true
+	}
+	return r, r.Version != ""
+}
+
+// dependencyLicense describes the licenses of the selected module version m.
+func (g *requirementGraph) dependencyLicense(m module.Version, p *licenses.Policy) *DependencyLicense {
+	d := &DependencyLicense{
+		ModulePath: m.Path,
+		Version:    m.Version,
+		URL:        constructUnitURL(m.Path, m.Path, m.Version),
+	}
+	t, ok := g.target(m)
+	if r, replaced := g.replacement(m); replaced {
+		d.Replacement = r.Path
+		if ok {
+			d.Replacement = r.String()
+		}
+	}
+	if !ok {
+		d.Explanation = fmt.Sprintf("Replaced by the directory %s, whose licenses are not checked.", d.Replacement)
+		return d
+	}
+	info := g.infos[t]
+	if info == nil {
+		d.Explanation = "This module version has not been processed."
+		return d
+	}
+	dec := p.Decide(t.Path, info.LicenseTypes)
+	d.Processed = true
+	d.LicenseTypes = info.LicenseTypes
+	d.Violation = !dec.Redistributable
+	d.Explanation = dec.Explanation
+	return d
+}
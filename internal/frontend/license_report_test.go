@@ -0,0 +1,160 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/time/rate"
+)
+
+func TestNewLicenseReport(t *testing.T) {
+	info := func(mv string, types []string, requires ...string) *postgres.DependencyInfo {
+		m := parseModuleVersion(t, mv)
+		gm := &internal.GoMod{}
+		for _, r := range requires {
+			rm := parseModuleVersion(t, r)
+			gm.Requires = append(gm.Requires, &internal.GoModRequire{ModulePath: rm.Path, Version: rm.Version})
+		}
+		return &postgres.DependencyInfo{ModulePath: m.Path, Version: m.Version, LicenseTypes: types, GoMod: gm}
+	}
+	root := info("example.com/m@v1.0.0", []string{"MIT"},
+		"example.com/a@v1.0.0", "example.com/b@v1.0.0", "example.com/local@v1.0.0", "example.com/old@v1.0.0")
+	root.GoMod.Replaces = []*internal.GoModReplace{
+		{OldPath: "example.com/local", NewPath: "./local"},
+		{OldPath: "example.com/old", OldVersion: "v1.0.0", NewPath: "example.com/new", NewVersion: "v2.0.0"},
+	}
+	db := map[module.Version]*postgres.DependencyInfo{}
+	for _, i := range []*postgres.DependencyInfo{
+		root,
+		info("example.com/a@v1.0.0", []string{"MIT"}),
+		info("example.com/a@v1.2.0", []string{"AGPL-3.0"}, "example.com/m@v0.9.0"),
+		info("example.com/b@v1.0.0", []string{"Apache-2.0"}, "example.com/a@v1.2.0", "example.com/c@v0.1.0"),
+		info("example.com/new@v2.0.0", []string{"BSD-3-Clause"}),
+	} {
+		db[module.Version{Path: i.ModulePath, Version: i.Version}] = i
+	}
+	getInfo := func(_ context.Context, mods []module.Version) (map[module.Version]*postgres.DependencyInfo, error) {
+		infos := map[module.Version]*postgres.DependencyInfo{}
+		for _, m := range mods {
+			if i := db[m]; i != nil {
+				infos[m] = i
+			}
+		}
+		return infos, nil
+	}
+
+	p := &licenses.Policy{Deny: []string{"AGPL-3.0"}}
+	got, err := newLicenseReport(context.Background(), getInfo, "example.com/m", "v1.0.0", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &LicenseReport{
+		ModulePath: "example.com/m",
+		Version:    "v1.0.0",
+		Dependencies: []*DependencyLicense{
+			{
+				ModulePath:   "example.com/a",
+				Version:      "v1.2.0",
+				Processed:    true,
+				LicenseTypes: []string{"AGPL-3.0"},
+				Violation:    true,
+				Explanation:  "The license policy does not allow AGPL-3.0.",
+			},
+			{
+				ModulePath:   "example.com/b",
+				Version:      "v1.0.0",
+				Processed:    true,
+				LicenseTypes: []string{"Apache-2.0"},
+				Explanation:  "Apache-2.0 is accepted by the license policy.",
+			},
+			{
+				ModulePath:  "example.com/c",
+				Version:     "v0.1.0",
+				Explanation: "This module version has not been processed.",
+			},
+			{
+				ModulePath:  "example.com/local",
+				Version:     "v1.0.0",
+				Replacement: "./local",
+				Explanation: "Replaced by the directory ./local, whose licenses are not checked.",
+			},
+			{
+				ModulePath:   "example.com/old",
+				Version:      "v1.0.0",
+				Replacement:  "example.com/new@v2.0.0",
+				Processed:    true,
+				LicenseTypes: []string{"BSD-3-Clause"},
+				Explanation:  "BSD-3-Clause is accepted by the license policy.",
+			},
+		},
+		NumViolations: 1,
+		Unprocessed:   []string{"example.com/c@v0.1.0"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(DependencyLicense{}, "URL"), cmpopts.IgnoreUnexported(LicenseReport{})); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// A module version without go.mod requirements has no report.
+	delete(db, module.Version{Path: "example.com/m", Version: "v1.0.0"})
+	if _, err := newLicenseReport(context.Background(), getInfo, "example.com/m", "v1.0.0", p); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("got error %v, want NotFound", err)
+	}
+}
+
+func parseModuleVersion(t *testing.T, mv string) module.Version {
+	t.Helper()
+	for i := len(mv) - 1; i >= 0; i-- {
+		if mv[i] == '@' {
+			return module.Version{Path: mv[:i], Version: mv[i+1:]}
+		}
+	}
+	t.Fatalf("%q: missing version", mv)
+	return module.Version{}
+}
+
+type countingQueue struct{ n int }
+
+func (q *countingQueue) ScheduleFetch(context.Context, string, string, string, bool) (bool, error) {
+	q.n++
+	return true, nil
+}
+
+func TestEnqueueUnprocessed(t *testing.T) {
+	ctx := context.Background()
+	q := &countingQueue{}
+	s := &Server{queue: q, enqueueLimiter: rate.NewLimiter(rate.Every(time.Hour), maxEnqueuePerRequest+5)}
+	var mods []string
+	for i := 0; i < 3*maxEnqueuePerRequest; i++ {
+		mods = append(mods, fmt.Sprintf("example.com/m%d@v1.0.0", i))
+	}
+
+	// A request enqueues at most maxEnqueuePerRequest modules.
+	if got := s.enqueueUnprocessed(ctx, mods); got != maxEnqueuePerRequest {
+		t.Errorf("first request: got %d enqueued, want %d", got, maxEnqueuePerRequest)
+	}
+	// The limiter is shared by requests, so the next one enqueues only what
+	// is left of the burst.
+	if got := s.enqueueUnprocessed(ctx, mods); got != 5 {
+		t.Errorf("second request: got %d enqueued, want 5", got)
+	}
+	if got := s.enqueueUnprocessed(ctx, mods); got != 0 {
+		t.Errorf("third request: got %d enqueued, want 0", got)
+	}
+	if want := maxEnqueuePerRequest + 5; q.n != want {
+		t.Errorf("got %d calls to ScheduleFetch, want %d", q.n, want)
+	}
+}
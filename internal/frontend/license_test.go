@@ -93,60 +93,70 @@ func TestFetchLicensesDetails(t *testing.T) {
 		err                                 error
 		name, fullPath, modulePath, version string
 		want                                []*licenses.License
+		wantExplanation                     string
 	}{
 		{
-			name:       "module root",
-			fullPath:   sample.ModulePath,
-			modulePath: sample.ModulePath,
-			version:    testModule.Version,
-			want:       []*licenses.License{testModule.Licenses[1]},
+			name:            "module root",
+			fullPath:        sample.ModulePath,
+			modulePath:      sample.ModulePath,
+			version:         testModule.Version,
+			want:            []*licenses.License{testModule.Licenses[1]},
+			wantExplanation: "MIT is accepted by the license policy.",
 		},
 		{
-			name:       "package without license",
-			fullPath:   sample.ModulePath + "/A",
-			modulePath: sample.ModulePath,
-			version:    testModule.Version,
-			want:       []*licenses.License{testModule.Licenses[1]},
+			name:            "package without license",
+			fullPath:        sample.ModulePath + "/A",
+			modulePath:      sample.ModulePath,
+			version:         testModule.Version,
+			want:            []*licenses.License{testModule.Licenses[1]},
+			wantExplanation: "MIT is accepted by the license policy.",
 		},
 		{
-			name:       "package with additional license",
-			fullPath:   sample.ModulePath + "/A/B",
-			modulePath: sample.ModulePath,
-			version:    testModule.Version,
-			want:       testModule.Licenses,
+			name:            "package with additional license",
+			fullPath:        sample.ModulePath + "/A/B",
+			modulePath:      sample.ModulePath,
+			version:         testModule.Version,
+			want:            testModule.Licenses,
+			wantExplanation: "BSD-3-Clause and MIT are accepted by the license policy.",
 		},
 		{
-			name:       "stdlib directory",
-			fullPath:   "cmd",
-			modulePath: stdlib.ModulePath,
-			version:    stdlibModule.Version,
-			want:       stdlibModule.Licenses,
+			name:            "stdlib directory",
+			fullPath:        "cmd",
+			modulePath:      stdlib.ModulePath,
+			version:         stdlibModule.Version,
+			want:            stdlibModule.Licenses,
+			wantExplanation: "MIT is accepted by the license policy.",
 		},
 		{
-			name:       "stdlib package",
-			fullPath:   "cmd/go",
-			modulePath: stdlib.ModulePath,
-			version:    stdlibModule.Version,
-			want:       stdlibModule.Licenses,
+			name:            "stdlib package",
+			fullPath:        "cmd/go",
+			modulePath:      stdlib.ModulePath,
+			version:         stdlibModule.Version,
+			want:            stdlibModule.Licenses,
+			wantExplanation: "MIT is accepted by the license policy.",
 		},
 		{
-			name:       "stdlib module",
-			fullPath:   stdlib.ModulePath,
-			modulePath: stdlib.ModulePath,
-			version:    stdlibModule.Version,
-			want:       stdlibModule.Licenses,
+			name:            "stdlib module",
+			fullPath:        stdlib.ModulePath,
+			modulePath:      stdlib.ModulePath,
+			version:         stdlibModule.Version,
+			want:            stdlibModule.Licenses,
+			wantExplanation: "MIT is accepted by the license policy.",
 		},
 		{
-			name:       "module with CRLF line terminators",
-			fullPath:   crlfPath,
-			modulePath: crlfPath,
-			version:    crlfModule.Version,
-			want:       crlfModule.Licenses,
+			name:            "module with CRLF line terminators",
+			fullPath:        crlfPath,
+			modulePath:      crlfPath,
+			version:         crlfModule.Version,
+			want:            crlfModule.Licenses,
+			wantExplanation: "MIT is accepted by the license policy.",
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			wantDetails := &LicensesDetails{Licenses: transformLicenses(
-				test.modulePath, test.version, test.want)}
+			wantDetails := &LicensesDetails{
+				Licenses: transformLicenses(test.modulePath, test.version, test.want),
+				Policy:   licenses.Decision{Redistributable: true, Explanation: test.wantExplanation},
+			}
 			got, err := fetchLicensesDetails(ctx, testDB, &internal.UnitMeta{
 				Path:       test.fullPath,
 				ModulePath: test.modulePath,
@@ -168,3 +178,43 @@ func TestFetchLicensesDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestLicenseSegments(t *testing.T) {
+	const extra = "The Software may not be used in military applications."
+	contents := strings.ReplaceAll(testhelper.MITLicense+"\n"+extra+"\n", "\n", "\r\n")
+	types, cov := licenses.DetectFile([]byte(contents), "LICENSE", nil)
+	lic := &licenses.License{
+		Metadata: &licenses.Metadata{Types: types, FilePath: "LICENSE", Coverage: cov},
+		Contents: []byte(contents),
+	}
+	got := transformLicenses(sample.ModulePath, sample.VersionString, []*licenses.License{lic})[0].Segments
+	var extras []string
+	for _, s := range got {
+		if strings.Contains(s.Text, "\r") {
+			t.Errorf("segment %q contains \\r", s.Text)
+		}
+		if s.Class != "" {
+			extras = append(extras, s.Class+": "+s.Text)
+		}
+	}
+	want := []string{"License-extra: " + strings.TrimSuffix(extra, ".")}
+	if diff := cmp.Diff(want, extras); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// Licenses that match their canonical texts have no segments.
+	types, cov = licenses.DetectFile([]byte(testhelper.MITLicense), "LICENSE", nil)
+	lic = &licenses.License{
+		Metadata: &licenses.Metadata{Types: types, FilePath: "LICENSE", Coverage: cov},
+		Contents: []byte(testhelper.MITLicense),
+	}
+	if got := licenseSegments(lic); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+
+	// Licenses whose contents are not available have no segments.
+	lic.Contents = nil
+	if got := licenseSegments(lic); got != nil {
+		t.Errorf("nil contents: got %v, want nil", got)
+	}
+}
@@ -0,0 +1,426 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// maxModCheckUpload is the largest go.mod or go.sum file, in bytes, that
+	// can be checked.
+	maxModCheckUpload = 1 << 20
+	// maxModCheckRequirements is the largest number of requirements that are
+	// checked.
+	maxModCheckRequirements = 300
+)
+
+// ModCheck is a report on the requirements of an uploaded go.mod file, and
+// the modules listed in a go.sum file uploaded along with it: which newer
+// versions exist, whether the versions are retracted or have known
+// vulnerabilities, and which licenses apply.
+type ModCheck struct {
+	basePage `json:"-"`
+
+	// ModulePath is the path of the module declared by the go.mod file.
+	ModulePath   string              `json:"modulePath"`
+	Requirements []*RequirementCheck `json:"requirements"`
+
+	// NumViolations is the number of requirements whose licenses are not
+	// accepted by the license policy.
+	NumViolations int `json:"numViolations"`
+	// NumUpdates is the number of requirements for which a newer minor or
+	// major version exists.
+	NumUpdates int `json:"numUpdates"`
+	// NumRetracted is the number of requirements on retracted versions.
+	NumRetracted int `json:"numRetracted"`
+	// NumVulnerable is the number of requirements on versions with known
+	// vulnerabilities.
+	NumVulnerable int `json:"numVulnerable"`
+
+	// Unprocessed lists the module versions, as path@version, that pkgsite
+	// has not processed. Their licenses and retractions are unknown until
+	// they are.
+	Unprocessed []string `json:"unprocessed"`
+	// NumEnqueued is the number of unprocessed module versions that were
+	// enqueued for processing.
+	NumEnqueued int `json:"numEnqueued"`
+	// Truncated reports whether there were too many requirements for all of
+	// them to be checked.
+	Truncated bool `json:"truncated"`
+
+	// GoMod and GoSum are the uploaded files, shown again in the form.
+	GoMod string `json:"-"`
+	GoSum string `json:"-"`
+	// Error describes why the uploaded files could not be checked.
+	Error string `json:"-"`
+	// Checked reports whether files were uploaded and checked.
+	Checked bool `json:"-"`
+}
+
+// RequirementCheck describes one requirement in a ModCheck.
+type RequirementCheck struct {
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+	URL        string `json:"-"`
+	Indirect   bool   `json:"indirect"`
+	// FromGoSum reports whether the module version is not required by the
+	// go.mod file, but is listed in the go.sum file. It is the highest
+	// version of the module there.
+	FromGoSum bool `json:"fromGoSum"`
+	// MissingSum reports whether a go.sum file was uploaded that has no
+	// checksum for the module version.
+	MissingSum bool `json:"missingSum"`
+
+	// Replacement is the module version, as path@version, that replaces this
+	// one according to the go.mod file, or the directory that replaces it.
+	// The licenses, retraction and vulnerabilities are those of the
+	// replacement module.
+	Replacement string `json:"replacement,omitempty"`
+
+	// Processed reports whether pkgsite has processed the module version.
+	Processed    bool     `json:"processed"`
+	LicenseTypes []string `json:"licenseTypes"`
+	// Violation reports whether the license policy does not accept the
+	// module's licenses.
+	Violation   bool   `json:"violation"`
+	Explanation string `json:"explanation,omitempty"`
+
+	Retracted           bool   `json:"retracted"`
+	RetractionRationale string `json:"retractionRationale,omitempty"`
+
+	// LatestMinorVersion is the latest version of the module, if it is
+	// higher than Version.
+	LatestMinorVersion string `json:"latestMinorVersion,omitempty"`
+	// LatestMajorModulePath is the module path of the latest major version
+	// of the module, if it is not ModulePath.
+	LatestMajorModulePath string `json:"latestMajorModulePath,omitempty"`
+
+	Vulns []*Vuln `json:"vulns"`
+
+	// target is the module version whose contents are used, or the zero
+	// value if it is replaced by a directory.
+	target module.Version
+}
+
+// serveModCheck handles requests for /mod-check. A GET request serves a form
+// for uploading a go.mod file, and optionally a go.sum file. A POST request
+// checks the uploaded files, in the "gomod" and "gosum" form values or in the
+// "gomodfile" and "gosumfile" uploaded files, serving the report as HTML, or
+// as JSON if the request path is /mod-check.json. The requirements that have
+// not been processed are enqueued to be fetched.
+func (s *Server) serveModCheck(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveModCheck(%q)", r.URL.Path)
+
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not store licenses or retractions.
+		return proxydatasourceNotSupportedErr()
+	}
+	asJSON := r.URL.Path == "/mod-check.json"
+	ctx := r.Context()
+	check := &ModCheck{}
+	switch r.Method {
+	case http.MethodGet:
+		if asJSON {
+			return &serverError{status: http.StatusMethodNotAllowed}
+		}
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, 2*maxModCheckUpload+1<<16)
+		if err := s.runModCheck(ctx, r, db, check); err != nil {
+			if !errors.Is(err, derrors.InvalidArgument) {
+				return err
+			}
+			if asJSON {
+				return &serverError{status: http.StatusBadRequest, err: err, responseText: check.Error}
+			}
+		}
+	default:
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	if asJSON {
+		return writeJSON(ctx, w, check)
+	}
+	check.basePage = s.newBasePage(r, "Check a go.mod file")
+	s.servePage(ctx, w, "mod_check.tmpl", check)
+	return nil
+}
+
+// runModCheck reads the files uploaded with r and fills in check. If they
+// cannot be checked, it sets check.Error and returns an error wrapping
+// derrors.InvalidArgument.
+func (s *Server) runModCheck(ctx context.Context, r *http.Request, db *postgres.DB, check *ModCheck) (err error) {
+	invalid := func(format string, args ...interface{}) error {
+		check.Error = fmt.Sprintf(format, args...)
+		return fmt.Errorf("%s: %w", check.Error, derrors.InvalidArgument)
+	}
+	if err := r.ParseMultipartForm(maxModCheckUpload); err != nil && err != http.ErrNotMultipart {
+		return invalid("Reading the upload: %v", err)
+	}
+	if check.GoMod, err = readModCheckUpload(r, "gomod"); err != nil {
+		return invalid("Reading go.mod: %v", err)
+	}
+	if check.GoSum, err = readModCheckUpload(r, "gosum"); err != nil {
+		return invalid("Reading go.sum: %v", err)
+	}
+	if strings.TrimSpace(check.GoMod) == "" {
+		return invalid("No go.mod file was given.")
+	}
+	gm, err := fetch.ParseGoMod([]byte(check.GoMod))
+	if err != nil {
+		return invalid("The go.mod file is invalid: %v", err)
+	}
+	var sums map[module.Version]bool
+	if strings.TrimSpace(check.GoSum) != "" {
+		sums, err = parseGoSum(check.GoSum)
+		if err != nil {
+			return invalid("The go.sum file is invalid: %v", err)
+		}
+	}
+	check.ModulePath = modfile.ModulePath([]byte(check.GoMod))
+	check.Requirements, check.Truncated = requirementsToCheck(gm, sums)
+	if err := s.lookUpRequirements(ctx, db, check.Requirements, licenses.CurrentPolicy()); err != nil {
+		return err
+	}
+	summarizeModCheck(check)
+	check.NumEnqueued = s.enqueueUnprocessed(ctx, check.Unprocessed)
+	check.Checked = true
+	return nil
+}
+
+// readModCheckUpload returns the contents of the uploaded file name+"file",
+// or else the form value name.
+func readModCheckUpload(r *http.Request, name string) (string, error) {
+	f, _, err := r.FormFile(name + "file")
+	if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+		return r.FormValue(name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	if len(b) > maxModCheckUpload {
+		return "", fmt.Errorf("larger than %d bytes", maxModCheckUpload)
+	}
+	return string(b), nil
+}
+
+// parseGoSum returns the module versions that have checksums in the
+// contents of a go.sum file. The value of a module version is true if the
+// checksum is of the module's contents, and false if it is only of its
+// go.mod file.
+func parseGoSum(contents string) (map[module.Version]bool, error) {
+	sums := map[module.Version]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: want 3 fields, got %d", n, len(fields))
+		}
+		v := strings.TrimSuffix(fields[1], "/go.mod")
+		if !semver.IsValid(v) {
+			return nil, fmt.Errorf("line %d: invalid version %q", n, fields[1])
+		}
+		m := module.Version{Path: fields[0], Version: v}
+		sums[m] = sums[m] || v == fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// requirementsToCheck returns the requirements of gm, and, if sums is not
+// nil, the highest version of each other module whose contents have a
+// checksum in sums, which is part of the build when go.mod files do not list
+// every indirect requirement. The requirements of gm are sorted by module
+// path and come first. No more than maxModCheckRequirements are returned;
+// the second result reports whether some were dropped.
+func requirementsToCheck(gm *internal.GoMod, sums map[module.Version]bool) ([]*RequirementCheck, bool) {
+	g := &requirementGraph{replace: replacements(gm.Replaces)}
+	newCheck := func(m module.Version) *RequirementCheck {
+		c := &RequirementCheck{
+			ModulePath: m.Path,
+			Version:    m.Version,
+			URL:        constructUnitURL(m.Path, m.Path, m.Version),
+		}
+		t, ok := g.target(m)
+		if r, replaced := g.replacement(m); replaced {
+			c.Replacement = r.Path
+			if ok {
+				c.Replacement = r.String()
+			}
+		}
+		if ok {
+			c.target = t
+		}
+		return c
+	}
+
+	var reqs []*RequirementCheck
+	required := map[string]bool{}
+	for _, r := range gm.Requires {
+		m := module.Version{Path: r.ModulePath, Version: r.Version}
+		if required[m.Path] {
+			continue
+		}
+		required[m.Path] = true
+		c := newCheck(m)
+		c.Indirect = r.Indirect
+		if sums != nil {
+			_, ok := sums[m]
+			c.MissingSum = !ok
+		}
+		reqs = append(reqs, c)
+	}
+	sortRequirementChecks(reqs)
+
+	highest := map[string]string{}
+	for m, hasContents := range sums {
+		if !hasContents || required[m.Path] {
+			continue
+		}
+		if v, ok := highest[m.Path]; !ok || semver.Compare(m.Version, v) > 0 {
+			highest[m.Path] = m.Version
+		}
+	}
+	var fromSum []*RequirementCheck
+	for path, v := range highest {
+		c := newCheck(module.Version{Path: path, Version: v})
+		c.Indirect = true
+		c.FromGoSum = true
+		fromSum = append(fromSum, c)
+	}
+	sortRequirementChecks(fromSum)
+	reqs = append(reqs, fromSum...)
+
+	if len(reqs) > maxModCheckRequirements {
+		return reqs[:maxModCheckRequirements], true
+	}
+	return reqs, false
+}
+
+// sortRequirementChecks sorts cs by module path.
+func sortRequirementChecks(cs []*RequirementCheck) {
+	sort.Slice(cs, func(i, j int) bool { return cs[i].ModulePath < cs[j].ModulePath })
+}
+
+// lookUpRequirements fills in the information that db has about each of
+// reqs, using p to decide whether their licenses are acceptable. It looks up
+// all of the requirements at once, so the number of queries does not depend
+// on the number of requirements.
+func (s *Server) lookUpRequirements(ctx context.Context, db *postgres.DB, reqs []*RequirementCheck, p *licenses.Policy) error {
+	var (
+		targets     []module.Version
+		targetPaths []string
+		paths       []string
+	)
+	for _, c := range reqs {
+		if c.target.Path != "" {
+			targets = append(targets, c.target)
+			targetPaths = append(targetPaths, c.target.Path)
+		}
+		paths = append(paths, c.ModulePath)
+	}
+	infos, err := db.GetDependencyInfo(ctx, targets)
+	if err != nil {
+		return err
+	}
+	vulnEntries, err := db.GetVulnEntriesForModules(ctx, targetPaths)
+	if err != nil {
+		return err
+	}
+	// Newer versions are those of the required module, even if it is
+	// replaced.
+	latest, err := db.GetLatestModuleVersions(ctx, paths)
+	if err != nil {
+		return err
+	}
+	for _, c := range reqs {
+		if c.target.Path == "" {
+			c.Explanation = fmt.Sprintf("Replaced by the directory %s, whose licenses are not checked.", c.Replacement)
+		} else {
+			c.setInfo(infos[c.target], p)
+			c.Vulns = affectingVulns(vulnEntries[c.target.Path], c.target.Path, c.target.Version, c.target.Path)
+		}
+		lv := latest[c.ModulePath]
+		if lv == nil {
+			continue
+		}
+		if lv.Version != "" {
+			if v := linkVersion(lv.Version, c.ModulePath); semver.Compare(v, c.Version) > 0 {
+				c.LatestMinorVersion = v
+			}
+		}
+		if lv.MajorModulePath != c.ModulePath {
+			c.LatestMajorModulePath = lv.MajorModulePath
+		}
+	}
+	return nil
+}
+
+// setInfo fills in the licenses and retraction of c from info, which is nil
+// if the module version has not been processed.
+func (c *RequirementCheck) setInfo(info *postgres.DependencyInfo, p *licenses.Policy) {
+	if info == nil {
+		c.Explanation = "This module version has not been processed."
+		return
+	}
+	dec := p.Decide(c.target.Path, info.LicenseTypes)
+	c.Processed = true
+	c.LicenseTypes = info.LicenseTypes
+	c.Violation = !dec.Redistributable
+	c.Explanation = dec.Explanation
+	c.Retracted = info.Retracted
+	c.RetractionRationale = info.RetractionRationale
+}
+
+// summarizeModCheck sets the counts and the list of unprocessed module
+// versions of check from its requirements.
+func summarizeModCheck(check *ModCheck) {
+	seen := map[string]bool{}
+	for _, c := range check.Requirements {
+		if c.Violation {
+			check.NumViolations++
+		}
+		if c.LatestMinorVersion != "" || c.LatestMajorModulePath != "" {
+			check.NumUpdates++
+		}
+		if c.Retracted {
+			check.NumRetracted++
+		}
+		if len(c.Vulns) > 0 {
+			check.NumVulnerable++
+		}
+		if c.target.Path != "" && !c.Processed && !seen[c.target.String()] {
+			seen[c.target.String()] = true
+			check.Unprocessed = append(check.Unprocessed, c.target.String())
+		}
+	}
+	sort.Strings(check.Unprocessed)
+}
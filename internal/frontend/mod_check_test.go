@@ -0,0 +1,95 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+func TestParseGoSum(t *testing.T) {
+	got, err := parseGoSum(`
+example.com/a v1.0.0 h1:aaa=
+example.com/a v1.0.0/go.mod h1:bbb=
+example.com/b v1.1.0/go.mod h1:ccc=
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[module.Version]bool{
+		{Path: "example.com/a", Version: "v1.0.0"}: true,
+		{Path: "example.com/b", Version: "v1.1.0"}: false,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, bad := range []string{"example.com/a v1.0.0", "example.com/a 1.0 h1:aaa="} {
+		if _, err := parseGoSum(bad); err == nil {
+			t.Errorf("parseGoSum(%q): got nil error", bad)
+		}
+	}
+}
+
+func TestRequirementsToCheck(t *testing.T) {
+	gm := &internal.GoMod{
+		Requires: []*internal.GoModRequire{
+			{ModulePath: "example.com/c", Version: "v1.0.0", Indirect: true},
+			{ModulePath: "example.com/a", Version: "v1.0.0"},
+			{ModulePath: "example.com/dir", Version: "v1.0.0"},
+		},
+		Replaces: []*internal.GoModReplace{
+			{OldPath: "example.com/c", NewPath: "example.com/fork", NewVersion: "v1.0.1"},
+			{OldPath: "example.com/dir", NewPath: "../dir"},
+		},
+	}
+	sums := map[module.Version]bool{
+		{Path: "example.com/a", Version: "v1.0.0"}:   true,
+		{Path: "example.com/c", Version: "v1.0.0"}:   true,
+		{Path: "example.com/e", Version: "v1.1.0"}:   true,
+		{Path: "example.com/e", Version: "v1.2.0"}:   true,
+		{Path: "example.com/f", Version: "v1.0.0"}:   false,
+		{Path: "example.com/a", Version: "v0.9.0"}:   true,
+		{Path: "example.com/dir", Version: "v0.1.0"}: false,
+	}
+	got, truncated := requirementsToCheck(gm, sums)
+	if truncated {
+		t.Error("got truncated, want false")
+	}
+	mv := func(path, version string) module.Version { return module.Version{Path: path, Version: version} }
+	want := []*RequirementCheck{
+		{ModulePath: "example.com/a", Version: "v1.0.0", target: mv("example.com/a", "v1.0.0")},
+		{ModulePath: "example.com/c", Version: "v1.0.0", Indirect: true, Replacement: "example.com/fork@v1.0.1",
+			target: mv("example.com/fork", "v1.0.1")},
+		{ModulePath: "example.com/dir", Version: "v1.0.0", MissingSum: true, Replacement: "../dir"},
+		{ModulePath: "example.com/e", Version: "v1.2.0", Indirect: true, FromGoSum: true, target: mv("example.com/e", "v1.2.0")},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(RequirementCheck{}), cmpopts.IgnoreFields(RequirementCheck{}, "URL")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSummarizeModCheck(t *testing.T) {
+	a := &RequirementCheck{ModulePath: "example.com/a", Version: "v1.0.0", target: module.Version{Path: "example.com/a", Version: "v1.0.0"}}
+	a.setInfo(&postgres.DependencyInfo{Retracted: true, RetractionRationale: "bad"}, licenses.CurrentPolicy())
+	a.LatestMinorVersion = "v1.1.0"
+	b := &RequirementCheck{ModulePath: "example.com/b", Version: "v1.0.0", target: module.Version{Path: "example.com/b", Version: "v1.0.0"}}
+	b.setInfo(nil, licenses.CurrentPolicy())
+	b.Vulns = []*Vuln{{ID: "GO-1"}}
+	check := &ModCheck{Requirements: []*RequirementCheck{a, b}}
+	summarizeModCheck(check)
+	if check.NumUpdates != 1 || check.NumRetracted != 1 || check.NumVulnerable != 1 {
+		t.Errorf("got %d updates, %d retracted, %d vulnerable; want 1 of each", check.NumUpdates, check.NumRetracted, check.NumVulnerable)
+	}
+	if want := []string{"example.com/b@v1.0.0"}; !cmp.Equal(check.Unprocessed, want) {
+		t.Errorf("got unprocessed %v, want %v", check.Unprocessed, want)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+)
+
+const (
+	// maxGraphEdges is the largest number of requirement edges in a module
+	// graph.
+	maxGraphEdges = 5000
+	// maxRequiredBy is the largest number of requiring modules in a module
+	// graph.
+	maxRequiredBy = 1000
+)
+
+// ModuleGraph describes the module requirement graph around a module
+// version: the module versions it requires, directly and transitively,
+// according to the go.mod files that have been processed, and the modules
+// that require it.
+type ModuleGraph struct {
+	basePage `json:"-"`
+
+	ModulePath string `json:"modulePath"`
+	Version    string `json:"version"`
+
+	// Edges are the edges of the graph that can be reached from the module
+	// version, sorted.
+	Edges []*GraphEdge `json:"edges"`
+	// Truncated reports whether the graph was too large for all of its
+	// edges to be included.
+	Truncated bool `json:"truncated"`
+
+	// RequiredBy lists the modules that require the module, with the
+	// version of it that their highest processed versions require.
+	RequiredBy []*GraphDependent `json:"requiredBy"`
+	// RequiredByTruncated reports whether there were too many requiring
+	// modules for all of them to be listed.
+	RequiredByTruncated bool `json:"requiredByTruncated"`
+
+	// Requires lists the direct requirements of the module version.
+	Requires []*GraphModule `json:"-"`
+	// Transitive lists the module versions that are required directly or
+	// transitively.
+	Transitive []*GraphModule `json:"-"`
+	// URL is the URL of the graph page, to which the suffixes of the
+	// exported forms are added.
+	URL string `json:"-"`
+}
+
+// GraphEdge is an edge of a ModuleGraph. From and To are of the form
+// path@version.
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Indirect bool   `json:"indirect"`
+}
+
+// GraphModule is a module version required by the module of a ModuleGraph.
+type GraphModule struct {
+	ModulePath string
+	Version    string
+	URL        string
+	Indirect   bool
+	// Depth is the length of the shortest path to the module version from the
+	// module of the graph.
+	Depth int
+}
+
+// GraphDependent is a module that requires the module of a ModuleGraph.
+type GraphDependent struct {
+	ModulePath string `json:"modulePath"`
+	// Version is the highest processed version of the requiring module.
+	Version string `json:"version"`
+	// RequiredVersion is the version of the module of the graph that
+	// Version requires.
+	RequiredVersion string `json:"requiredVersion"`
+	Indirect        bool   `json:"indirect"`
+	URL             string `json:"-"`
+}
+
+// serveModuleGraph handles requests for /mod-graph/<path>[@version]. It
+// serves the requirement graph of the module containing path as HTML, as JSON
+// if the request path ends in ".json", or in the DOT language of Graphviz if
+// it ends in ".dot".
+func (s *Server) serveModuleGraph(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveModuleGraph(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not store go.mod files.
+		return proxydatasourceNotSupportedErr()
+	}
+	ctx := r.Context()
+	urlPath := strings.TrimPrefix(r.URL.Path, "/mod-graph")
+	var ext string
+	for _, e := range []string{".json", ".dot"} {
+		if strings.HasSuffix(urlPath, e) {
+			ext = e
+			urlPath = strings.TrimSuffix(urlPath, e)
+		}
+	}
+	info, err := extractURLPathInfo(urlPath)
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	um, err := ds.GetUnitMeta(ctx, info.fullPath, info.modulePath, info.requestedVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	edges, err := db.GetRequirementEdges(ctx, um.ModulePath, um.Version, true, maxGraphEdges+1)
+	if err != nil {
+		return err
+	}
+	requiredBy, err := db.GetRequiredBy(ctx, um.ModulePath, maxRequiredBy+1)
+	if err != nil {
+		return err
+	}
+	graph := newModuleGraph(um.ModulePath, um.Version, edges, requiredBy)
+
+	var (
+		body        []byte
+		contentType string
+	)
+	switch ext {
+	case ".json":
+		return writeJSON(ctx, w, graph)
+	case ".dot":
+		body = []byte(graph.dot())
+		contentType = "text/vnd.graphviz; charset=utf-8"
+	default:
+		graph.URL = "/mod-graph/" + um.ModulePath + "@" + um.Version
+		graph.basePage = s.newBasePage(r, um.ModulePath+" - Module graph")
+		s.servePage(ctx, w, "module_graph.tmpl", graph)
+		return nil
+	}
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(body); err != nil {
+		log.Errorf(ctx, "Error writing module graph: %v", err)
+	}
+	return nil
+}
+
+// newModuleGraph returns the graph of modulePath@version, given the edges
+// that can be reached from it and the edges that end at a version of
+// modulePath. If there are more than maxGraphEdges or maxRequiredBy of them,
+// the extra ones are dropped and the graph is marked truncated.
+func newModuleGraph(modulePath, version string, edges, requiredBy []*internal.RequirementEdge) *ModuleGraph {
+	g := &ModuleGraph{
+		ModulePath: modulePath,
+		Version:    version,
+		Edges:      []*GraphEdge{},
+		RequiredBy: []*GraphDependent{},
+	}
+	if len(edges) > maxGraphEdges {
+		edges = edges[:maxGraphEdges]
+		g.Truncated = true
+	}
+	if len(requiredBy) > maxRequiredBy {
+		requiredBy = requiredBy[:maxRequiredBy]
+		g.RequiredByTruncated = true
+	}
+
+	// Find the shortest path to each module version, breadth-first. The
+	// graph may have cycles, so module versions are visited only once.
+	root := modulePath + "@" + version
+	out := map[string][]*internal.RequirementEdge{}
+	for _, e := range edges {
+		from := e.ModulePath + "@" + e.Version
+		out[from] = append(out[from], e)
+		g.Edges = append(g.Edges, &GraphEdge{From: from, To: e.RequiredPath + "@" + e.RequiredVersion, Indirect: e.Indirect})
+	}
+	depth := map[string]int{root: 0}
+	level := []string{root}
+	for d := 1; len(level) > 0; d++ {
+		var next []string
+		for _, from := range level {
+			for _, e := range out[from] {
+				to := e.RequiredPath + "@" + e.RequiredVersion
+				if _, ok := depth[to]; ok {
+					continue
+				}
+				depth[to] = d
+				next = append(next, to)
+				m := &GraphModule{
+					ModulePath: e.RequiredPath,
+					Version:    e.RequiredVersion,
+					URL:        constructUnitURL(e.RequiredPath, e.RequiredPath, e.RequiredVersion),
+					Indirect:   e.Indirect,
+					Depth:      d,
+				}
+				if d == 1 {
+					g.Requires = append(g.Requires, m)
+				}
+				g.Transitive = append(g.Transitive, m)
+			}
+		}
+		level = next
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	for _, ms := range [][]*GraphModule{g.Requires, g.Transitive} {
+		sortGraphModules(ms)
+	}
+	for _, e := range requiredBy {
+		g.RequiredBy = append(g.RequiredBy, &GraphDependent{
+			ModulePath:      e.ModulePath,
+			Version:         e.Version,
+			RequiredVersion: e.RequiredVersion,
+			Indirect:        e.Indirect,
+			URL:             constructUnitURL(e.ModulePath, e.ModulePath, e.Version),
+		})
+	}
+	return g
+}
+
+// sortGraphModules sorts ms by module path, and then by version.
+func sortGraphModules(ms []*GraphModule) {
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].ModulePath != ms[j].ModulePath {
+			return ms[i].ModulePath < ms[j].ModulePath
+		}
+		return semver.Compare(ms[i].Version, ms[j].Version) < 0
+	})
+}
+
+// dot returns g in the DOT language of Graphviz. Indirect requirements are
+// drawn dashed, and the requirements of the modules that require the module
+// of g are drawn in gray.
+func (g *ModuleGraph) dot() string {
+	var b strings.Builder
+	root := g.ModulePath + "@" + g.Version
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(root))
+	fmt.Fprintf(&b, "\t%s [style=bold];\n", strconv.Quote(root))
+	for _, e := range g.Edges {
+		attrs := ""
+		if e.Indirect {
+			attrs = " [style=dashed]"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), attrs)
+	}
+	for _, d := range g.RequiredBy {
+		attrs := "color=gray"
+		if d.Indirect {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [%s];\n", strconv.Quote(d.ModulePath+"@"+d.Version),
+			strconv.Quote(g.ModulePath+"@"+d.RequiredVersion), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
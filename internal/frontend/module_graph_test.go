@@ -0,0 +1,93 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestNewModuleGraph(t *testing.T) {
+	edge := func(from, fromVersion, to, toVersion string, indirect bool) *internal.RequirementEdge {
+		return &internal.RequirementEdge{ModulePath: from, Version: fromVersion, RequiredPath: to, RequiredVersion: toVersion, Indirect: indirect}
+	}
+	// The graph has a cycle between a and b.
+	edges := []*internal.RequirementEdge{
+		edge("example.com/b", "v1.0.0", "example.com/a", "v1.0.0", false),
+		edge("example.com/a", "v1.0.0", "example.com/b", "v1.0.0", false),
+		edge("example.com/b", "v1.0.0", "example.com/c", "v1.1.0", true),
+		edge("example.com/a", "v1.0.0", "example.com/c", "v1.0.0", false),
+	}
+	requiredBy := []*internal.RequirementEdge{
+		edge("example.com/b", "v1.0.0", "example.com/a", "v1.0.0", false),
+		edge("example.com/d", "v2.0.0", "example.com/a", "v0.9.0", true),
+	}
+	got := newModuleGraph("example.com/a", "v1.0.0", edges, requiredBy)
+	want := &ModuleGraph{
+		ModulePath: "example.com/a",
+		Version:    "v1.0.0",
+		Edges: []*GraphEdge{
+			{From: "example.com/a@v1.0.0", To: "example.com/b@v1.0.0"},
+			{From: "example.com/a@v1.0.0", To: "example.com/c@v1.0.0"},
+			{From: "example.com/b@v1.0.0", To: "example.com/a@v1.0.0"},
+			{From: "example.com/b@v1.0.0", To: "example.com/c@v1.1.0", Indirect: true},
+		},
+		RequiredBy: []*GraphDependent{
+			{ModulePath: "example.com/b", Version: "v1.0.0", RequiredVersion: "v1.0.0"},
+			{ModulePath: "example.com/d", Version: "v2.0.0", RequiredVersion: "v0.9.0", Indirect: true},
+		},
+		Requires: []*GraphModule{
+			{ModulePath: "example.com/b", Version: "v1.0.0", Depth: 1},
+			{ModulePath: "example.com/c", Version: "v1.0.0", Depth: 1},
+		},
+		Transitive: []*GraphModule{
+			{ModulePath: "example.com/b", Version: "v1.0.0", Depth: 1},
+			{ModulePath: "example.com/c", Version: "v1.0.0", Depth: 1},
+			{ModulePath: "example.com/c", Version: "v1.1.0", Indirect: true, Depth: 2},
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(ModuleGraph{}),
+		cmpopts.IgnoreFields(GraphModule{}, "URL"),
+		cmpopts.IgnoreFields(GraphDependent{}, "URL"),
+	}
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	wantDOT := `digraph "example.com/a@v1.0.0" {
+	"example.com/a@v1.0.0" [style=bold];
+	"example.com/a@v1.0.0" -> "example.com/b@v1.0.0";
+	"example.com/a@v1.0.0" -> "example.com/c@v1.0.0";
+	"example.com/b@v1.0.0" -> "example.com/a@v1.0.0";
+	"example.com/b@v1.0.0" -> "example.com/c@v1.1.0" [style=dashed];
+	"example.com/b@v1.0.0" -> "example.com/a@v1.0.0" [color=gray];
+	"example.com/d@v2.0.0" -> "example.com/a@v0.9.0" [color=gray, style=dashed];
+}
+`
+	if diff := cmp.Diff(wantDOT, got.dot()); diff != "" {
+		t.Errorf("dot mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewModuleGraphTruncated(t *testing.T) {
+	var edges []*internal.RequirementEdge
+	for i := 0; i <= maxGraphEdges; i++ {
+		edges = append(edges, &internal.RequirementEdge{
+			ModulePath: "example.com/a", Version: "v1.0.0",
+			RequiredPath: "example.com/b", RequiredVersion: "v1.0.0",
+		})
+	}
+	got := newModuleGraph("example.com/a", "v1.0.0", edges, nil)
+	if !got.Truncated || len(got.Edges) != maxGraphEdges {
+		t.Errorf("got Truncated = %t with %d edges, want true with %d", got.Truncated, len(got.Edges), maxGraphEdges)
+	}
+	if got.RequiredByTruncated || got.RequiredBy == nil {
+		t.Errorf("got RequiredByTruncated = %t, RequiredBy = %v; want false, empty", got.RequiredByTruncated, got.RequiredBy)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/sbom"
+)
+
+// SBOM formats, as file name suffixes of /sbom URLs.
+const (
+	sbomSPDXSuffix      = ".spdx.json"
+	sbomCycloneDXSuffix = ".cdx.json"
+)
+
+// serveSBOM handles requests for /sbom/<module>@<version>.spdx.json and
+// /sbom/<module>@<version>.cdx.json. It serves a software bill of materials
+// for the module version in the SPDX or CycloneDX format. Requests for other
+// versions, like "latest", or for paths within the module are redirected to
+// the URL for the module and its resolved version, so that the SBOM of a
+// module version always has the same URL.
+func (s *Server) serveSBOM(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveSBOM(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not store go.mod files or license metadata.
+		return proxydatasourceNotSupportedErr()
+	}
+	ctx := r.Context()
+	urlPath := strings.TrimPrefix(r.URL.Path, "/sbom")
+	var suffix string
+	for _, sfx := range []string{sbomSPDXSuffix, sbomCycloneDXSuffix} {
+		if strings.HasSuffix(urlPath, sfx) {
+			suffix = sfx
+		}
+	}
+	if suffix == "" {
+		return &serverError{status: http.StatusNotFound}
+	}
+	info, err := extractURLPathInfo(strings.TrimSuffix(urlPath, suffix))
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	um, err := ds.GetUnitMeta(ctx, info.fullPath, info.modulePath, info.requestedVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	canonicalPath := "/sbom/" + um.ModulePath + "@" + um.Version + suffix
+	if r.URL.Path != canonicalPath {
+		http.Redirect(w, r, canonicalPath, http.StatusFound)
+		return nil
+	}
+	m, err := sbomModule(ctx, db, um)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	switch suffix {
+	case sbomSPDXSuffix:
+		// The namespace comes from the configured site URL rather than the
+		// Host header, which is chosen by the client.
+		body, err = sbom.SPDX(m, s.siteURL+canonicalPath)
+		w.Header().Set("Content-Type", "application/spdx+json")
+	case sbomCycloneDXSuffix:
+		body, err = sbom.CycloneDX(m)
+		w.Header().Set("Content-Type", "application/vnd.cyclonedx+json")
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		log.Errorf(ctx, "Error writing SBOM: %v", err)
+	}
+	return nil
+}
+
+// sbomModule collects the data needed to generate an SBOM for the module
+// version described by um, which must be a module root.
+func sbomModule(ctx context.Context, db *postgres.DB, um *internal.UnitMeta) (_ *sbom.Module, err error) {
+	defer derrors.Wrap(&err, "sbomModule(%q, %q)", um.ModulePath, um.Version)
+
+	mi, err := db.GetModuleInfo(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := db.GetPackagesInModule(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return nil, err
+	}
+	lics, err := db.GetLicenseMetadata(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return nil, err
+	}
+	m := &sbom.Module{Info: mi, Packages: pkgs, Licenses: lics}
+
+	gm, err := db.GetGoMod(ctx, um.ModulePath, um.Version)
+	if errors.Is(err, derrors.NotFound) {
+		// The go.mod file was not stored when the module was processed, or
+		// the module has none.
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mods []module.Version
+	for _, r := range gm.Requires {
+		mods = append(mods, module.Version{Path: r.ModulePath, Version: r.Version})
+	}
+	infos, err := db.GetDependencyInfo(ctx, mods)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range gm.Requires {
+		d := &sbom.Dependency{ModulePath: r.ModulePath, Version: r.Version, Indirect: r.Indirect}
+		if info := infos[module.Version{Path: r.ModulePath, Version: r.Version}]; info != nil {
+			d.LicenseTypes = append([]string{}, info.LicenseTypes...)
+		}
+		m.Dependencies = append(m.Dependencies, d)
+	}
+	return m, nil
+}
@@ -11,6 +11,7 @@ import (
 	"math"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +30,9 @@ type SearchPage struct {
 	basePage
 	Pagination pagination
 	Results    []*SearchResult
+	// MinDocCoverage is the minimum percentage of documented exported
+	// identifiers requested with the "coverage" query parameter, or 0.
+	MinDocCoverage int
 }
 
 // SearchResult contains data needed to display a single search result.
@@ -42,13 +46,23 @@ type SearchResult struct {
 	CommitTime     string
 	NumImportedBy  uint64
 	Approximate    bool
+	Deprecated     bool
 }
 
 // fetchSearchPage fetches data matching the search query from the database and
-// returns a SearchPage.
-func fetchSearchPage(ctx context.Context, db *postgres.DB, query string, pageParams paginationParams) (*SearchPage, error) {
+// returns a SearchPage. If minCoverage is positive, only packages with at least
+// that percentage of their exported identifiers documented are returned.
+func fetchSearchPage(ctx context.Context, db *postgres.DB, query string, pageParams paginationParams, minCoverage int) (*SearchPage, error) {
 	maxResultCount := maxSearchOffset + pageParams.limit
-	dbresults, err := db.Search(ctx, query, pageParams.limit, pageParams.offset(), maxResultCount)
+	var (
+		dbresults []*internal.SearchResult
+		err       error
+	)
+	if minCoverage > 0 {
+		dbresults, err = db.SearchWithMinDocCoverage(ctx, query, pageParams.limit, pageParams.offset(), maxResultCount, float64(minCoverage)/100)
+	} else {
+		dbresults, err = db.Search(ctx, query, pageParams.limit, pageParams.offset(), maxResultCount)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +78,7 @@ func fetchSearchPage(ctx context.Context, db *postgres.DB, query string, pagePar
 			Licenses:       r.Licenses,
 			CommitTime:     elapsedTime(r.CommitTime),
 			NumImportedBy:  r.NumImportedBy,
+			Deprecated:     r.Deprecated,
 		})
 	}
 
@@ -84,8 +99,9 @@ func fetchSearchPage(ctx context.Context, db *postgres.DB, query string, pagePar
 	pgs := newPagination(pageParams, len(results), numResults)
 	pgs.Approximate = approximate
 	return &SearchPage{
-		Results:    results,
-		Pagination: pgs,
+		Results:        results,
+		Pagination:     pgs,
+		MinDocCoverage: minCoverage,
 	}, nil
 }
 
@@ -166,13 +182,24 @@ func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request, ds internal
 		}
 	}
 
+	minCoverage, err := searchMinDocCoverage(r)
+	if err != nil {
+		return &serverError{
+			status: http.StatusBadRequest,
+			epage: &errorPage{
+				messageTemplate: template.MakeTrustedTemplate(
+					`<h3 class="Error-message">Coverage must be a percentage between 0 and 100.</h3>`),
+			},
+		}
+	}
+
 	if path := searchRequestRedirectPath(ctx, ds, query); path != "" {
 		http.Redirect(w, r, path, http.StatusFound)
 		return nil
 	}
-	page, err := fetchSearchPage(ctx, db, query, pageParams)
+	page, err := fetchSearchPage(ctx, db, query, pageParams, minCoverage)
 	if err != nil {
-		return fmt.Errorf("fetchSearchPage(ctx, db, %q): %v", query, err)
+		return fmt.Errorf("fetchSearchPage(ctx, db, %q, %d): %v", query, minCoverage, err)
 	}
 	page.basePage = s.newBasePage(r, fmt.Sprintf("%s - Search Results", query))
 	s.servePage(ctx, w, "search.tmpl", page)
@@ -209,6 +236,24 @@ func searchQuery(r *http.Request) string {
 	return strings.TrimSpace(r.FormValue("q"))
 }
 
+// searchMinDocCoverage extracts the minimum documentation coverage percentage
+// from the "coverage" query parameter of the request. It returns 0 if the
+// parameter is absent.
+func searchMinDocCoverage(r *http.Request) (int, error) {
+	v := strings.TrimSuffix(strings.TrimSpace(r.FormValue("coverage")), "%")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > 100 {
+		return 0, fmt.Errorf("coverage %d out of range", n)
+	}
+	return n, nil
+}
+
 // elapsedTime takes a date and returns returns human-readable,
 // relative timestamps based on the following rules:
 // (1) 'X hours ago' when X < 6
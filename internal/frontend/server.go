@@ -8,6 +8,7 @@ package frontend
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -26,7 +27,9 @@ import (
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/queue"
+	"golang.org/x/time/rate"
 )
 
 // Server can be installed to serve the go discovery frontend.
@@ -46,6 +49,17 @@ type Server struct {
 	appVersionLabel      string
 	googleTagManagerID   string
 	serveStats           bool
+	// sourceZips holds module zips for the source browser. It is nil if
+	// there is no module proxy to download them from.
+	sourceZips *zipCache
+	// showUnexportedDocs reports whether documentation for unexported
+	// identifiers is available for a module. It is nil if it never is.
+	showUnexportedDocs func(modulePath string) bool
+	// siteURL is the canonical URL of the site, without a trailing slash.
+	siteURL string
+	// enqueueLimiter limits the rate at which requests for reports can
+	// enqueue unprocessed modules for processing.
+	enqueueLimiter *rate.Limiter
 
 	mu        sync.Mutex // Protects all fields below
 	templates map[string]*template.Template
@@ -65,8 +79,30 @@ type ServerConfig struct {
 	AppVersionLabel      string
 	GoogleTagManagerID   string
 	ServeStats           bool
+	// ProxyClient, if non-nil, is used to download module source for the
+	// source browser.
+	ProxyClient *proxy.Client
+	// SourceZipCacheDir, if non-empty, is a directory in which module zips
+	// downloaded for the source browser are cached.
+	SourceZipCacheDir string
+	// ShowUnexportedDocs, if non-nil, reports whether documentation for
+	// unexported identifiers is available for the module with the given
+	// path. See config.Config.ShowUnexportedDocs.
+	ShowUnexportedDocs func(modulePath string) bool
+	// SiteURL is the canonical URL of the site, like "https://pkg.go.dev".
+	// See config.Config.SiteURL.
+	SiteURL string
 }
 
+const (
+	// enqueueRate is the number of unprocessed modules per second that
+	// requests for reports can enqueue for processing, across all requests.
+	enqueueRate = rate.Limit(1)
+	// enqueueBurst is the largest number of unprocessed modules that can be
+	// enqueued at once.
+	enqueueBurst = 2 * maxEnqueuePerRequest
+)
+
 // NewServer creates a new Server for the given database and template directory.
 func NewServer(scfg ServerConfig) (_ *Server, err error) {
 	defer derrors.Wrap(&err, "NewServer(...)")
@@ -90,6 +126,12 @@ func NewServer(scfg ServerConfig) (_ *Server, err error) {
 		appVersionLabel:      scfg.AppVersionLabel,
 		googleTagManagerID:   scfg.GoogleTagManagerID,
 		serveStats:           scfg.ServeStats,
+		showUnexportedDocs:   scfg.ShowUnexportedDocs,
+		siteURL:              strings.TrimSuffix(scfg.SiteURL, "/"),
+		enqueueLimiter:       rate.NewLimiter(enqueueRate, enqueueBurst),
+	}
+	if scfg.ProxyClient != nil {
+		s.sourceZips = newZipCache(scfg.ProxyClient, scfg.SourceZipCacheDir)
 	}
 	errorPageBytes, err := s.renderErrorPage(context.Background(), http.StatusInternalServerError, "error.tmpl", nil)
 	if err != nil {
@@ -99,6 +141,19 @@ func NewServer(scfg ServerConfig) (_ *Server, err error) {
 	return s, nil
 }
 
+// writeJSON writes v to w as JSON.
+func writeJSON(ctx context.Context, w http.ResponseWriter, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(body); err != nil {
+		log.Errorf(ctx, "Error writing JSON: %v", err)
+	}
+	return nil
+}
+
 // Install registers server routes using the given handler registration func.
 // authValues is the set of values that can be set on authHeader to bypass the
 // cache.
@@ -135,8 +190,20 @@ func (s *Server) Install(handle func(string, http.Handler), redisClient *redis.C
 	handle("/search", searchHandler)
 	handle("/search-help", s.staticPageHandler("search_help.tmpl", "Search Help"))
 	handle("/license-policy", s.licensePolicyHandler())
+	handle("/license-report/", s.errorHandler(s.serveLicenseReport))
+	handle("/sbom/", s.errorHandler(s.serveSBOM))
+	handle("/mod-graph/", s.errorHandler(s.serveModuleGraph))
+	handle("/mod-check", s.errorHandler(s.serveModCheck))
+	handle("/mod-check.json", s.errorHandler(s.serveModCheck))
+	handle("/vuln/", s.errorHandler(s.serveVuln))
+	handle("/vulns/", s.errorHandler(s.serveUnitVulns))
+	handle("/license-changes.atom", s.errorHandler(s.serveLicenseChanges))
+	handle("/license-changes.json", s.errorHandler(s.serveLicenseChanges))
+	handle("/license-changes/", s.errorHandler(s.serveLicenseChanges))
 	handle("/about", http.RedirectHandler("https://go.dev/about", http.StatusFound))
 	handle("/badge/", http.HandlerFunc(s.badgeHandler))
+	handle("/coverage/", s.errorHandler(s.serveDocCoverage))
+	handle("/src/", s.errorHandler(s.serveSource))
 	handle("/C", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Package "C" is a special case: redirect to /cmd/cgo.
 		// (This is what golang.org/C does.)
@@ -266,15 +333,25 @@ type licensePolicyPage struct {
 	basePage
 	LicenseFileNames []string
 	LicenseTypes     []licenses.AcceptedLicenseInfo
+	// DeniedLicenseTypes, ReviewLicenseTypes and ModuleOverrides are from the
+	// license policy.
+	DeniedLicenseTypes []string
+	ReviewLicenseTypes []string
+	ModuleOverrides    []*licenses.ModuleOverride
 }
 
 func (s *Server) licensePolicyHandler() http.HandlerFunc {
-	lics := licenses.AcceptedLicenses()
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The policy is read on each request, since it may be reloaded.
+		lics := licenses.AcceptedLicenses()
+		policy := licenses.CurrentPolicy()
 		page := licensePolicyPage{
-			basePage:         s.newBasePage(r, "Licenses"),
-			LicenseFileNames: licenses.FileNames,
-			LicenseTypes:     lics,
+			basePage:           s.newBasePage(r, "Licenses"),
+			LicenseFileNames:   licenses.FileNames,
+			LicenseTypes:       lics,
+			DeniedLicenseTypes: policy.Deny,
+			ReviewLicenseTypes: policy.Review,
+			ModuleOverrides:    policy.Modules,
 		}
 		s.servePage(r.Context(), w, "license_policy.tmpl", page)
 	})
@@ -493,6 +570,7 @@ var templateFuncs = template.FuncMap{
 	"commaseparate": func(s []string) string {
 		return strings.Join(s, ", ")
 	},
+	"sourceURL": dochtml.SourceURL,
 }
 
 // parsePageTemplates parses html templates contained in the given base
@@ -510,9 +588,14 @@ func parsePageTemplates(base template.TrustedSource) (map[string]*template.Templ
 		{tsc("fetch.tmpl")},
 		{tsc("index.tmpl")},
 		{tsc("license_policy.tmpl")},
+		{tsc("license_report.tmpl")},
+		{tsc("module_graph.tmpl")},
+		{tsc("mod_check.tmpl")},
 		{tsc("search.tmpl")},
 		{tsc("search_help.tmpl")},
+		{tsc("source.tmpl")},
 		{tsc("unit_details.tmpl"), tsc("unit.tmpl")},
+		{tsc("unit_gomod.tmpl"), tsc("unit.tmpl")},
 		{tsc("unit_importedby.tmpl"), tsc("unit.tmpl")},
 		{tsc("unit_imports.tmpl"), tsc("unit.tmpl")},
 		{tsc("unit_licenses.tmpl"), tsc("unit.tmpl")},
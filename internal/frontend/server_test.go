@@ -1245,14 +1245,20 @@ func TestCheckTemplates(t *testing.T) {
 		{"fetch", nil, errorPage{}},
 		{"index", nil, basePage{}},
 		{"license_policy", nil, licensePolicyPage{}},
+		{"mod_check", nil, ModCheck{}},
+		{"module_graph", nil, ModuleGraph{}},
 		{"search", nil, SearchPage{}},
 		{"search_help", nil, basePage{}},
+		{"source", nil, SourcePage{}},
 		{"unit_details", nil, UnitPage{}},
 		{
 			"unit_details",
 			[]string{"unit_outline", "legacy_unit_outline", "unit_readme", "unit_doc", "unit_files", "unit_directories"},
 			MainDetails{},
 		},
+		{"unit_details", []string{"unit_coverage"}, DocCoverage{}},
+		{"unit_gomod", nil, UnitPage{}},
+		{"unit_gomod", []string{"gomod"}, GoModDetails{}},
 		{"unit_importedby", nil, UnitPage{}},
 		{"unit_importedby", []string{"importedby"}, ImportedByDetails{}},
 		{"unit_imports", nil, UnitPage{}},
@@ -0,0 +1,344 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/safehtml/template"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// maxSourceFileSize is the largest file that the source browser will display
+// or search.
+const maxSourceFileSize = 1024 * 1024
+
+// SourcePage contains the data for the source browser page, which displays a
+// file or lists a directory of a module version.
+type SourcePage struct {
+	basePage
+
+	ModulePath     string
+	Version        string // resolved version
+	DisplayVersion string
+
+	// Path is the path of the file or directory, relative to the module root.
+	Path string
+
+	// Breadcrumb links to the directories containing the file or directory,
+	// starting with the module root.
+	Breadcrumb []link
+
+	// DocURL is the URL of the documentation of the package containing the
+	// file or directory.
+	DocURL string
+
+	// IsDir reports whether the page lists a directory.
+	IsDir   bool
+	Entries []*SourceEntry
+
+	// Lines holds the contents of a file. It is empty if the file can't be
+	// displayed, in which case Message says why.
+	Lines   []*SourceLine
+	Message string
+
+	// SearchURL is the URL to which code search queries for the directory
+	// are submitted.
+	SearchURL string
+
+	// Search holds the results of a code search over the files under Path.
+	// If it is non-nil, the page displays the results instead of the file or
+	// directory.
+	Search *CodeSearch
+}
+
+// SourceEntry is a file or subdirectory in a directory listing.
+type SourceEntry struct {
+	Name  string
+	URL   string
+	IsDir bool
+}
+
+// serveSource serves the source browser, at
+// /src/<module>@<version>/<file-or-directory>. Source files are read from the
+// module zip, which is downloaded from the module proxy.
+func (s *Server) serveSource(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveSource(%q)", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		return &serverError{status: http.StatusMethodNotAllowed}
+	}
+	if s.sourceZips == nil {
+		// There is no module proxy to read source files from.
+		return proxydatasourceNotSupportedErr()
+	}
+	ctx := r.Context()
+	var cs *CodeSearch
+	if q := strings.TrimSpace(r.FormValue("q")); q != "" {
+		cs = newCodeSearch(q, r.FormValue("re") != "", r.FormValue("i") != "")
+	}
+	modulePath, requestedVersion, filePath, err := parseSourceURLPath(r.URL.Path)
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	um, err := ds.GetUnitMeta(ctx, modulePath, modulePath, requestedVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	if !um.IsRedistributable {
+		return &serverError{
+			status: http.StatusNotFound,
+			epage: &errorPage{
+				messageTemplate: template.MakeTrustedTemplate(
+					`<h3 class="Error-message">Source is not displayed due to license restrictions.</h3>`),
+			},
+		}
+	}
+	zr, err := s.sourceZips.get(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) || errors.Is(err, derrors.ModuleTooLarge) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	page, err := sourcePageFromZip(ctx, zr, um.ModulePath, um.Version, filePath, cs)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	title := page.Path
+	if title == "" {
+		title = um.ModulePath
+	}
+	if cs != nil {
+		title = fmt.Sprintf("%s - Search in %s", cs.Query, title)
+	} else {
+		title += " - Source"
+	}
+	page.basePage = s.newBasePage(r, title)
+	s.servePage(ctx, w, "source.tmpl", page)
+	return nil
+}
+
+// parseSourceURLPath parses a source browser URL path of the form
+// /src/<module>@<version>/<file-or-directory>.
+func parseSourceURLPath(urlPath string) (modulePath, version, filePath string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(urlPath, "/src/"), "@", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", "", fmt.Errorf("%q: missing module path or version", urlPath)
+	}
+	modulePath = strings.TrimSuffix(parts[0], "/")
+	version = parts[1]
+	if i := strings.IndexByte(version, '/'); i >= 0 {
+		version, filePath = version[:i], strings.Trim(version[i+1:], "/")
+	}
+	if version == "" {
+		return "", "", "", fmt.Errorf("%q: missing version", urlPath)
+	}
+	if modulePath != stdlib.ModulePath && !isValidPath(modulePath) {
+		return "", "", "", fmt.Errorf("%q: invalid module path", urlPath)
+	}
+	return modulePath, version, path.Clean("/" + filePath)[1:], nil
+}
+
+// sourcePageFromZip returns a SourcePage for the file or directory at
+// filePath in zr, the zip of the given module version. If cs is non-nil, the
+// page holds the results of running the code search cs over the files under
+// filePath.
+func sourcePageFromZip(ctx context.Context, zr *zip.Reader, modulePath, version, filePath string, cs *CodeSearch) (_ *SourcePage, err error) {
+	defer derrors.Wrap(&err, "sourcePageFromZip(%q, %q, %q)", modulePath, version, filePath)
+
+	prefix := modulePath + "@" + version + "/"
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, prefix) && !strings.HasSuffix(f.Name, "/") {
+			files[f.Name[len(prefix):]] = f
+		}
+	}
+	page := &SourcePage{
+		ModulePath:     modulePath,
+		Version:        version,
+		DisplayVersion: displayVersion(version, modulePath),
+		Path:           filePath,
+		Breadcrumb:     sourceBreadcrumb(modulePath, version, filePath),
+	}
+	dir := filePath
+	f, isFile := files[filePath]
+	if isFile {
+		dir = path.Dir(filePath)
+		if dir == "." {
+			dir = ""
+		}
+	} else {
+		page.IsDir = true
+		page.Entries = sourceEntries(files, modulePath, version, dir)
+		if len(page.Entries) == 0 {
+			return nil, derrors.NotFound
+		}
+	}
+	page.DocURL = sourceDocURL(modulePath, version, dir)
+	page.SearchURL = godoc.HostedSourceURL(modulePath, version, filePath)
+	switch {
+	case cs != nil:
+		page.Search = cs
+		if err := searchSourceFiles(ctx, cs, files, modulePath, version, filePath); err != nil {
+			return nil, err
+		}
+	case isFile:
+		if err := addSourceFile(page, files, f, dir); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// addSourceFile adds the contents of f, in directory dir of the module, to
+// page.
+func addSourceFile(page *SourcePage, files map[string]*zip.File, f *zip.File, dir string) error {
+	if f.UncompressedSize64 > maxSourceFileSize {
+		page.Message = "This file is too large to display."
+		return nil
+	}
+	contents, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(contents) {
+		page.Message = "This file is not displayed because it is not text."
+		return nil
+	}
+	if path.Ext(page.Path) != ".go" {
+		page.Lines = plainSourceLines(contents)
+		return nil
+	}
+
+	// Parse the other Go files of the package, so that uses of identifiers
+	// declared in them can be linked.
+	fset := token.NewFileSet()
+	pkgFiles := map[string]*ast.File{}
+	file, err := parser.ParseFile(fset, path.Base(page.Path), contents, parser.ParseComments)
+	if err != nil {
+		// Display the file without links.
+		page.Lines = goSourceLines(contents, nil)
+		return nil
+	}
+	pkgFiles[path.Base(page.Path)] = file
+	for name, zf := range files {
+		if d := path.Dir(name); d != dir && !(d == "." && dir == "") {
+			continue
+		}
+		base := path.Base(name)
+		if base == path.Base(page.Path) || path.Ext(base) != ".go" || strings.HasSuffix(base, "_test.go") || zf.UncompressedSize64 > maxSourceFileSize {
+			continue
+		}
+		src, err := readZipFile(zf)
+		if err != nil {
+			return err
+		}
+		pf, err := parser.ParseFile(fset, base, src, 0)
+		if err != nil || pf.Name.Name != file.Name.Name {
+			continue
+		}
+		pkgFiles[base] = pf
+	}
+	lineURL := func(filename string, line int) string {
+		return fmt.Sprintf("%s#L%d", godoc.HostedSourceURL(page.ModulePath, page.Version, path.Join(dir, filename)), line)
+	}
+	links := goSourceLinks(fset, pkgFiles, file, sourceDocURL(page.ModulePath, page.Version, dir), lineURL)
+	page.Lines = goSourceLines(contents, links)
+	return nil
+}
+
+// sourceDocURL returns the URL of the documentation for the directory dir of
+// the given module version.
+func sourceDocURL(modulePath, version, dir string) string {
+	pkgPath := path.Join(modulePath, dir)
+	if modulePath == stdlib.ModulePath {
+		pkgPath = dir
+	}
+	return constructUnitURL(pkgPath, modulePath, linkVersion(version, modulePath))
+}
+
+// sourceEntries returns the files and subdirectories of dir in files, sorted
+// with directories first.
+func sourceEntries(files map[string]*zip.File, modulePath, version, dir string) []*SourceEntry {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	seen := map[string]bool{}
+	var entries []*SourceEntry
+	for name := range files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		e := &SourceEntry{Name: rest}
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			e.Name = rest[:i]
+			e.IsDir = true
+		}
+		if seen[e.Name] {
+			continue
+		}
+		seen[e.Name] = true
+		e.URL = godoc.HostedSourceURL(modulePath, version, path.Join(dir, e.Name))
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// sourceBreadcrumb returns links to the module root and each directory
+// containing filePath.
+func sourceBreadcrumb(modulePath, version, filePath string) []link {
+	links := []link{{Href: godoc.HostedSourceURL(modulePath, version, ""), Body: modulePath + "@" + version}}
+	if filePath == "" {
+		return links
+	}
+	elems := strings.Split(filePath, "/")
+	for i, e := range elems {
+		links = append(links, link{
+			Href: godoc.HostedSourceURL(modulePath, version, strings.Join(elems[:i+1], "/")),
+			Body: e,
+		})
+	}
+	return links
+}
+
+func readZipFile(f *zip.File) (_ []byte, err error) {
+	defer derrors.Wrap(&err, "readZipFile(%q)", f.Name)
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
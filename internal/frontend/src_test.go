@@ -0,0 +1,150 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+func TestParseSourceURLPath(t *testing.T) {
+	for _, test := range []struct {
+		urlPath                           string
+		wantModule, wantVersion, wantFile string
+		wantErr                           bool
+	}{
+		{"/src/example.com/m@v1.2.3", "example.com/m", "v1.2.3", "", false},
+		{"/src/example.com/m@v1.2.3/", "example.com/m", "v1.2.3", "", false},
+		{"/src/example.com/m@v1.2.3/a/b.go", "example.com/m", "v1.2.3", "a/b.go", false},
+		{"/src/example.com/m@master/../../x.go", "example.com/m", "master", "x.go", false},
+		{"/src/std@go1.15/net/http/server.go", "std", "go1.15", "net/http/server.go", false},
+		{"/src/example.com/m", "", "", "", true},
+		{"/src/example.com/m@/a.go", "", "", "", true},
+		{"/src/@v1.0.0/a.go", "", "", "", true},
+	} {
+		t.Run(test.urlPath, func(t *testing.T) {
+			gotModule, gotVersion, gotFile, err := parseSourceURLPath(test.urlPath)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+			if gotModule != test.wantModule || gotVersion != test.wantVersion || gotFile != test.wantFile {
+				t.Errorf("got (%q, %q, %q), want (%q, %q, %q)",
+					gotModule, gotVersion, gotFile, test.wantModule, test.wantVersion, test.wantFile)
+			}
+		})
+	}
+}
+
+func TestSourcePageFromZip(t *testing.T) {
+	const (
+		modulePath = "example.com/m"
+		version    = "v1.0.0"
+	)
+	ctx := context.Background()
+	zr := newTestZip(t, map[string]string{
+		"example.com/m@v1.0.0/go.mod":    "module example.com/m\n",
+		"example.com/m@v1.0.0/a/a.go":    "package a\n\nfunc A() { b() }\n",
+		"example.com/m@v1.0.0/a/b.go":    "package a\n\nfunc b() {}\n",
+		"example.com/m@v1.0.0/a/c/c.go":  "package c\n",
+		"example.com/m@v1.0.0/bin/image": "\xff\xfe",
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		got, err := sourcePageFromZip(ctx, zr, modulePath, version, "a", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.IsDir {
+			t.Error("IsDir = false, want true")
+		}
+		want := []*SourceEntry{
+			{Name: "c", URL: "/src/example.com/m@v1.0.0/a/c", IsDir: true},
+			{Name: "a.go", URL: "/src/example.com/m@v1.0.0/a/a.go"},
+			{Name: "b.go", URL: "/src/example.com/m@v1.0.0/a/b.go"},
+		}
+		if diff := cmp.Diff(want, got.Entries); diff != "" {
+			t.Errorf("entries mismatch (-want +got):\n%s", diff)
+		}
+		wantCrumbs := []link{
+			{Href: "/src/example.com/m@v1.0.0", Body: "example.com/m@v1.0.0"},
+			{Href: "/src/example.com/m@v1.0.0/a", Body: "a"},
+		}
+		if diff := cmp.Diff(wantCrumbs, got.Breadcrumb); diff != "" {
+			t.Errorf("breadcrumb mismatch (-want +got):\n%s", diff)
+		}
+		if want := "/example.com/m@v1.0.0/a"; got.DocURL != want {
+			t.Errorf("DocURL = %q, want %q", got.DocURL, want)
+		}
+	})
+
+	t.Run("go file", func(t *testing.T) {
+		got, err := sourcePageFromZip(ctx, zr, modulePath, version, "a/a.go", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.IsDir || len(got.Lines) != 3 {
+			t.Fatalf("got IsDir = %t, %d lines; want false, 3", got.IsDir, len(got.Lines))
+		}
+		hrefs := map[string]string{}
+		for _, tok := range got.Lines[2].Tokens {
+			if tok.Href != "" {
+				hrefs[tok.Text] = tok.Href
+			}
+		}
+		want := map[string]string{
+			"A": "/example.com/m@v1.0.0/a#A",
+			"b": "/src/example.com/m@v1.0.0/a/b.go#L3",
+		}
+		if diff := cmp.Diff(want, hrefs); diff != "" {
+			t.Errorf("links mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("binary file", func(t *testing.T) {
+		got, err := sourcePageFromZip(ctx, zr, modulePath, version, "bin/image", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Lines != nil || got.Message == "" {
+			t.Errorf("got %d lines, message %q; want no lines and a message", len(got.Lines), got.Message)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := sourcePageFromZip(ctx, zr, modulePath, version, "nope", nil)
+		if !errors.Is(err, derrors.NotFound) {
+			t.Errorf("got %v, want NotFound", err)
+		}
+	})
+}
+
+func newTestZip(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
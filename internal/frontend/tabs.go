@@ -39,6 +39,7 @@ const (
 	tabImports    = "imports"
 	tabImportedBy = "importedby"
 	tabLicenses   = "licenses"
+	tabGoMod      = "gomod"
 )
 
 var (
@@ -64,6 +65,10 @@ var (
 			Name:         tabLicenses,
 			TemplateName: "unit_licenses.tmpl",
 		},
+		{
+			Name:         tabGoMod,
+			TemplateName: "unit_gomod.tmpl",
+		},
 	}
 	unitTabLookup = make(map[string]TabSettings, len(unitTabs))
 )
@@ -75,13 +80,16 @@ func init() {
 }
 
 // fetchDetailsForPackage returns tab details by delegating to the correct detail
-// handler.
-func fetchDetailsForUnit(ctx context.Context, r *http.Request, tab string, ds internal.DataSource, um *internal.UnitMeta) (_ interface{}, err error) {
+// handler. canShowUnexported reports whether documentation for unexported
+// identifiers is available for the unit, and hostedSource whether this site
+// serves a source browser.
+func fetchDetailsForUnit(ctx context.Context, r *http.Request, tab string, ds internal.DataSource, um *internal.UnitMeta, canShowUnexported, hostedSource bool) (_ interface{}, err error) {
 	defer derrors.Wrap(&err, "fetchDetailsForUnit(r, %q, ds, um=%q,%q,%q)", tab, um.Path, um.ModulePath, um.Version)
 	switch tab {
 	case tabMain:
 		_, expandReadme := r.URL.Query()["readme"]
-		return fetchMainDetails(ctx, ds, um, expandReadme)
+		showUnexported := r.FormValue("m") == "all"
+		return fetchMainDetails(ctx, ds, um, expandReadme, showUnexported, canShowUnexported, hostedSource)
 	case tabVersions:
 		return fetchVersionsDetails(ctx, ds, um.Path, um.ModulePath)
 	case tabImports:
@@ -90,6 +98,8 @@ func fetchDetailsForUnit(ctx context.Context, r *http.Request, tab string, ds in
 		return fetchImportedByDetails(ctx, ds, um.Path, um.ModulePath)
 	case tabLicenses:
 		return fetchLicensesDetails(ctx, ds, um)
+	case tabGoMod:
+		return fetchGoModDetails(ctx, ds, um)
 	}
 	return nil, fmt.Errorf("BUG: unable to fetch details: unknown tab %q", tab)
 }
@@ -69,6 +69,9 @@ type UnitPage struct {
 
 	// Details contains data specific to the type of page being rendered.
 	Details interface{}
+
+	// Vulns are the known vulnerabilities that affect the unit.
+	Vulns []*Vuln
 }
 
 // serveUnitPage serves a unit page for a path using the paths,
@@ -138,11 +141,16 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 		PageLabels:       pageLabels(um),
 		PageType:         pageType(um),
 	}
-	d, err := fetchDetailsForUnit(ctx, r, tab, ds, um)
+	canShowUnexported := s.showUnexportedDocs != nil && s.showUnexportedDocs(um.ModulePath)
+	d, err := fetchDetailsForUnit(ctx, r, tab, ds, um, canShowUnexported, s.sourceZips != nil)
 	if err != nil {
 		return err
 	}
 	page.Details = d
+	page.Vulns, err = vulnsForUnit(ctx, ds, um.ModulePath, um.Version, um.Path)
+	if err != nil {
+		return err
+	}
 	main, ok := d.(*MainDetails)
 	if ok {
 		page.MetaDescription = metaDescription(main.ImportedByCount)
@@ -174,6 +182,9 @@ func isValidTabForUnit(tab string, um *internal.UnitMeta) bool {
 	if !um.IsPackage() && (tab == tabImports || tab == tabImportedBy) {
 		return false
 	}
+	if tab == tabGoMod && (!um.IsModule() || !um.HasGoMod || um.ModulePath == stdlib.ModulePath) {
+		return false
+	}
 	return true
 }
 
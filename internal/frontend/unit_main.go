@@ -89,6 +89,10 @@ type MainDetails struct {
 	// SourceURL is the URL to the source of the package.
 	SourceURL string
 
+	// SourceSearchURL is the URL to which queries that search the source of
+	// the module are submitted.
+	SourceSearchURL string
+
 	// ExpandReadme is holds the expandable readme state.
 	ExpandReadme bool
 
@@ -100,6 +104,19 @@ type MainDetails struct {
 
 	// IsStableVersion is true if the major version is v1 or greater.
 	IsStableVersion bool
+
+	// CanShowUnexported reports whether documentation for unexported
+	// identifiers is available for this unit; see
+	// config.Config.ShowUnexportedDocs.
+	CanShowUnexported bool
+
+	// ShowUnexported reports whether DocBody includes documentation for
+	// unexported identifiers. It is set by the "m=all" query parameter.
+	ShowUnexported bool
+
+	// DocCoverage is the documentation coverage of the unit. It is nil if
+	// coverage is not available.
+	DocCoverage *DocCoverage
 }
 
 // File is a source file for a package.
@@ -118,12 +135,13 @@ type NestedModule struct {
 // Subdirectory is a package in a subdirectory relative to the path of a given
 // unit. This content is used in the Directories section of the unit page.
 type Subdirectory struct {
-	Suffix   string
-	URL      string
-	Synopsis string
+	Suffix     string
+	URL        string
+	Synopsis   string
+	Deprecated bool
 }
 
-func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta, expandReadme bool) (_ *MainDetails, err error) {
+func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta, expandReadme, showUnexported, canShowUnexported, hostedSource bool) (_ *MainDetails, err error) {
 	defer middleware.ElapsedStat(ctx, "fetchMainDetails")()
 
 	unit, err := ds.GetUnit(ctx, um, internal.WithMain)
@@ -146,6 +164,7 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 	if err != nil {
 		return nil, err
 	}
+	showUnexported = showUnexported && canShowUnexported
 	var (
 		docParts           = &dochtml.Parts{}
 		docLinks, modLinks []link
@@ -166,7 +185,8 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 			}
 			return nil, err
 		}
-		docParts, err = getHTML(ctx, unit, docPkg)
+		docPkg.AllDecls = showUnexported
+		docParts, err = getHTML(ctx, unit, docPkg, hostedSource)
 		// If err  is ErrTooLarge, then docBody will have an appropriate message.
 		if err != nil && !errors.Is(err, dochtml.ErrTooLarge) {
 			return nil, err
@@ -175,7 +195,7 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 			docLinks = append(docLinks, link{Href: l.Href, Body: l.Text})
 		}
 		end = middleware.ElapsedStat(ctx, "sourceFiles")
-		files = sourceFiles(unit, docPkg)
+		files = sourceFiles(unit, docPkg, hostedSource)
 		end()
 	}
 	// If the unit is not a module, fetch the module readme to extract its
@@ -218,17 +238,41 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 		DocSynopsis:       synopsis,
 		SourceFiles:       files,
 		RepositoryURL:     um.SourceInfo.RepoURL(),
-		SourceURL:         um.SourceInfo.DirectoryURL(internal.Suffix(um.Path, um.ModulePath)),
+		SourceURL:         sourceDirectoryURL(um, hostedSource),
+		SourceSearchURL:   sourceSearchURL(um, hostedSource),
 		MobileOutline:     docParts.MobileOutline,
 		NumImports:        unit.NumImports,
 		ImportedByCount:   importedByCount,
 		IsPackage:         unit.IsPackage(),
-		ModFileURL:        um.SourceInfo.ModuleURL() + "/go.mod",
+		ModFileURL:        um.SourceInfo.FileURL("go.mod"),
 		IsTaggedVersion:   isTaggedVersion,
 		IsStableVersion:   semver.Major(um.Version) != "v0",
+		CanShowUnexported: canShowUnexported && unit.IsPackage(),
+		ShowUnexported:    showUnexported,
+		DocCoverage:       unitDocCoverage(unit),
 	}, nil
 }
 
+// sourceDirectoryURL returns the URL of the source of the unit's directory. If
+// the module's source is not on a known code host and hostedSource is true, it
+// is the URL of the directory in the source browser on this site.
+func sourceDirectoryURL(um *internal.UnitMeta, hostedSource bool) string {
+	dir := internal.Suffix(um.Path, um.ModulePath)
+	if um.SourceInfo == nil && hostedSource {
+		return godoc.HostedSourceURL(um.ModulePath, um.Version, dir)
+	}
+	return um.SourceInfo.DirectoryURL(dir)
+}
+
+// sourceSearchURL returns the URL for searching the source of the unit's
+// module, or the empty string if this site does not serve a source browser.
+func sourceSearchURL(um *internal.UnitMeta, hostedSource bool) string {
+	if !hostedSource {
+		return ""
+	}
+	return godoc.HostedSourceURL(um.ModulePath, um.Version, "")
+}
+
 // moduleInfo extracts module info from a unit. This is a shim
 // for functions ReadmeHTML and createDirectory that will be removed
 // when we complete the switch to units.
@@ -305,9 +349,10 @@ func getSubdirectories(um *internal.UnitMeta, pkgs []*internal.PackageMeta) []*S
 			continue
 		}
 		sdirs = append(sdirs, &Subdirectory{
-			URL:      constructUnitURL(pm.Path, um.ModulePath, linkVersion(um.Version, um.ModulePath)),
-			Suffix:   internal.Suffix(pm.Path, um.Path),
-			Synopsis: pm.Synopsis,
+			URL:        constructUnitURL(pm.Path, um.ModulePath, linkVersion(um.Version, um.ModulePath)),
+			Suffix:     internal.Suffix(pm.Path, um.Path),
+			Synopsis:   pm.Synopsis,
+			Deprecated: pm.Deprecated,
 		})
 	}
 	sort.Slice(sdirs, func(i, j int) bool { return sdirs[i].Suffix < sdirs[j].Suffix })
@@ -316,11 +361,11 @@ func getSubdirectories(um *internal.UnitMeta, pkgs []*internal.PackageMeta) []*S
 
 const missingDocReplacement = `<p>Documentation is missing.</p>`
 
-func getHTML(ctx context.Context, u *internal.Unit, docPkg *godoc.Package) (_ *dochtml.Parts, err error) {
+func getHTML(ctx context.Context, u *internal.Unit, docPkg *godoc.Package, hostedSource bool) (_ *dochtml.Parts, err error) {
 	defer derrors.Wrap(&err, "getHTML(%s)", u.Path)
 
 	if len(u.Documentation.Source) > 0 {
-		return renderDocParts(ctx, u, docPkg)
+		return renderDocParts(ctx, u, docPkg, hostedSource)
 	}
 	log.Errorf(ctx, "unit %s (%s@%s) missing documentation source", u.Path, u.ModulePath, u.Version)
 	return &dochtml.Parts{Body: template.MustParseAndExecuteToHTML(missingDocReplacement)}, nil
@@ -197,3 +197,25 @@ func TestApproximateLowerBound(t *testing.T) {
 		}
 	}
 }
+
+func TestSourceURLsWithoutSourceInfo(t *testing.T) {
+	um := &internal.UnitMeta{
+		Path:       "example.com/m/p",
+		ModulePath: "example.com/m",
+		Version:    "v1.0.0",
+	}
+	for _, test := range []struct {
+		hostedSource        bool
+		wantDir, wantSearch string
+	}{
+		{false, "", ""},
+		{true, "/src/example.com/m@v1.0.0/p", "/src/example.com/m@v1.0.0"},
+	} {
+		if got := sourceDirectoryURL(um, test.hostedSource); got != test.wantDir {
+			t.Errorf("sourceDirectoryURL(hostedSource=%t) = %q, want %q", test.hostedSource, got, test.wantDir)
+		}
+		if got := sourceSearchURL(um, test.hostedSource); got != test.wantSearch {
+			t.Errorf("sourceSearchURL(hostedSource=%t) = %q, want %q", test.hostedSource, got, test.wantSearch)
+		}
+	}
+}
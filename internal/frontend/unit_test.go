@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/testing/sample"
 )
 
@@ -81,6 +82,7 @@ func TestIsValidTab(t *testing.T) {
 		tabImports,
 		tabImportedBy,
 		tabLicenses,
+		tabGoMod,
 	}
 	for _, test := range []struct {
 		name     string
@@ -92,6 +94,24 @@ func TestIsValidTab(t *testing.T) {
 			um:       sample.UnitMeta(sample.ModulePath, sample.ModulePath, sample.VersionString, "", true),
 			wantTabs: []string{tabMain, tabVersions, tabLicenses},
 		},
+		{
+			name: "module with go.mod",
+			um: func() *internal.UnitMeta {
+				um := sample.UnitMeta(sample.ModulePath, sample.ModulePath, sample.VersionString, "", true)
+				um.HasGoMod = true
+				return um
+			}(),
+			wantTabs: []string{tabMain, tabVersions, tabLicenses, tabGoMod},
+		},
+		{
+			name: "stdlib",
+			um: func() *internal.UnitMeta {
+				um := sample.UnitMeta(stdlib.ModulePath, stdlib.ModulePath, "v1.15.0", "", true)
+				um.HasGoMod = true
+				return um
+			}(),
+			wantTabs: []string{tabMain, tabVersions, tabLicenses},
+		},
 		{
 			name:     "directory",
 			um:       sample.UnitMeta(sample.ModulePath+"/go", sample.ModulePath, sample.VersionString, "", true),
@@ -30,6 +30,15 @@ func TestExtractURLPathInfo(t *testing.T) {
 				requestedVersion: internal.LatestVersion,
 			},
 		},
+		{
+			name: "stdlib package at master",
+			url:  "/net/http@master",
+			want: &urlPathInfo{
+				modulePath:       stdlib.ModulePath,
+				fullPath:         "net/http",
+				requestedVersion: "master",
+			},
+		},
 		{
 			name: "path at version in nested module",
 			url:  "/github.com/hashicorp/vault/api@v1.0.3",
@@ -218,7 +227,7 @@ func TestIsSupportedVersion(t *testing.T) {
 		{"net/http", "v1.2.3", true}, // isSupportedVersion expects the goTag is already converted to semver
 		{"net/http", "v1.2.3.bad", false},
 		{"net/http", "latest", true},
-		{"net/http", "master", false},
+		{"net/http", "master", true},
 		{"net/http", "main", false},
 	}
 	for _, test := range tests {
@@ -30,6 +30,10 @@ type VersionsDetails struct {
 	// OtherModules is the slice of VersionLists with a different module path
 	// from the current package.
 	OtherModules []*VersionList
+
+	// LicenseChangesURL is the URL of the feed of license changes of the
+	// current module.
+	LicenseChangesURL string
 }
 
 // VersionListKey identifies a version list on the versions tab. We have a
@@ -59,6 +63,20 @@ type VersionSummary struct {
 	// Link to this version, for use in the anchor href.
 	Link    string
 	Version string
+	// Retracted reports whether the version is retracted by the go.mod file
+	// of the latest version of the module.
+	Retracted           bool
+	RetractionRationale string
+	// Prerelease reports whether the version is a prerelease of Go: a beta, a
+	// release candidate or a commit on the master branch. It is only set for
+	// the standard library.
+	Prerelease bool
+	// LicenseChanges describes how the license files of the version changed
+	// since the previous version.
+	LicenseChanges []string
+	// Vulns are the IDs of the known vulnerabilities that affect the version
+	// of the unit.
+	Vulns []string
 }
 
 func fetchVersionsDetails(ctx context.Context, ds internal.DataSource, fullPath, modulePath string) (*VersionsDetails, error) {
@@ -71,18 +89,51 @@ func fetchVersionsDetails(ctx context.Context, ds internal.DataSource, fullPath,
 	if err != nil {
 		return nil, err
 	}
-	linkify := func(mi *internal.ModuleInfo) string {
+	versionPath := func(mi *internal.ModuleInfo) string {
 		// Here we have only version information, but need to construct the full
 		// import path of the package corresponding to this version.
-		var versionPath string
 		if mi.ModulePath == stdlib.ModulePath {
-			versionPath = fullPath
-		} else {
-			versionPath = pathInVersion(internal.V1Path(fullPath, modulePath), mi)
+			return fullPath
+		}
+		return pathInVersion(internal.V1Path(fullPath, modulePath), mi)
+	}
+	linkify := func(mi *internal.ModuleInfo) string {
+		return constructUnitURL(versionPath(mi), mi.ModulePath, linkVersion(mi.Version, mi.ModulePath))
+	}
+	changes, err := licenseChangesForVersions(ctx, db, versions)
+	if err != nil {
+		return nil, err
+	}
+	vulns, err := vulnsForVersions(ctx, db, versions, versionPath)
+	if err != nil {
+		return nil, err
+	}
+	details := buildVersionDetails(modulePath, versions, changes, vulns, linkify)
+	details.LicenseChangesURL = "/license-changes/" + modulePath + ".atom"
+	return details, nil
+}
+
+// licenseChangesForVersions returns descriptions of the license changes of
+// the given module versions, keyed by path@version.
+func licenseChangesForVersions(ctx context.Context, db *postgres.DB, versions []*internal.ModuleInfo) (map[string][]string, error) {
+	changes := map[string][]string{}
+	seen := map[string]bool{}
+	for _, mi := range versions {
+		series := mi.SeriesPath()
+		if seen[series] {
+			continue
+		}
+		seen[series] = true
+		lcs, err := db.GetLicenseChanges(ctx, series)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range lcs {
+			key := c.ModulePath + "@" + c.Version
+			changes[key] = append(changes[key], describeLicenseChange(c))
 		}
-		return constructUnitURL(versionPath, mi.ModulePath, linkVersion(mi.Version, mi.ModulePath))
 	}
-	return buildVersionDetails(modulePath, versions, linkify), nil
+	return changes, nil
 }
 
 // pathInVersion constructs the full import path of the package corresponding
@@ -109,7 +160,10 @@ func pathInVersion(v1Path string, mi *internal.ModuleInfo) string {
 // versions tab, organizing major versions into those that have the same module
 // path as the package version under consideration, and those that don't.  The
 // given versions MUST be sorted first by module path and then by semver.
-func buildVersionDetails(currentModulePath string, modInfos []*internal.ModuleInfo, linkify func(v *internal.ModuleInfo) string) *VersionsDetails {
+// The descriptions of the license changes of each version are looked up in
+// licenseChanges, and the IDs of the vulnerabilities that affect it in vulns,
+// by path@version.
+func buildVersionDetails(currentModulePath string, modInfos []*internal.ModuleInfo, licenseChanges, vulns map[string][]string, linkify func(v *internal.ModuleInfo) string) *VersionsDetails {
 
 	// lists organizes versions by VersionListKey. Note that major version isn't
 	// sufficient as a key: there are packages contained in the same major
@@ -146,9 +200,14 @@ func buildVersionDetails(currentModulePath string, modInfos []*internal.ModuleIn
 		}
 		key := VersionListKey{ModulePath: mi.ModulePath, Major: major}
 		vs := &VersionSummary{
-			Link:       linkify(mi),
-			CommitTime: absoluteTime(mi.CommitTime),
-			Version:    linkVersion(mi.Version, mi.ModulePath),
+			Link:                linkify(mi),
+			CommitTime:          absoluteTime(mi.CommitTime),
+			Version:             linkVersion(mi.Version, mi.ModulePath),
+			Retracted:           mi.Retracted,
+			RetractionRationale: mi.RetractionRationale,
+			Prerelease:          mi.ModulePath == stdlib.ModulePath && stdlib.IsPrerelease(mi.Version),
+			LicenseChanges:      licenseChanges[mi.ModulePath+"@"+mi.Version],
+			Vulns:               vulns[mi.ModulePath+"@"+mi.Version],
 		}
 		if _, ok := lists[key]; !ok {
 			seenLists = append(seenLists, key)
@@ -250,7 +309,7 @@ func pseudoVersionRev(v string) string {
 
 // displayVersion returns the version string, formatted for display.
 func displayVersion(v string, modulePath string) string {
-	if modulePath == stdlib.ModulePath {
+	if modulePath == stdlib.ModulePath && !version.IsPseudo(v) {
 		return goTagForVersion(v)
 	}
 	return formatVersion(v)
@@ -262,7 +321,8 @@ func displayVersion(v string, modulePath string) string {
 // other version strings.
 func linkVersion(v string, modulePath string) string {
 	if modulePath == stdlib.ModulePath {
-		if strings.HasPrefix(v, "go") {
+		// Go tags, "master" and pseudo-versions of master are used as is.
+		if strings.HasPrefix(v, "go") || v == stdlib.MasterVersion || version.IsPseudo(v) {
 			return v
 		}
 		return goTagForVersion(v)
@@ -109,6 +109,22 @@ func TestFetchPackageVersionsDetails(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "want stdlib prereleases labeled",
+			pkg:  nethttpPkg,
+			modules: []*internal.Module{
+				sampleModule("std", "v1.12.5", version.TypeRelease, nethttpPkg),
+				sampleModule("std", "v1.13.0-beta.1", version.TypePrerelease, nethttpPkg),
+				sampleModule("std", "v0.0.0-20201015141213-0123456789ab", version.TypePseudo, nethttpPkg),
+			},
+			wantDetails: func() *VersionsDetails {
+				master := makeList("net/http", "std", "master", []string{"v0.0.0-20201015141213-0123456789ab"})
+				master.Versions[0].Prerelease = true
+				go1 := makeList("net/http", "std", "go1", []string{"go1.13beta1", "go1.12.5"})
+				go1.Versions[0].Prerelease = true
+				return &VersionsDetails{ThisModule: []*VersionList{master, go1}}
+			}(),
+		},
 		{
 			name: "want v1 first",
 			pkg:  pkg1,
@@ -181,6 +197,7 @@ func TestFetchPackageVersionsDetails(t *testing.T) {
 			if err != nil {
 				t.Fatalf("fetchVersionsDetails(ctx, db, %q, %q): %v", tc.pkg.Path, tc.pkg.ModulePath, err)
 			}
+			tc.wantDetails.LicenseChangesURL = "/license-changes/" + tc.pkg.ModulePath + ".atom"
 			for _, vl := range tc.wantDetails.ThisModule {
 				for _, v := range vl.Versions {
 					v.CommitTime = absoluteTime(tc.modules[0].CommitTime)
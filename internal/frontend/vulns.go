@@ -0,0 +1,173 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// Vuln describes a known vulnerability that affects a unit.
+type Vuln struct {
+	ID      string   `json:"id"`
+	Summary string   `json:"summary,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	// URL is a link to the advisory.
+	URL string `json:"url"`
+	// FixedVersion is the lowest later version in which the vulnerability is
+	// fixed. It is empty if there is no fix.
+	FixedVersion string `json:"fixedVersion,omitempty"`
+	// Symbols are the affected symbols of the package. They are empty if the
+	// unit is a directory or all of its symbols are affected.
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// UnitVulns is the response of the vulnerability API for a unit.
+type UnitVulns struct {
+	Path       string  `json:"path"`
+	ModulePath string  `json:"modulePath"`
+	Version    string  `json:"version"`
+	Vulns      []*Vuln `json:"vulns"`
+}
+
+// vulnsForUnit returns the vulnerabilities that affect the unit at path in
+// modulePath@version. It returns nil if ds does not store vulnerabilities.
+func vulnsForUnit(ctx context.Context, ds internal.DataSource, modulePath, version, path string) ([]*Vuln, error) {
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		return nil, nil
+	}
+	entries, err := db.GetVulnEntriesForModule(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return affectingVulns(entries, modulePath, version, path), nil
+}
+
+// affectingVulns returns the vulnerabilities of entries that affect the unit
+// at path in modulePath@version.
+func affectingVulns(entries []*vuln.Entry, modulePath, version, path string) []*Vuln {
+	var vulns []*Vuln
+	for _, e := range entries {
+		symbols, affected := e.Affects(modulePath, version, path)
+		if !affected {
+			continue
+		}
+		url := e.URL()
+		if url == "" {
+			url = "/vuln/" + e.ID + ".json"
+		}
+		summary := e.Summary
+		if summary == "" {
+			summary = e.Details
+		}
+		vulns = append(vulns, &Vuln{
+			ID:           e.ID,
+			Summary:      summary,
+			Aliases:      e.Aliases,
+			URL:          url,
+			FixedVersion: e.FixedVersion(modulePath, version),
+			Symbols:      symbols,
+		})
+	}
+	return vulns
+}
+
+// vulnsForVersions returns the IDs of the vulnerabilities that affect each of
+// the given versions of the unit, keyed by path@version. The path of the unit
+// in each version is returned by pathFor.
+func vulnsForVersions(ctx context.Context, db *postgres.DB, versions []*internal.ModuleInfo, pathFor func(*internal.ModuleInfo) string) (map[string][]string, error) {
+	ids := map[string][]string{}
+	byModule := map[string][]*vuln.Entry{}
+	for _, mi := range versions {
+		entries, ok := byModule[mi.ModulePath]
+		if !ok {
+			var err error
+			entries, err = db.GetVulnEntriesForModule(ctx, mi.ModulePath)
+			if err != nil {
+				return nil, err
+			}
+			byModule[mi.ModulePath] = entries
+		}
+		for _, v := range affectingVulns(entries, mi.ModulePath, mi.Version, pathFor(mi)) {
+			key := mi.ModulePath + "@" + mi.Version
+			ids[key] = append(ids[key], v.ID)
+		}
+	}
+	return ids, nil
+}
+
+// serveVuln handles requests for /vuln/<ID>.json, serving the OSV entry of
+// the advisory with the given ID as it was read from the vulnerability
+// database.
+func (s *Server) serveVuln(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveVuln(%q)", r.URL.Path)
+
+	db, ok := ds.(*postgres.DB)
+	if !ok {
+		// The proxydatasource does not store vulnerabilities.
+		return proxydatasourceNotSupportedErr()
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/vuln/")
+	if !strings.HasSuffix(id, ".json") || strings.Contains(id, "/") {
+		return &serverError{status: http.StatusNotFound}
+	}
+	e, err := db.GetVulnEntry(r.Context(), strings.TrimSuffix(id, ".json"))
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	return writeJSON(r.Context(), w, json.RawMessage(e.Raw))
+}
+
+// serveUnitVulns handles requests for /vulns/<path>[@version].json, serving
+// the vulnerabilities that affect the unit as JSON.
+func (s *Server) serveUnitVulns(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveUnitVulns(%q)", r.URL.Path)
+
+	if _, ok := ds.(*postgres.DB); !ok {
+		// The proxydatasource does not store vulnerabilities.
+		return proxydatasourceNotSupportedErr()
+	}
+	urlPath := strings.TrimPrefix(r.URL.Path, "/vulns")
+	if !strings.HasSuffix(urlPath, ".json") {
+		return &serverError{status: http.StatusNotFound}
+	}
+	info, err := extractURLPathInfo(strings.TrimSuffix(urlPath, ".json"))
+	if err != nil {
+		return &serverError{status: http.StatusBadRequest, err: err}
+	}
+	ctx := r.Context()
+	um, err := ds.GetUnitMeta(ctx, info.fullPath, info.modulePath, info.requestedVersion)
+	if err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{status: http.StatusNotFound, err: err}
+		}
+		return err
+	}
+	vulns, err := vulnsForUnit(ctx, ds, um.ModulePath, um.Version, um.Path)
+	if err != nil {
+		return err
+	}
+	if vulns == nil {
+		vulns = []*Vuln{}
+	}
+	return writeJSON(ctx, w, &UnitVulns{
+		Path:       um.Path,
+		ModulePath: um.ModulePath,
+		Version:    um.Version,
+		Vulns:      vulns,
+	})
+}
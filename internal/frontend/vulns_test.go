@@ -0,0 +1,62 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+func TestAffectingVulns(t *testing.T) {
+	var entries []*vuln.Entry
+	for _, s := range []string{
+		`{
+			"id": "GO-1",
+			"aliases": ["CVE-1"],
+			"summary": "Panic in parse",
+			"affected": [{
+				"package": {"ecosystem": "Go", "name": "example.com/a"},
+				"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.0"}]}],
+				"ecosystem_specific": {"imports": [{"path": "example.com/a/parse", "symbols": ["Parse"]}]}
+			}],
+			"references": [{"type": "ADVISORY", "url": "https://vuln.example.com/GO-1"}]
+		}`,
+		`{
+			"id": "GO-2",
+			"details": "Everything is affected",
+			"affected": [{"package": {"ecosystem": "Go", "name": "example.com/a"}}]
+		}`,
+	} {
+		e, err := vuln.Parse([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	go1 := &Vuln{
+		ID:           "GO-1",
+		Summary:      "Panic in parse",
+		Aliases:      []string{"CVE-1"},
+		URL:          "https://vuln.example.com/GO-1",
+		FixedVersion: "v1.2.0",
+		Symbols:      []string{"Parse"},
+	}
+	go2 := &Vuln{ID: "GO-2", Summary: "Everything is affected", URL: "/vuln/GO-2.json"}
+	for _, test := range []struct {
+		version, path string
+		want          []*Vuln
+	}{
+		{"v1.1.0", "example.com/a/parse", []*Vuln{go1, go2}},
+		{"v1.1.0", "example.com/a/other", []*Vuln{go2}},
+		{"v1.2.0", "example.com/a/parse", []*Vuln{go2}},
+	} {
+		got := affectingVulns(entries, "example.com/a", test.version, test.path)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%s@%s: mismatch (-want +got):\n%s", test.path, test.version, diff)
+		}
+	}
+}
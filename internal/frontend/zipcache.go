@@ -0,0 +1,238 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"archive/zip"
+	"container/list"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/proxy"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/zipio"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// maxSourceZipSize is the largest module zip that the source browser
+	// will download.
+	maxSourceZipSize = 100 * 1024 * 1024
+
+	// maxSourceZipCacheBytes is the total compressed size of the module zips
+	// kept in memory by the source browser.
+	maxSourceZipCacheBytes = 4 * maxSourceZipSize
+
+	// sourceZipTimeout bounds the time to load a module zip.
+	sourceZipTimeout = 2 * time.Minute
+)
+
+// zipCache holds the zips of recently browsed module versions, so that viewing
+// or searching several files of a module doesn't download its zip each time.
+// Concurrent requests for the same zip share a single download.
+//
+// Zips are kept in memory, up to maxBytes in total, evicting the least
+// recently used first. If dir is set, they are also kept on disk. The disk
+// cache is laid out like the module cache, in dir/<escaped module>/@v/<escaped
+// version>.zip. It is never pruned.
+type zipCache struct {
+	getZip   func(ctx context.Context, modulePath, resolvedVersion string) (*zip.Reader, error)
+	dir      string
+	maxBytes int64
+	group    singleflight.Group
+
+	mu      sync.Mutex
+	lru     *list.List // of *zipCacheEntry, most recently used first
+	entries map[string]*list.Element
+	size    int64 // total size of the entries in lru
+}
+
+type zipCacheEntry struct {
+	key  string
+	zr   *zip.Reader
+	size int64
+}
+
+// newZipCache returns a zipCache that downloads zips from proxyClient. Zips of
+// the standard library are read from the ones saved by the worker in
+// stdlib.ZipDir. If dir is non-empty, zips are also cached in that directory.
+func newZipCache(proxyClient *proxy.Client, dir string) *zipCache {
+	return newZipCacheWithGetter(func(ctx context.Context, modulePath, resolvedVersion string) (*zip.Reader, error) {
+		if modulePath == stdlib.ModulePath {
+			return stdlib.SavedZip(resolvedVersion)
+		}
+		size, err := proxyClient.GetZipSize(ctx, modulePath, resolvedVersion)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxSourceZipSize {
+			return nil, fmt.Errorf("zip is %d bytes: %w", size, derrors.ModuleTooLarge)
+		}
+		return proxyClient.GetZip(ctx, modulePath, resolvedVersion)
+	}, dir, maxSourceZipCacheBytes)
+}
+
+func newZipCacheWithGetter(getZip func(ctx context.Context, modulePath, resolvedVersion string) (*zip.Reader, error), dir string, maxBytes int64) *zipCache {
+	return &zipCache{
+		getZip:   getZip,
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// get returns the zip of the given module version. The zip is loaded with a
+// context that is independent of ctx, because the load is shared with
+// concurrent callers; ctx only bounds how long this caller waits for it.
+func (c *zipCache) get(ctx context.Context, modulePath, resolvedVersion string) (_ *zip.Reader, err error) {
+	defer derrors.Wrap(&err, "zipCache.get(%q, %q)", modulePath, resolvedVersion)
+
+	key := modulePath + "@" + resolvedVersion
+	if zr := c.lookup(key); zr != nil {
+		return zr, nil
+	}
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		if zr := c.lookup(key); zr != nil {
+			return zr, nil
+		}
+		lctx, cancel := context.WithTimeout(context.Background(), sourceZipTimeout)
+		defer cancel()
+		zr, err := c.load(lctx, modulePath, resolvedVersion)
+		if err != nil {
+			return nil, err
+		}
+		c.add(key, zr)
+		return zr, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*zip.Reader), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load reads the zip of the given module version from disk, or downloads it.
+func (c *zipCache) load(ctx context.Context, modulePath, resolvedVersion string) (*zip.Reader, error) {
+	if modulePath == stdlib.ModulePath {
+		// The worker already saves standard library zips on disk.
+		return c.getZip(ctx, modulePath, resolvedVersion)
+	}
+	zr, err := c.readDisk(modulePath, resolvedVersion)
+	if err != nil {
+		// The disk cache is only an optimization.
+		log.Warningf(ctx, "reading zip cache: %v", err)
+	}
+	if zr != nil {
+		return zr, nil
+	}
+	zr, err = c.getZip(ctx, modulePath, resolvedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.writeDisk(zr, modulePath, resolvedVersion); err != nil {
+		log.Warningf(ctx, "writing zip cache: %v", err)
+	}
+	return zr, nil
+}
+
+// lookup returns the zip with the given key from memory, or nil if it isn't
+// there.
+func (c *zipCache) lookup(key string) *zip.Reader {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*zipCacheEntry).zr
+}
+
+// add keeps zr in memory, evicting the least recently used zips to stay
+// within maxBytes. A zip larger than maxBytes is not kept.
+func (c *zipCache) add(key string, zr *zip.Reader) {
+	size := zipSize(zr)
+	if size > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	for c.size+size > c.maxBytes {
+		e := c.lru.Back()
+		ze := c.lru.Remove(e).(*zipCacheEntry)
+		delete(c.entries, ze.key)
+		c.size -= ze.size
+	}
+	c.entries[key] = c.lru.PushFront(&zipCacheEntry{key: key, zr: zr, size: size})
+	c.size += size
+}
+
+// zipSize returns the approximate number of bytes of memory used by zr, the
+// total compressed size of its files.
+func zipSize(zr *zip.Reader) int64 {
+	var n int64
+	for _, f := range zr.File {
+		n += int64(f.CompressedSize64)
+	}
+	return n
+}
+
+// diskPath returns the name of the file in which the zip of the given module
+// version is cached.
+func (c *zipCache) diskPath(modulePath, resolvedVersion string) (string, error) {
+	ep, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+	ev, err := module.EscapeVersion(resolvedVersion)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.dir, filepath.FromSlash(ep), "@v", ev+".zip"), nil
+}
+
+// readDisk returns the zip of the given module version from the disk cache,
+// or nil if it isn't cached.
+func (c *zipCache) readDisk(modulePath, resolvedVersion string) (_ *zip.Reader, err error) {
+	if c.dir == "" {
+		return nil, nil
+	}
+	filename, err := c.diskPath(modulePath, resolvedVersion)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zipio.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return zr, err
+}
+
+// writeDisk writes zr to the disk cache.
+func (c *zipCache) writeDisk(zr *zip.Reader, modulePath, resolvedVersion string) (err error) {
+	if c.dir == "" {
+		return nil
+	}
+	filename, err := c.diskPath(modulePath, resolvedVersion)
+	if err != nil {
+		return err
+	}
+	return zipio.WriteFile(filename, zr)
+}
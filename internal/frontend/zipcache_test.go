@@ -0,0 +1,178 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+func TestZipCache(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "zipcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	zr := newTestZip(t, map[string]string{"example.com/M@v1.0.0/a.go": "package a\n"})
+	downloads := 0
+	newCache := func() *zipCache {
+		return newZipCacheWithGetter(func(_ context.Context, modulePath, resolvedVersion string) (*zip.Reader, error) {
+			downloads++
+			return zr, nil
+		}, dir, 2*zipSize(zr))
+	}
+
+	c := newCache()
+	for i := 0; i < 2; i++ {
+		if _, err := c.get(ctx, "example.com/M", "v1.0.0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("got %d downloads, want 1", downloads)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com", "!m", "@v", "v1.0.0.zip")); err != nil {
+		t.Errorf("zip not cached on disk: %v", err)
+	}
+
+	// A new cache reads the zip from disk.
+	got, err := newCache().get(ctx, "example.com/M", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if downloads != 1 {
+		t.Errorf("got %d downloads, want 1", downloads)
+	}
+	if len(got.File) != 1 || got.File[0].Name != "example.com/M@v1.0.0/a.go" {
+		t.Errorf("got files %v, want example.com/M@v1.0.0/a.go", got.File)
+	}
+
+	// The in-memory cache is bounded, evicting the least recently used zip.
+	for _, v := range []string{"v1.0.1", "v1.0.2", "v1.0.1", "v1.0.3"} {
+		if _, err := c.get(ctx, "example.com/M", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var keys []string
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(*zipCacheEntry).key)
+	}
+	if want := []string{"example.com/M@v1.0.3", "example.com/M@v1.0.1"}; !cmp.Equal(keys, want) {
+		t.Errorf("got keys %v in memory, want %v", keys, want)
+	}
+	if want := 2 * zipSize(zr); c.size != want {
+		t.Errorf("got size %d, want %d", c.size, want)
+	}
+}
+
+func TestZipCacheSingleflight(t *testing.T) {
+	ctx := context.Background()
+	zr := newTestZip(t, map[string]string{"example.com/M@v1.0.0/a.go": "package a\n"})
+	var downloads int32
+	release := make(chan struct{})
+	c := newZipCacheWithGetter(func(_ context.Context, modulePath, resolvedVersion string) (*zip.Reader, error) {
+		atomic.AddInt32(&downloads, 1)
+		<-release
+		return zr, nil
+	}, "", maxSourceZipCacheBytes)
+
+	const n = 5
+	errc := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := c.get(ctx, "example.com/M", "v1.0.0")
+			errc <- err
+		}()
+	}
+	// Give the goroutines time to wait on the same download.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	for i := 0; i < n; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := atomic.LoadInt32(&downloads); got != 1 {
+		t.Errorf("got %d downloads, want 1", got)
+	}
+}
+
+func TestZipCacheCanceledCaller(t *testing.T) {
+	zr := newTestZip(t, map[string]string{"example.com/M@v1.0.0/a.go": "package a\n"})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := newZipCacheWithGetter(func(ctx context.Context, modulePath, resolvedVersion string) (*zip.Reader, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return zr, nil
+	}, "", maxSourceZipCacheBytes)
+
+	// The first caller gives up while the zip is loading.
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.get(ctx, "example.com/M", "v1.0.0")
+		errc <- err
+	}()
+	<-started
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller: got %v, want context.Canceled", err)
+	}
+
+	// The load it started is not canceled, and other callers share it.
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.get(context.Background(), "example.com/M", "v1.0.0")
+		done <- err
+	}()
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZipCacheStdlib(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "stdlibzips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d string) { stdlib.ZipDir = d }(stdlib.ZipDir)
+	stdlib.ZipDir = dir
+
+	c := newZipCache(nil, "")
+	if _, err := c.get(ctx, stdlib.ModulePath, "v1.15.0"); !errors.Is(err, derrors.NotFound) {
+		t.Fatalf("got %v, want NotFound", err)
+	}
+	zr := newTestZip(t, map[string]string{"std@v1.15.0/fmt/print.go": "package fmt\n"})
+	if err := stdlib.SaveZip(zr, "v1.15.0"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.get(ctx, stdlib.ModulePath, "v1.15.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.File) != 1 || got.File[0].Name != "std@v1.15.0/fmt/print.go" {
+		t.Errorf("got files %v, want std@v1.15.0/fmt/print.go", got.File)
+	}
+}
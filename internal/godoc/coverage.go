@@ -0,0 +1,83 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"go/ast"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc/internal/doc"
+)
+
+// DocInfo holds facts about a package's documentation that are computed when
+// it is rendered.
+type DocInfo struct {
+	// Deprecated reports whether the package comment has a paragraph
+	// beginning with "Deprecated:".
+	Deprecated bool
+	// Coverage describes how much of the package's exported API is
+	// documented.
+	Coverage *internal.DocCoverage
+}
+
+// docCoverage computes the documentation coverage of d. It counts exported
+// constants, variables, functions, types and methods. A constant or variable
+// is documented if either its declaration group or its own line has a
+// comment. The declarations of commands are not counted.
+func docCoverage(d *doc.Package) *internal.DocCoverage {
+	c := &internal.DocCoverage{NumPackages: 1}
+	if d.Doc == "" {
+		c.NumMissingPackageDocs = 1
+	}
+	if d.Name == "main" {
+		return c
+	}
+	count := func(name, doc string) {
+		if !ast.IsExported(name) {
+			return
+		}
+		c.NumExported++
+		if doc != "" {
+			c.NumDocumented++
+		}
+	}
+	countValues := func(vs []*doc.Value) {
+		for _, v := range vs {
+			for _, spec := range v.Decl.Specs {
+				vspec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				text := v.Doc
+				if text == "" && vspec.Doc != nil {
+					text = vspec.Doc.Text()
+				}
+				if text == "" && vspec.Comment != nil {
+					text = vspec.Comment.Text()
+				}
+				for _, n := range vspec.Names {
+					count(n.Name, text)
+				}
+			}
+		}
+	}
+	countFuncs := func(fs []*doc.Func) {
+		for _, f := range fs {
+			count(f.Name, f.Doc)
+		}
+	}
+
+	countValues(d.Consts)
+	countValues(d.Vars)
+	countFuncs(d.Funcs)
+	for _, t := range d.Types {
+		count(t.Name, t.Doc)
+		countValues(t.Consts)
+		countValues(t.Vars)
+		countFuncs(t.Funcs)
+		countFuncs(t.Methods)
+	}
+	return c
+}
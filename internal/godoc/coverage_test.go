@@ -0,0 +1,108 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc/internal/doc"
+)
+
+func TestDocCoverage(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+		want *internal.DocCoverage
+	}{
+		{
+			name: "documented",
+			src: `
+				// Package p is documented.
+				package p
+
+				// Documented group.
+				const (
+					A = 1
+					B = 2
+				)
+
+				var (
+					// V is documented.
+					V int
+					W int // W is documented.
+					X int
+				)
+
+				// F is documented.
+				func F() {}
+
+				func G() {}
+
+				// T is documented.
+				type T int
+
+				func NewT() T { return 0 }
+
+				// M is documented.
+				func (T) M() {}
+
+				func (T) m() {}
+			`,
+			want: &internal.DocCoverage{
+				NumPackages:   1,
+				NumExported:   10,
+				NumDocumented: 7,
+			},
+		},
+		{
+			name: "no package comment",
+			src: `
+				package p
+
+				// F is documented.
+				func F() {}
+			`,
+			want: &internal.DocCoverage{
+				NumPackages:           1,
+				NumMissingPackageDocs: 1,
+				NumExported:           1,
+				NumDocumented:         1,
+			},
+		},
+		{
+			name: "command",
+			src: `
+				// Command main does things.
+				package main
+
+				func Exported() {}
+
+				func main() {}
+			`,
+			want: &internal.DocCoverage{NumPackages: 1},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "p.go", test.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := doc.NewFromFiles(fset, []*ast.File{f}, "example.com/p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := docCoverage(d)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
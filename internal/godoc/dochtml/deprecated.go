@@ -0,0 +1,18 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import "regexp"
+
+// deprecatedRegexp matches the start of a doc comment paragraph that begins
+// with "Deprecated:".
+var deprecatedRegexp = regexp.MustCompile(`(?:^|\n\s*\n)\s*Deprecated:`)
+
+// IsDeprecated reports whether doc, the text of a doc comment, marks its
+// package or declaration as deprecated. By convention, that is done with a
+// paragraph beginning with "Deprecated:".
+func IsDeprecated(doc string) bool {
+	return deprecatedRegexp.MatchString(doc)
+}
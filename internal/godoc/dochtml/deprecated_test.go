@@ -0,0 +1,26 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import "testing"
+
+func TestIsDeprecated(t *testing.T) {
+	for _, test := range []struct {
+		doc  string
+		want bool
+	}{
+		{"", false},
+		{"Deprecated: use Bar.", true},
+		{"Foo does things.\n\nDeprecated: use Bar.\n", true},
+		{"Foo does things.\n  \nDeprecated: use Bar.\n", true},
+		{"Foo does things.\nDeprecated: use Bar.\n", false},
+		{"Foo is not Deprecated: really.", false},
+		{"Foo does things.\n\nThe old API is deprecated.", false},
+	} {
+		if got := IsDeprecated(test.doc); got != test.want {
+			t.Errorf("IsDeprecated(%q) = %t, want %t", test.doc, got, test.want)
+		}
+	}
+}
@@ -246,14 +246,31 @@ func executeToHTMLWithLimit(tmpl *template.Template, data interface{}, limit int
 	return uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract(buf.B.String()), nil
 }
 
-// linkHTML returns an HTML-formatted name linked to the given URL.
+// linkHTML returns an HTML-formatted name linked to the given source URL.
 // The class argument is the class of the 'a' tag.
 // If url is the empty string, the name is not linked.
 func linkHTML(name, url, class string) safehtml.HTML {
 	if url == "" {
 		return safehtml.HTMLEscaped(name)
 	}
-	return render.ExecuteToHTML(render.LinkTemplate, render.Link{Class: class, Href: url, Text: name})
+	return render.ExecuteToHTML(sourceLinkTemplate, struct {
+		Href        safehtml.URL
+		Text, Class string
+	}{SourceURL(url), name, class})
+}
+
+var sourceLinkTemplate = template.Must(template.New("sourceLink").Parse(
+	`<a {{with .Class}}class="{{.}}" {{end}}href="{{.Href}}">{{.Text}}</a>`))
+
+// SourceURL returns u, a link to source code, as a safehtml.URL. Besides the
+// URLs that safehtml allows, it allows file:/// URLs, which link to the files
+// of modules in local mode.
+func SourceURL(u string) safehtml.URL {
+	if strings.HasPrefix(u, "file:///") {
+		// A file URL can't run code, so it is as safe as an http URL.
+		return uncheckedconversions.URLFromStringKnownToSatisfyTypeContract(u)
+	}
+	return safehtml.URLSanitized(u)
 }
 
 // examples is an internal representation of all package examples.
@@ -82,6 +82,16 @@ func TestRender(t *testing.T) {
 	if err := checker(htmlDoc); err != nil {
 		t.Errorf("note check: %v", err)
 	}
+
+	checker = in("#FD", in(".Documentation-deprecatedTag", hasExactText("deprecated")))
+	if err := checker(htmlDoc); err != nil {
+		t.Errorf("deprecated tag check: %v", err)
+	}
+	checker = in(".Documentation-function .Documentation-deprecatedDetails",
+		in(".Documentation-deprecatedSummary", hasExactText("Show")))
+	if err := checker(htmlDoc); err != nil {
+		t.Errorf("deprecated details check: %v", err)
+	}
 }
 
 func TestRenderParts(t *testing.T) {
@@ -300,6 +310,18 @@ func TestLinkHTML(t *testing.T) {
 			link: `"><script>bad</script>`,
 			want: `<a class="class" href="%22%3e%3cscript%3ebad%3c/script%3e">&lt;a href=&#34;gfr.con&#34;&gt;&lt;/a&gt;</a>`,
 		},
+		{
+			name: "local file link is allowed",
+			in:   "file.go",
+			link: "file:///home/me/m/file.go#L3",
+			want: `<a class="class" href="file:///home/me/m/file.go#L3">file.go</a>`,
+		},
+		{
+			name: "unsafe scheme is sanitized",
+			in:   "file.go",
+			link: "javascript:alert(1)",
+			want: `<a class="class" href="about:invalid#zGoSafez">file.go</a>`,
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got := linkHTML(test.in, test.link, "class")
@@ -457,6 +479,7 @@ func testIDsAndKinds(t *testing.T, htmlDoc *html.Node) {
 		{"C", "constant"},
 		{"CT", "constant"},
 		{"F", "function"},
+		{"FD", "function"},
 		{"TF", "function"},
 		{"T.M", "method"},
 		{"V", "variable"},
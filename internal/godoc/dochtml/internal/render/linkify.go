@@ -427,8 +427,13 @@ scan:
 			break scan
 		case token.COMMENT:
 			tokType = commentType
+			commentStart := template.MustParseAndExecuteToHTML(`<span class="comment">`)
+			if isDeprecatedComment(lit) {
+				// Mark deprecated fields and interface methods.
+				commentStart = template.MustParseAndExecuteToHTML(`<span class="comment deprecated">`)
+			}
 			htmlLines[line] = append(htmlLines[line],
-				template.MustParseAndExecuteToHTML(`<span class="comment">`),
+				commentStart,
 				r.formatLineHTML(lit, idr),
 				template.MustParseAndExecuteToHTML(`</span>`))
 			lastOffset += len(lit)
@@ -701,3 +706,11 @@ func generateAnchorLinks(idr *identifierResolver, decl ast.Decl) map[*ast.Ident]
 	})
 	return m
 }
+
+// isDeprecatedComment reports whether the comment token lit begins a
+// "Deprecated:" paragraph.
+func isDeprecatedComment(lit string) bool {
+	text := strings.TrimPrefix(lit, "//")
+	text = strings.TrimPrefix(text, "/*")
+	return strings.HasPrefix(strings.TrimSpace(text), "Deprecated:")
+}
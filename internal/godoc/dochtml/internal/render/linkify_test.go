@@ -516,3 +516,20 @@ func TestParseLink(t *testing.T) {
 		}
 	}
 }
+
+func TestIsDeprecatedComment(t *testing.T) {
+	for _, test := range []struct {
+		lit  string
+		want bool
+	}{
+		{"// Deprecated: use Y.", true},
+		{"//Deprecated: use Y.", true},
+		{"/* Deprecated: use Y. */", true},
+		{"// X is Deprecated: no.", false},
+		{"// Deprecated", false},
+	} {
+		if got := isDeprecatedComment(test.lit); got != test.want {
+			t.Errorf("isDeprecatedComment(%q) = %t, want %t", test.lit, got, test.want)
+		}
+	}
+}
@@ -61,4 +61,5 @@ var tmpl = map[string]interface{}{
 	"source_link":              func() string { return "" },
 	"play_url":                 func(*doc.Example) string { return "" },
 	"safe_id":                  render.SafeGoID,
+	"is_deprecated":            IsDeprecated,
 }
@@ -23,6 +23,11 @@ var V = 2
 // func
 func F() {}
 
+// deprecated func
+//
+// Deprecated: use F.
+func FD() {}
+
 // type
 type T int
 
@@ -22,6 +22,12 @@ type Package struct {
 	Fset *token.FileSet
 	encPackage
 	renderCalled bool
+
+	// AllDecls, if true, causes RenderParts to render documentation for
+	// unexported identifiers as well as exported ones. It is only useful
+	// if the package's files were added with AddFileWithUnexported, or with
+	// removeNodes set to false.
+	AllDecls bool
 }
 
 // encPackage holds the fields of Package that can be directly encoded.
@@ -72,11 +78,22 @@ func NewPackage(fset *token.FileSet, goos, goarch string, modPaths map[string]bo
 // AddFile adds a file to the Package. After it returns, the contents of the ast.File
 // are unsuitable for anything other than the methods of this package.
 func (p *Package) AddFile(f *ast.File, removeNodes bool) {
+	p.addFile(f, removeNodes, false)
+}
+
+// AddFileWithUnexported is like AddFile with removeNodes set to true, except
+// that it keeps unexported declarations, so that their documentation can be
+// rendered with AllDecls.
+func (p *Package) AddFileWithUnexported(f *ast.File) {
+	p.addFile(f, true, true)
+}
+
+func (p *Package) addFile(f *ast.File, removeNodes, keepUnexported bool) {
 	filename := p.Fset.Position(f.Package).Filename
 	// Don't trim anything from a test file or one in a XXX_test package; it
 	// may be part of a playable example.
 	if removeNodes && !strings.HasSuffix(filename, "_test.go") && !strings.HasSuffix(f.Name.Name, "_test") {
-		removeUnusedASTNodes(f)
+		removeUnusedASTNodes(f, keepUnexported)
 	}
 	p.Files = append(p.Files, &File{
 		Name: filename,
@@ -86,12 +103,14 @@ func (p *Package) AddFile(f *ast.File, removeNodes bool) {
 
 // removeUnusedASTNodes removes parts of the AST not needed for documentation.
 // It doesn't remove unexported consts, vars or types, although it probably could.
-func removeUnusedASTNodes(pf *ast.File) {
+// If keepUnexported is true, it doesn't remove unexported functions either,
+// only their bodies.
+func removeUnusedASTNodes(pf *ast.File, keepUnexported bool) {
 	var decls []ast.Decl
 	for _, d := range pf.Decls {
 		if f, ok := d.(*ast.FuncDecl); ok {
 			// Remove all unexported functions and function bodies.
-			if f.Name == nil || !ast.IsExported(f.Name.Name) {
+			if f.Name == nil || (!keepUnexported && !ast.IsExported(f.Name.Name)) {
 				continue
 			}
 			// Remove the function body, unless it's an example.
@@ -94,19 +94,67 @@ func (t T) M() int
 func (t) U()
 `
 	////////////////
+	const wantKeepUnexported = `// Package-level comment.
+package p
 
-	fset := token.NewFileSet()
-	astFile, err := parser.ParseFile(fset, "tst.go", file, parser.ParseComments)
-	if err != nil {
-		t.Fatal(err)
-	}
-	removeUnusedASTNodes(astFile)
-	var buf bytes.Buffer
-	if err := format.Node(&buf, fset, astFile); err != nil {
-		t.Fatal(err)
-	}
-	got := buf.String()
-	if diff := cmp.Diff(want, got); diff != "" {
-		t.Errorf("mismatch (-want, +got):\n%s", diff)
+// const C
+const C = 1
+
+// leave unexported consts
+const c = 1
+
+// var V
+var V int
+
+// leave unexported vars
+var v int
+
+// type T
+type T int
+
+// leave unexported types
+type t int
+
+// Exp is exported.
+func Exp()
+
+// unexp is not exported, but the comment is preserved for notes.
+func unexp()
+
+// M is exported.
+func (t T) M() int
+
+// m isn't, but the comment is preserved for notes.
+func (T) m()
+
+// U is an exported method of an unexported type.
+// Its doc is not shown, unless t is embedded
+// in an exported type. We don't remove it to
+// be safe.
+func (t) U()
+`
+	////////////////
+
+	for _, test := range []struct {
+		keepUnexported bool
+		want           string
+	}{
+		{false, want},
+		{true, wantKeepUnexported},
+	} {
+		fset := token.NewFileSet()
+		astFile, err := parser.ParseFile(fset, "tst.go", file, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		removeUnusedASTNodes(astFile, test.keepUnexported)
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, astFile); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("keepUnexported=%t: mismatch (-want, +got):\n%s", test.keepUnexported, diff)
+		}
 	}
 }
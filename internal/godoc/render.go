@@ -44,9 +44,10 @@ var noDocTemplate = template.Must(template.New("").Parse(`<p>No documentation fo
 type Renderer struct {
 }
 
-// Render renders the documentation for the package.
+// Render renders the documentation for the package. It also returns facts
+// about the documentation, such as whether it marks the package deprecated.
 // Rendering destroys p's AST; do not call any methods of p after it returns.
-func (p *Package) Render(ctx context.Context, innerPath string, sourceInfo *source.Info, modInfo *ModuleInfo, goos, goarch string) (synopsis string, imports []string, html safehtml.HTML, err error) {
+func (p *Package) Render(ctx context.Context, innerPath string, sourceInfo *source.Info, modInfo *ModuleInfo, goos, goarch string) (synopsis string, imports []string, info *DocInfo, html safehtml.HTML, err error) {
 	// This is mostly copied from internal/fetch/fetch.go.
 	defer derrors.Wrap(&err, "godoc.Package.Render(%q, %q, %q, %q, %q)", modInfo.ModulePath, modInfo.ResolvedVersion, innerPath, goos, goarch)
 
@@ -56,24 +57,29 @@ func (p *Package) Render(ctx context.Context, innerPath string, sourceInfo *sour
 	if (goos != "" && goos != p.GOOS) || (goarch != "" && goarch != p.GOARCH) {
 		html, err := noDocTemplate.ExecuteToHTML(goos + "/" + goarch)
 		if err != nil {
-			return "", nil, safehtml.HTML{}, err
+			return "", nil, nil, safehtml.HTML{}, err
 		}
-		return "No documentation.", nil, html, errors.New("no doc")
+		return "No documentation.", nil, nil, html, errors.New("no doc")
 	}
 	d, err := p.docPackage(innerPath, modInfo)
 	if err != nil {
-		return "", nil, safehtml.HTML{}, err
+		return "", nil, nil, safehtml.HTML{}, err
 	}
 
-	// Render documentation HTML.
-	opts := p.renderOptions(innerPath, sourceInfo, modInfo)
+	// Render documentation HTML. It is stored in the database, so it must not
+	// link to the source browser, which only some frontends serve.
+	opts := p.renderOptions(innerPath, sourceInfo, modInfo, false)
 	docHTML, err := dochtml.Render(ctx, p.Fset, d, opts)
 	if errors.Is(err, ErrTooLarge) {
 		docHTML = template.MustParseAndExecuteToHTML(DocTooLargeReplacement)
 	} else if err != nil {
-		return "", nil, safehtml.HTML{}, fmt.Errorf("dochtml.Render: %v", err)
+		return "", nil, nil, safehtml.HTML{}, fmt.Errorf("dochtml.Render: %v", err)
 	}
-	return doc.Synopsis(d.Doc), d.Imports, docHTML, err
+	info = &DocInfo{
+		Deprecated: dochtml.IsDeprecated(d.Doc),
+		Coverage:   docCoverage(d),
+	}
+	return doc.Synopsis(d.Doc), d.Imports, info, docHTML, err
 }
 
 // docPackage computes and returns a doc.Package.
@@ -102,6 +108,9 @@ func (p *Package) docPackage(innerPath string, modInfo *ModuleInfo) (_ *doc.Pack
 	if noFiltering {
 		m |= doc.AllDecls
 	}
+	if p.AllDecls {
+		m |= doc.AllDecls | doc.AllMethods
+	}
 	var allGoFiles []*ast.File
 	for _, f := range p.Files {
 		allGoFiles = append(allGoFiles, f.AST)
@@ -130,21 +139,29 @@ func (p *Package) docPackage(innerPath string, modInfo *ModuleInfo) (_ *doc.Pack
 	return d, nil
 }
 
-// renderOptions returns a RenderOptions for p.
-func (p *Package) renderOptions(innerPath string, sourceInfo *source.Info, modInfo *ModuleInfo) dochtml.RenderOptions {
+// renderOptions returns a RenderOptions for p. If the module's source is not
+// on a known code host and hostedSource is true, source links point to the
+// source browser on this site.
+func (p *Package) renderOptions(innerPath string, sourceInfo *source.Info, modInfo *ModuleInfo, hostedSource bool) dochtml.RenderOptions {
 	sourceLinkFunc := func(n ast.Node) string {
-		if sourceInfo == nil {
-			return ""
-		}
 		p := p.Fset.Position(n.Pos())
 		if p.Line == 0 { // invalid Position
 			return ""
 		}
+		if sourceInfo == nil {
+			if !hostedSource {
+				return ""
+			}
+			return fmt.Sprintf("%s#L%d", HostedSourceURL(modInfo.ModulePath, modInfo.ResolvedVersion, path.Join(innerPath, p.Filename)), p.Line)
+		}
 		return sourceInfo.LineURL(path.Join(innerPath, p.Filename), p.Line)
 	}
 	fileLinkFunc := func(filename string) string {
 		if sourceInfo == nil {
-			return ""
+			if !hostedSource {
+				return ""
+			}
+			return HostedSourceURL(modInfo.ModulePath, modInfo.ResolvedVersion, path.Join(innerPath, filename))
 		}
 		return sourceInfo.FileURL(path.Join(innerPath, filename))
 	}
@@ -157,16 +174,24 @@ func (p *Package) renderOptions(innerPath string, sourceInfo *source.Info, modIn
 	}
 }
 
+// HostedSourceURL returns the URL path of a file or directory in the source
+// browser served by this site. filePath is relative to the module root.
+func HostedSourceURL(modulePath, version, filePath string) string {
+	return path.Join("/src", modulePath+"@"+version, filePath)
+}
+
 // RenderParts renders the documentation for the package in parts.
+// hostedSource reports whether this site serves a source browser that source
+// links can point to when the module's source is not on a known code host.
 // Rendering destroys p's AST; do not call any methods of p after it returns.
-func (p *Package) RenderParts(ctx context.Context, innerPath string, sourceInfo *source.Info, modInfo *ModuleInfo) (_ *dochtml.Parts, err error) {
+func (p *Package) RenderParts(ctx context.Context, innerPath string, sourceInfo *source.Info, modInfo *ModuleInfo, hostedSource bool) (_ *dochtml.Parts, err error) {
 	p.renderCalled = true
 
 	d, err := p.docPackage(innerPath, modInfo)
 	if err != nil {
 		return nil, err
 	}
-	opts := p.renderOptions(innerPath, sourceInfo, modInfo)
+	opts := p.renderOptions(innerPath, sourceInfo, modInfo, hostedSource)
 	parts, err := dochtml.RenderParts(ctx, p.Fset, d, opts)
 	if errors.Is(err, ErrTooLarge) {
 		return &dochtml.Parts{Body: template.MustParseAndExecuteToHTML(DocTooLargeReplacement)}, nil
@@ -195,5 +220,5 @@ func RenderPartsFromUnit(ctx context.Context, u *internal.Unit) (_ *dochtml.Part
 	} else if u.Path != u.ModulePath {
 		innerPath = u.Path[len(u.ModulePath)+1:]
 	}
-	return docPkg.RenderParts(ctx, innerPath, u.SourceInfo, modInfo)
+	return docPkg.RenderParts(ctx, innerPath, u.SourceInfo, modInfo, false)
 }
@@ -6,6 +6,8 @@ package godoc
 
 import (
 	"context"
+	"go/parser"
+	"go/token"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -35,7 +37,7 @@ func TestRender(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	wantSyn, wantImports, wantDoc, err := p.Render(ctx, "p", si, mi, "", "")
+	wantSyn, wantImports, _, wantDoc, err := p.Render(ctx, "p", si, mi, "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -45,7 +47,7 @@ func TestRender(t *testing.T) {
 
 	check := func(p *Package) {
 		t.Helper()
-		gotSyn, gotImports, gotDoc, err := p.Render(ctx, "p", si, mi, "", "")
+		gotSyn, gotImports, _, gotDoc, err := p.Render(ctx, "p", si, mi, "", "")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -85,3 +87,41 @@ func TestRender(t *testing.T) {
 	}
 	check(p2)
 }
+
+func TestRenderPartsAllDecls(t *testing.T) {
+	dochtml.LoadTemplates(templateSource)
+	ctx := context.Background()
+	mi := &ModuleInfo{
+		ModulePath:      sample.ModulePath,
+		ResolvedVersion: sample.VersionString,
+	}
+	for _, test := range []struct {
+		allDecls bool
+		want     bool
+	}{
+		{false, false},
+		{true, true},
+	} {
+		fset := token.NewFileSet()
+		pf, err := parser.ParseFile(fset, filepath.Join("testdata", "p", "p.go"), nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := NewPackage(fset, "linux", "amd64", nil)
+		p.AddFileWithUnexported(pf)
+		p.AllDecls = test.allDecls
+		parts, err := p.RenderParts(ctx, "p", nil, mi, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := parts.Body.String()
+		for _, id := range []string{`id="unexp"`, `id="us"`, `id="T.m"`} {
+			if got := strings.Contains(body, id); got != test.want {
+				t.Errorf("AllDecls=%t: body contains %s = %t, want %t", test.allDecls, id, got, test.want)
+			}
+		}
+		if strings.Contains(body, "return") {
+			t.Errorf("AllDecls=%t: doc rendered with function bodies", test.allDecls)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import "golang.org/x/mod/semver"
+
+// GoMod holds the directives of a module's go.mod file that are of interest
+// to users browsing the module.
+type GoMod struct {
+	// GoVersion is the version in the go directive, or the empty string if
+	// there is no go directive.
+	GoVersion string
+	// Deprecated reports whether the module directive is preceded or followed
+	// by a comment paragraph beginning with "Deprecated:".
+	Deprecated bool
+	// DeprecationComment is the rest of that paragraph.
+	DeprecationComment string
+
+	Requires []*GoModRequire
+	Replaces []*GoModReplace
+	Excludes []*GoModExclude
+	Retracts []*GoModRetract
+}
+
+// GoModRequire is a require directive in a go.mod file.
+type GoModRequire struct {
+	ModulePath string
+	Version    string
+	Indirect   bool // has an "// indirect" comment
+}
+
+// GoModReplace is a replace directive in a go.mod file.
+type GoModReplace struct {
+	OldPath    string
+	OldVersion string // empty if all versions of OldPath are replaced
+	NewPath    string
+	NewVersion string // empty if NewPath is a file path
+}
+
+// IsLocal reports whether the replacement is a directory on the local file
+// system, rather than a module.
+func (r *GoModReplace) IsLocal() bool {
+	return r.NewVersion == ""
+}
+
+// GoModExclude is an exclude directive in a go.mod file.
+type GoModExclude struct {
+	ModulePath string
+	Version    string
+}
+
+// GoModRetract is a retract directive in a go.mod file. It retracts the
+// closed interval of versions [Low, High]; for a single version, Low and High
+// are the same.
+type GoModRetract struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// Includes reports whether version v is in the interval retracted by r.
+func (r *GoModRetract) Includes(v string) bool {
+	return semver.Compare(r.Low, v) <= 0 && semver.Compare(v, r.High) <= 0
+}
+
+// A RequirementEdge is an edge of the module requirement graph: a require
+// directive in the go.mod file of the module version ModulePath@Version.
+type RequirementEdge struct {
+	ModulePath      string
+	Version         string
+	RequiredPath    string
+	RequiredVersion string
+	Indirect        bool
+}
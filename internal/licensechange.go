@@ -0,0 +1,42 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import "time"
+
+// A LicenseChange is a change to a license file of a module version, compared
+// with the previous version of its series. A file that was added or removed
+// is a change, as is a file whose license types changed. Changes to the
+// contents of a file that leave its types unchanged are not recorded.
+type LicenseChange struct {
+	ModulePath string
+	Version    string
+	// PreviousModulePath and PreviousVersion identify the version with which
+	// the license files were compared. The module path differs from
+	// ModulePath if the versions have different major versions.
+	PreviousModulePath string
+	PreviousVersion    string
+
+	FilePath string
+	// OldTypes are the license types of the file in the previous version. It
+	// is nil if the file was added.
+	OldTypes []string
+	// NewTypes are the license types of the file in this version. It is nil
+	// if the file was removed.
+	NewTypes []string
+
+	// CreatedAt is the time at which the change was detected.
+	CreatedAt time.Time
+}
+
+// Added reports whether the license file was added.
+func (c *LicenseChange) Added() bool {
+	return c.OldTypes == nil
+}
+
+// Removed reports whether the license file was removed.
+func (c *LicenseChange) Removed() bool {
+	return c.NewTypes == nil
+}
@@ -0,0 +1,396 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/licensecheck"
+)
+
+// A SegmentKind describes how a TextSegment compares with the canonical texts
+// of the licenses found in a license file.
+type SegmentKind int
+
+const (
+	// SegmentMatched is text that matches a canonical license text, or that
+	// fills a blank in one, like the name of a copyright holder. Text that
+	// could not be compared is also considered matched.
+	SegmentMatched SegmentKind = iota
+	// SegmentExtra is text that is not part of a canonical license text,
+	// like an additional clause.
+	SegmentExtra
+	// SegmentMissing is text of a canonical license that the file omits.
+	SegmentMissing
+)
+
+// A TextSegment is a part of the text of a license file, or a part of a
+// canonical license text that is missing from it.
+type TextSegment struct {
+	Kind SegmentKind
+	Text string
+}
+
+// maxCompareEdits is the largest number of words that may differ between a
+// license file and a canonical license text for them to be compared. It
+// bounds the time and memory spent comparing texts that barely match.
+const maxCompareEdits = 500
+
+// wildcard is the word that stands for a blank to be filled in, like the
+// year and holder of a copyright, in the canonical license texts of
+// licensecheck.
+const wildcard = "___"
+
+// canonicalTexts maps the names of the licenses known to licensecheck to
+// their texts.
+var canonicalTexts = func() map[string]string {
+	m := map[string]string{}
+	for _, l := range licensecheck.BuiltinLicenses() {
+		if l.Text != "" {
+			m[l.Name] = l.Text
+		}
+	}
+	return m
+}()
+
+// CompareText compares the contents of a license file with the canonical
+// texts of the licenses that licensecheck found in it, as described by cov.
+// It returns the contents divided into segments that match the canonical texts
+// and segments that are extra, interspersed with segments for the words of
+// the canonical texts that the contents lack. Words are compared ignoring case
+// and punctuation. The concatenated text of the segments that are not missing
+// is the contents.
+//
+// CompareText returns nil if cov has no matches, which is the case if the
+// file was not examined by licensecheck.
+func CompareText(contents []byte, cov licensecheck.Coverage) []TextSegment {
+	if len(cov.Match) == 0 {
+		return nil
+	}
+	words := splitWords(contents)
+	// kinds holds the kind of each word of contents. Words outside of any
+	// match are extra.
+	kinds := make([]SegmentKind, len(words))
+	for i := range kinds {
+		kinds[i] = SegmentExtra
+	}
+	// missing holds the canonical words that are missing before each word of
+	// contents, and at the end.
+	missing := make([][]string, len(words)+1)
+
+	// Each match is compared with the words of contents that follow the
+	// previous match, so that text between matches, like a copyright
+	// notice, can fill the blanks of the canonical text.
+	first := 0
+	for i, m := range cov.Match {
+		last := len(words)
+		if i+1 < len(cov.Match) {
+			last = wordIndex(words, m.End)
+		}
+		matchWords := words[first:last]
+		canon := canonicalTexts[m.Name]
+		if m.IsURL || canon == "" {
+			for j := range matchWords {
+				if w := words[first+j]; w.start >= m.Start && w.end <= m.End {
+					kinds[first+j] = SegmentMatched
+				}
+			}
+		} else {
+			compareWords(canon, matchWords, kinds[first:last], missing[first:last+1])
+		}
+		first = last
+	}
+	return textSegments(contents, words, kinds, missing)
+}
+
+// A word is a word of a license text.
+type word struct {
+	text       string // lower case, or the wildcard
+	start, end int    // byte offsets of the word in the text
+	para       int    // index of the paragraph containing the word
+}
+
+// splitWords splits text into words, which are runs of letters and digits or
+// wildcards. Other characters are ignored, as are list markers like "1." and
+// "(a)", which vary in form. Blank lines separate paragraphs.
+func splitWords(text []byte) []word {
+	isWordRune := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	var (
+		words    []word
+		para     int
+		newlines int // since the last word
+	)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		if newlines >= 2 && (isWordRune(r) || r == '_') && len(words) > 0 {
+			para++
+		}
+		switch {
+		case isWordRune(r):
+			j := i + size
+			for j < len(text) {
+				r, size := utf8.DecodeRune(text[j:])
+				if !isWordRune(r) {
+					break
+				}
+				j += size
+			}
+			if !isListMarker(text, i, j) {
+				words = append(words, word{strings.ToLower(string(text[i:j])), i, j, para})
+				newlines = 0
+			}
+			i = j
+		case bytes.HasPrefix(text[i:], []byte(wildcard)):
+			j := i
+			for j < len(text) && text[j] == '_' {
+				j++
+			}
+			words = append(words, word{wildcard, i, j, para})
+			newlines = 0
+			i = j
+		default:
+			if r == '\n' {
+				newlines++
+			}
+			i += size
+		}
+	}
+	return words
+}
+
+// isListMarker reports whether text[start:end], a run of letters and digits,
+// is a list marker: a number or a single letter at the start of a line,
+// followed by a period or a closing parenthesis.
+func isListMarker(text []byte, start, end int) bool {
+	w := text[start:end]
+	if len(w) > 3 || (len(w) > 1 && bytes.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0) {
+		return false
+	}
+	if end >= len(text) || (text[end] != '.' && text[end] != ')') {
+		return false
+	}
+	i := start
+	for i > 0 && (text[i-1] == ' ' || text[i-1] == '\t' || text[i-1] == '(') {
+		i--
+	}
+	return i == 0 || text[i-1] == '\n'
+}
+
+// wordIndex returns the index of the first word in words that ends after
+// offset.
+func wordIndex(words []word, offset int) int {
+	for i, w := range words {
+		if w.end > offset {
+			return i
+		}
+	}
+	return len(words)
+}
+
+// compareWords compares a canonical license text with the words of a license
+// file, setting the kind of each file word in kinds and recording the
+// canonical text that is missing before each file word, or at the end, in
+// missing. Words that replace a wildcard in the canonical text are matched,
+// as long as they are in the same paragraph as the text before the wildcard,
+// so that a clause added after a copyright notice is not mistaken for part of
+// the notice. If the texts differ too much to be compared, all the words are
+// considered matched.
+func compareWords(canonText string, file []word, kinds []SegmentKind, missing [][]string) {
+	canon := splitWords([]byte(canonText))
+	ops := diffWords(canon, file)
+	if ops == nil {
+		for i := range kinds {
+			kinds[i] = SegmentMatched
+		}
+		return
+	}
+	ci, fi := 0, 0
+	for len(ops) > 0 {
+		if ops[0] == opEqual {
+			kinds[fi] = SegmentMatched
+			ops = ops[1:]
+			ci++
+			fi++
+			continue
+		}
+		// Process a block of consecutive insertions and deletions.
+		n := 0
+		for n < len(ops) && ops[n] != opEqual {
+			n++
+		}
+		block := ops[:n]
+		ops = ops[n:]
+		fillsBlank := false
+		c := ci
+		for _, op := range block {
+			if op == opDelete {
+				if canon[c].text == wildcard {
+					fillsBlank = true
+				}
+				c++
+			}
+		}
+		start := fi
+		// The paragraph in which the blank is filled.
+		para := -1
+		if fillsBlank {
+			if fi > 0 {
+				para = file[fi-1].para
+			} else if fi < len(file) {
+				para = file[fi].para
+			}
+		}
+		// runStart is the index of the first canonical word of the current
+		// run of missing words, or -1.
+		runStart := -1
+		endRun := func() {
+			if runStart >= 0 {
+				missing[start] = append(missing[start], canonText[canon[runStart].start:canon[ci-1].end])
+				runStart = -1
+			}
+		}
+		for _, op := range block {
+			switch op {
+			case opDelete:
+				if canon[ci].text == wildcard {
+					endRun()
+				} else if runStart < 0 {
+					runStart = ci
+				}
+				ci++
+			case opInsert:
+				if file[fi].para == para {
+					kinds[fi] = SegmentMatched
+				} else {
+					kinds[fi] = SegmentExtra
+				}
+				fi++
+			}
+		}
+		endRun()
+	}
+}
+
+// An editOp is an operation of an edit script that transforms one sequence of
+// words into another.
+type editOp int
+
+const (
+	opEqual  editOp = iota // the words are the same
+	opDelete               // a word is only in the first sequence
+	opInsert               // a word is only in the second sequence
+)
+
+// diffWords returns a shortest edit script that transforms the text of a into
+// the text of b, using the algorithm of Eugene W. Myers, "An O(ND) Difference
+// Algorithm and Its Variations". It returns nil if the script would have more
+// than maxCompareEdits insertions and deletions.
+func diffWords(a, b []word) []editOp {
+	n, m := len(a), len(b)
+	// v[k] is the furthest x reached on diagonal k = x - y. trace[d] holds
+	// v[-d-1:d+2] before step d.
+	v := map[int]int{1: 0}
+	var trace [][]int
+	for d := 0; d <= maxCompareEdits; d++ {
+		saved := make([]int, 2*d+3)
+		for k := -d - 1; k <= d+1; k++ {
+			saved[k+d+1] = v[k]
+		}
+		trace = append(trace, saved)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1] < v[k+1]) {
+				x = v[k+1]
+			} else {
+				x = v[k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x].text == b[y].text {
+				x++
+				y++
+			}
+			v[k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack returns the edit script found by diffWords, given the trace of
+// its search.
+func backtrack(trace [][]int, x, y int) []editOp {
+	var ops []editOp
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, opEqual)
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, opInsert)
+			} else {
+				ops = append(ops, opDelete)
+			}
+		}
+		x, y = prevX, prevY
+	}
+	// Reverse the operations, which were found from the end.
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// textSegments divides contents into segments according to the kinds of its
+// words and the canonical words missing before them. Characters between
+// words are in the segment of the words around them, or in a matched segment
+// if those words differ in kind.
+func textSegments(contents []byte, words []word, kinds []SegmentKind, missing [][]string) []TextSegment {
+	var segs []TextSegment
+	add := func(kind SegmentKind, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(segs); n > 0 && segs[n-1].Kind == kind {
+			segs[n-1].Text += text
+			return
+		}
+		segs = append(segs, TextSegment{Kind: kind, Text: text})
+	}
+	pos := 0
+	for i, w := range words {
+		between := SegmentMatched
+		if i > 0 && kinds[i-1] == kinds[i] && len(missing[i]) == 0 {
+			between = kinds[i]
+		}
+		add(between, string(contents[pos:w.start]))
+		if len(missing[i]) > 0 {
+			add(SegmentMissing, strings.Join(missing[i], " "))
+		}
+		add(kinds[i], string(contents[w.start:w.end]))
+		pos = w.end
+	}
+	if len(missing[len(words)]) > 0 {
+		add(SegmentMissing, strings.Join(missing[len(words)], " "))
+	}
+	add(SegmentMatched, string(contents[pos:]))
+	return segs
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/licensecheck"
+)
+
+const mitGrant = `Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated documentation files (the "Software"), to deal in the Software without restriction, including without limitation the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to permit persons to whom the Software is furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.
+`
+
+const mitWarranty = `
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+`
+
+func TestCompareText(t *testing.T) {
+	const (
+		copyright   = "Copyright (c) 2020 Jane Doe\n\n"
+		restriction = "The Software may not be used in military applications."
+	)
+	for _, test := range []struct {
+		name     string
+		contents string
+		want     []TextSegment // if nil, all contents match
+	}{
+		{
+			name:     "canonical",
+			contents: copyright + mitGrant + mitWarranty,
+		},
+		{
+			name:     "additional restriction",
+			contents: copyright + mitGrant + mitWarranty + "\n" + restriction + "\n",
+			want: []TextSegment{
+				{SegmentMatched, copyright + mitGrant + mitWarranty + "\n"},
+				{SegmentExtra, strings.TrimSuffix(restriction, ".")},
+				{SegmentMatched, ".\n"},
+			},
+		},
+		{
+			name:     "restriction after copyright",
+			contents: copyright + restriction + "\n\n" + mitGrant + mitWarranty,
+			want: []TextSegment{
+				{SegmentMatched, copyright},
+				{SegmentExtra, strings.TrimSuffix(restriction, ".")},
+				{SegmentMatched, ".\n\n" + mitGrant + mitWarranty},
+			},
+		},
+		{
+			name:     "omission",
+			contents: copyright + strings.Replace(mitGrant, "free of charge, ", "", 1) + mitWarranty,
+			want: []TextSegment{
+				{SegmentMatched, copyright + "Permission is hereby granted, "},
+				{SegmentMissing, "free of charge"},
+				{SegmentMatched, strings.TrimPrefix(strings.Replace(mitGrant, "free of charge, ", "", 1), "Permission is hereby granted, ") + mitWarranty},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, cov := DetectFile([]byte(test.contents), "LICENSE", nil)
+			got := CompareText([]byte(test.contents), cov)
+			want := test.want
+			if want == nil {
+				want = []TextSegment{{SegmentMatched, test.contents}}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+			var text strings.Builder
+			for _, s := range got {
+				if s.Kind != SegmentMissing {
+					text.WriteString(s.Text)
+				}
+			}
+			if text.String() != test.contents {
+				t.Errorf("segments do not add up to the contents:\n%s", text.String())
+			}
+		})
+	}
+}
+
+func TestCompareTextNoMatches(t *testing.T) {
+	if got := CompareText([]byte("Copyright 2020 Jane Doe"), licensecheck.Coverage{}); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	words := func(s string) []word { return splitWords([]byte(s)) }
+	for _, test := range []struct {
+		a, b string
+		want []editOp
+	}{
+		{"", "", nil},
+		{"a b c", "a b c", []editOp{opEqual, opEqual, opEqual}},
+		{"a b c", "a c", []editOp{opEqual, opDelete, opEqual}},
+		{"a c", "A, b, C.", []editOp{opEqual, opInsert, opEqual}},
+		{"a ___ c", "a x y c", []editOp{opEqual, opDelete, opInsert, opInsert, opEqual}},
+		{"1. a\n2. b", "* a\n(b) b", []editOp{opEqual, opEqual}},
+	} {
+		got := diffWords(words(test.a), words(test.b))
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("diffWords(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+
+	// Texts that differ too much are not compared.
+	if got := diffWords(words(strings.Repeat("a ", maxCompareEdits+1)), nil); got != nil {
+		t.Errorf("got %d operations, want nil", len(got))
+	}
+}
@@ -4,6 +4,12 @@
 
 package licenses
 
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
 // Files we should ignore.
 // Set of "modulePath filePath".
 var ignoreFiles = map[string]bool{
@@ -13,8 +19,61 @@ var ignoreFiles = map[string]bool{
 	"gioui.org COPYING": true,
 }
 
+// An Exception is a license file that was reviewed and found acceptable,
+// although it is not detected as such. Unlike the exceptions under the
+// exception-files directory, Exceptions are kept in the database and
+// managed through the worker.
+type Exception struct {
+	// Key identifies the contents of the file (see ExceptionKey).
+	Key string
+	// Types are the license types of the file.
+	Types []string
+	// Reviewer is the person who added the exception.
+	Reviewer string
+	// Reason says why the file is acceptable.
+	Reason string
+	// CreatedAt is the time at which the exception was added.
+	CreatedAt time.Time
+}
+
+var (
+	exceptionsMu sync.Mutex
+	// exceptions maps the keys of the Exceptions passed to SetExceptions to
+	// their types.
+	exceptions = map[string][]string{}
+)
+
+// SetExceptions arranges for license files that match one of excs to be
+// detected as having its types. The built-in exceptions take precedence.
+//
+// Like the license policy, exceptions are applied when a module is processed,
+// so modules must be reprocessed for a change of exceptions to affect them.
+func SetExceptions(excs []*Exception) {
+	m := map[string][]string{}
+	for _, e := range excs {
+		m[e.Key] = e.Types
+	}
+	exceptionsMu.Lock()
+	defer exceptionsMu.Unlock()
+	exceptions = m
+}
+
+// ExceptionKey returns the key that identifies contents in the lists of
+// exceptions: the hex-encoded SHA-256 of contents, ignoring case and
+// differences in white space.
+func ExceptionKey(contents []byte) string {
+	k := exceptionKey(contents)
+	return hex.EncodeToString(k[:])
+}
+
 // exceptionFileTypes returns the license types of the file with contents if it
 // is in the list of exceptions. Otherwise it returns nil.
 func exceptionFileTypes(contents []byte) []string {
-	return exceptionFileTypesMap[exceptionKey(contents)]
+	key := exceptionKey(contents)
+	if types := exceptionFileTypesMap[key]; types != nil {
+		return types
+	}
+	exceptionsMu.Lock()
+	defer exceptionsMu.Unlock()
+	return exceptions[hex.EncodeToString(key[:])]
 }
@@ -58,6 +58,9 @@ var maxLicenseSize uint64 = modzip.MaxLICENSE
 // Metadata holds information extracted from a license file.
 type Metadata struct {
 	// Types is the set of license types, as determined by the licensecheck package.
+	// A type may also be a normalized SPDX license expression, like
+	// "Apache-2.0 OR MIT", if the file declares one with an
+	// SPDX-License-Identifier tag.
 	Types []string
 	// FilePath is the '/'-separated path to the license file in the module zip,
 	// relative to the contents directory.
@@ -70,12 +73,16 @@ type Metadata struct {
 type License struct {
 	*Metadata
 	Contents []byte
+	// ContentsKey is the exception key of the contents (see ExceptionKey).
+	// Unlike Contents, it is kept when non-redistributable data is removed,
+	// so that the file can be matched with exceptions added later.
+	ContentsKey string
 }
 
 // RemoveNonRedistributableData methods removes the license contents
-// if the license is non-redistributable.
-func (l *License) RemoveNonRedistributableData() {
-	if !Redistributable(l.Types) {
+// if the license is non-redistributable in the module at modulePath.
+func (l *License) RemoveNonRedistributableData(modulePath string) {
+	if !CurrentPolicy().Decide(modulePath, l.Types).Redistributable {
 		l.Contents = nil
 	}
 }
@@ -213,10 +220,22 @@ type AcceptedLicenseInfo struct {
 }
 
 // AcceptedLicenses returns a sorted slice of license types that are accepted as
-// redistributable. Its result is intended to be displayed to users.
+// redistributable by the current policy. Its result is intended to be
+// displayed to users.
 func AcceptedLicenses() []AcceptedLicenseInfo {
-	var lics []AcceptedLicenseInfo
+	p := CurrentPolicy()
+	types := map[string]bool{}
 	for l := range redistributableLicenseTypes {
+		types[l] = true
+	}
+	for _, l := range p.Allow {
+		types[l] = true
+	}
+	var lics []AcceptedLicenseInfo
+	for l := range types {
+		if !p.accepts(l) {
+			continue
+		}
 		identifier := spdxIdentifierOverrides[l]
 		if identifier == "" {
 			identifier = l
@@ -307,7 +326,7 @@ func (d *Detector) PackageInfo(dir string) (isRedistributable bool, lics []*Lice
 	// redistributable. A module that is granted an exception (see DetectFiles)
 	// may have licenses that are non-redistributable.
 	ltypes := types(lics)
-	isRedistributable = d.ModuleIsRedistributable() && (len(ltypes) == 0 || Redistributable(ltypes))
+	isRedistributable = d.ModuleIsRedistributable() && (len(ltypes) == 0 || CurrentPolicy().Decide(d.modulePath, ltypes).Redistributable)
 	// A package's licenses include the ones we've already computed, as well
 	// as the module licenses.
 	return isRedistributable, append(lics, d.moduleLicenses...)
@@ -317,7 +336,7 @@ func (d *Detector) PackageInfo(dir string) (isRedistributable bool, lics []*Lice
 func (d *Detector) computeModuleInfo() {
 	// Check that all licenses in the contents directory are redistributable.
 	d.moduleLicenses = d.detectFiles(d.Files(RootFiles))
-	d.moduleRedist = Redistributable(types(d.moduleLicenses))
+	d.moduleRedist = CurrentPolicy().Decide(d.modulePath, types(d.moduleLicenses)).Redistributable
 }
 
 // computeAllLicenseInfo collects all the detected licenses in the zip and
@@ -328,8 +347,10 @@ func (d *Detector) computeAllLicenseInfo() {
 	d.allLicenses = append(d.allLicenses, d.moduleLicenses...)
 	nonRootLicenses := d.detectFiles(d.Files(NonRootFiles))
 	d.allLicenses = append(d.allLicenses, nonRootLicenses...)
+	headerLicenses := d.sourceHeaderLicenses()
+	d.allLicenses = append(d.allLicenses, headerLicenses...)
 	d.licsByDir = map[string][]*License{}
-	for _, l := range nonRootLicenses {
+	for _, l := range append(nonRootLicenses, headerLicenses...) {
 		prefix := path.Dir(l.FilePath)
 		d.licsByDir[prefix] = append(d.licsByDir[prefix], l)
 	}
@@ -431,7 +452,8 @@ func (d *Detector) detectFiles(files []*zip.File) []*License {
 				FilePath: strings.TrimPrefix(f.Name, prefix),
 				Coverage: cov,
 			},
-			Contents: bytes,
+			Contents:    bytes,
+			ContentsKey: ExceptionKey(bytes),
 		})
 	}
 	return licenses
@@ -440,6 +462,12 @@ func (d *Detector) detectFiles(files []*zip.File) []*License {
 // DetectFile return the set of license types for the given file contents. It
 // also returns the licensecheck coverage information. The filename is used
 // solely for logging.
+//
+// If the file has an SPDX-License-Identifier tag near its start, the tag's
+// expression is used when licensecheck finds no license text, or finds only
+// the licenses that the expression names. Otherwise the types found by
+// licensecheck, including unknown text, are added to those of the expression,
+// so that a tag cannot hide the rest of the file.
 func DetectFile(contents []byte, filename string, logf func(string, ...interface{})) ([]string, licensecheck.Coverage) {
 	if logf == nil {
 		logf = func(string, ...interface{}) {}
@@ -448,6 +476,41 @@ func DetectFile(contents []byte, filename string, logf func(string, ...interface
 		logf("%s is an exception", filename)
 		return types, licensecheck.Coverage{}
 	}
+	types, cov := detectText(contents, filename, logf)
+	e := spdxHeaderExpression(contents)
+	if e == nil {
+		return types, cov
+	}
+	logf("%s has SPDX license expression %q", filename, e)
+	exprTypes := typesForExpression(e)
+	if len(cov.Match) == 0 {
+		return exprTypes, cov
+	}
+	named := map[string]bool{}
+	for _, id := range e.IDs() {
+		named[typeForSPDX(id)] = true
+	}
+	all := map[string]bool{}
+	agree := true
+	for _, t := range types {
+		all[t] = true
+		if !named[t] {
+			agree = false
+		}
+	}
+	if agree {
+		return exprTypes, cov
+	}
+	logf("%s has license text other than its SPDX expression: %v", filename, types)
+	for _, t := range exprTypes {
+		all[t] = true
+	}
+	return setToSortedSlice(all), cov
+}
+
+// detectText returns the license types that licensecheck finds in contents,
+// and the coverage information.
+func detectText(contents []byte, filename string, logf func(string, ...interface{})) ([]string, licensecheck.Coverage) {
 	cov, ok := checker.Cover(contents, licensecheck.Options{})
 	if !ok {
 		logf("%s checker.Cover failed, skipping", filename)
@@ -471,17 +534,10 @@ func DetectFile(contents []byte, filename string, logf func(string, ...interface
 }
 
 // Redistributable reports whether the set of license types establishes that a
-// module or package is redistributable.
+// module or package is redistributable under the current policy, ignoring the
+// policy's module overrides.
 func Redistributable(licenseTypes []string) bool {
-	if len(licenseTypes) == 0 {
-		return false
-	}
-	for _, t := range licenseTypes {
-		if !redistributableLicenseTypes[t] && !ignorableLicenseTypes[t] {
-			return false
-		}
-	}
-	return true
+	return CurrentPolicy().Decide("", licenseTypes).Redistributable
 }
 
 var canonicalNames = map[string]string{
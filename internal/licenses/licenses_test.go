@@ -691,6 +691,27 @@ func TestExceptions(t *testing.T) {
 	}
 }
 
+func TestSetExceptions(t *testing.T) {
+	const contents = "Do what\nyou want with this code."
+	defer SetExceptions(nil)
+	SetExceptions([]*Exception{{
+		Key:   ExceptionKey([]byte("do WHAT you want with this code.")),
+		Types: []string{"Unlicense"},
+	}})
+	if got, want := exceptionFileTypes([]byte(contents)), []string{"Unlicense"}; !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	types, _ := DetectFile([]byte(contents), "LICENSE", nil)
+	if want := []string{"Unlicense"}; !cmp.Equal(types, want) {
+		t.Errorf("DetectFile: got %v, want %v", types, want)
+	}
+
+	SetExceptions(nil)
+	if got := exceptionFileTypes([]byte(contents)); got != nil {
+		t.Errorf("after removing the exception: got %v, want nil", got)
+	}
+}
+
 // newZipReader creates an in-memory zip of the given contents and returns a reader to it.
 func newZipReader(t *testing.T, contentsDir string, contents map[string]string) *zip.Reader {
 	var buf bytes.Buffer
@@ -0,0 +1,217 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// A Policy decides which licenses allow the contents of a module to be
+// redistributed. The zero Policy accepts the license types described by
+// AcceptedLicenses, and nothing else.
+type Policy struct {
+	// Allow lists license types, as reported by licensecheck (for example
+	// "MIT" or "GPL2"), that are accepted in addition to the default ones.
+	Allow []string
+
+	// Deny lists license types that are not accepted, even if they are
+	// accepted by default.
+	Deny []string
+
+	// Review lists license types that are awaiting review. They are not
+	// accepted until they are moved to Allow.
+	Review []string
+
+	// Modules overrides the decision for the modules whose paths are under
+	// some prefixes, regardless of their licenses. If more than one prefix
+	// matches a module path, the longest one is used.
+	Modules []*ModuleOverride
+}
+
+// A ModuleOverride decides whether the modules whose paths are equal to or
+// below Prefix are redistributable.
+type ModuleOverride struct {
+	Prefix          string
+	Redistributable bool
+	// Reason, if non-empty, is shown to users along with the decision.
+	Reason string
+}
+
+// A Decision is the result of applying a Policy to the licenses of a module
+// or package.
+type Decision struct {
+	Redistributable bool
+	// Explanation is a sentence or two describing the decision, for display
+	// to users.
+	Explanation string
+}
+
+// policy holds the *Policy used by the functions of this package.
+var policy atomic.Value
+
+func init() {
+	policy.Store(&Policy{})
+}
+
+// SetPolicy arranges for the functions of this package to use p. It returns
+// an error, and leaves the policy unchanged, if p is invalid.
+//
+// SetPolicy may be called at any time. Redistributability is determined when a
+// module is processed, so modules must be reprocessed for a change of policy
+// to affect them.
+func SetPolicy(p *Policy) error {
+	if p == nil {
+		p = &Policy{}
+	}
+	lists := map[string]string{}
+	for name, types := range map[string][]string{"Allow": p.Allow, "Deny": p.Deny, "Review": p.Review} {
+		for _, t := range types {
+			if other, ok := lists[t]; ok && other != name {
+				return fmt.Errorf("license policy: %q is in both %s and %s", t, other, name)
+			}
+			lists[t] = name
+		}
+	}
+	for _, o := range p.Modules {
+		if strings.TrimSuffix(o.Prefix, "/") == "" {
+			return fmt.Errorf("license policy: module override with empty prefix")
+		}
+	}
+	policy.Store(p)
+	return nil
+}
+
+// CurrentPolicy returns the policy used by the functions of this package.
+func CurrentPolicy() *Policy {
+	return policy.Load().(*Policy)
+}
+
+// Decide applies p to licenseTypes, the types of the licenses of a module or
+// package in the module modulePath. A license type that is an SPDX license
+// expression is accepted if its licenses are, following the expression's AND
+// and OR operators.
+func (p *Policy) Decide(modulePath string, licenseTypes []string) Decision {
+	if o := p.moduleOverride(modulePath); o != nil {
+		what := "redistributable"
+		if !o.Redistributable {
+			what = "not redistributable"
+		}
+		exp := fmt.Sprintf("The license policy treats modules under %s as %s, regardless of their licenses.", o.Prefix, what)
+		if o.Reason != "" {
+			exp += " " + o.Reason
+		}
+		return Decision{Redistributable: o.Redistributable, Explanation: exp}
+	}
+	if len(licenseTypes) == 0 {
+		return Decision{Explanation: "No license was detected."}
+	}
+	var accepted, denied, review, unaccepted []string
+	seen := map[string]bool{}
+	for _, t := range licenseTypes {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		switch {
+		case isCompoundType(t):
+			if e, err := ParseExpression(t); err == nil && e.eval(p.acceptsSPDX) {
+				accepted = append(accepted, t)
+			} else {
+				unaccepted = append(unaccepted, t)
+			}
+		case contains(p.Deny, t):
+			denied = append(denied, t)
+		case contains(p.Review, t):
+			review = append(review, t)
+		case contains(p.Allow, t) || redistributableLicenseTypes[t]:
+			accepted = append(accepted, t)
+		case ignorableLicenseTypes[t]:
+		default:
+			unaccepted = append(unaccepted, t)
+		}
+	}
+	switch {
+	case len(denied) > 0:
+		return Decision{Explanation: fmt.Sprintf("The license policy does not allow %s.", listTypes(denied))}
+	case len(review) > 0:
+		return Decision{Explanation: fmt.Sprintf("%s awaiting review under the license policy.", listTypesWithVerb(review))}
+	case len(unaccepted) > 0:
+		return Decision{Explanation: fmt.Sprintf("%s not accepted by the license policy.", listTypesWithVerb(unaccepted))}
+	case len(accepted) == 0:
+		return Decision{Redistributable: true, Explanation: "The detected licenses place no restrictions on redistribution."}
+	default:
+		return Decision{Redistributable: true, Explanation: fmt.Sprintf("%s accepted by the license policy.", listTypesWithVerb(accepted))}
+	}
+}
+
+// moduleOverride returns the override with the longest prefix that matches
+// modulePath, or nil if there is none.
+func (p *Policy) moduleOverride(modulePath string) *ModuleOverride {
+	var best *ModuleOverride
+	for _, o := range p.Modules {
+		prefix := strings.TrimSuffix(o.Prefix, "/")
+		if modulePath != prefix && !strings.HasPrefix(modulePath, prefix+"/") {
+			continue
+		}
+		if best == nil || len(prefix) > len(strings.TrimSuffix(best.Prefix, "/")) {
+			best = o
+		}
+	}
+	return best
+}
+
+// accepts reports whether p accepts the license type t, ignoring module
+// overrides.
+func (p *Policy) accepts(t string) bool {
+	if contains(p.Deny, t) || contains(p.Review, t) {
+		return false
+	}
+	return contains(p.Allow, t) || redistributableLicenseTypes[t]
+}
+
+// acceptsSPDX reports whether p accepts the license with the SPDX identifier
+// id. The policy may name the license by its identifier or by its license
+// type.
+func (p *Policy) acceptsSPDX(id string) bool {
+	t := typeForSPDX(id)
+	if t == id {
+		return p.accepts(t)
+	}
+	if contains(p.Deny, id) || contains(p.Review, id) {
+		return false
+	}
+	return p.accepts(t) || p.accepts(id)
+}
+
+func contains(s []string, t string) bool {
+	for _, e := range s {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// listTypes returns the license types in a form suitable for a sentence, like
+// "GPL2 and GPL3".
+func listTypes(types []string) string {
+	types = append([]string(nil), types...)
+	sort.Strings(types)
+	if len(types) == 1 {
+		return types[0]
+	}
+	return strings.Join(types[:len(types)-1], ", ") + " and " + types[len(types)-1]
+}
+
+// listTypesWithVerb is like listTypes, but adds "is" or "are".
+func listTypesWithVerb(types []string) string {
+	if len(types) == 1 {
+		return types[0] + " is"
+	}
+	return listTypes(types) + " are"
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecide(t *testing.T) {
+	p := &Policy{
+		Allow:  []string{"SSPL"},
+		Deny:   []string{"AGPL-3.0"},
+		Review: []string{"GPL3"},
+		Modules: []*ModuleOverride{
+			{Prefix: "example.com/org", Redistributable: true, Reason: "Our own code."},
+			{Prefix: "example.com/org/vendored", Redistributable: false},
+		},
+	}
+	for _, test := range []struct {
+		modulePath string
+		types      []string
+		want       Decision
+	}{
+		{
+			"example.com/m", []string{"MIT", "SSPL"},
+			Decision{true, "MIT and SSPL are accepted by the license policy."},
+		},
+		{
+			"example.com/m", []string{"MIT", "AGPL-3.0"},
+			Decision{false, "The license policy does not allow AGPL-3.0."},
+		},
+		{
+			"example.com/m", []string{"GPL3"},
+			Decision{false, "GPL3 is awaiting review under the license policy."},
+		},
+		{
+			"example.com/m", []string{"UNKNOWN", "MIT"},
+			Decision{false, "UNKNOWN is not accepted by the license policy."},
+		},
+		{
+			"example.com/m", []string{"CC-Notice"},
+			Decision{true, "The detected licenses place no restrictions on redistribution."},
+		},
+		{
+			"example.com/m", []string{"MIT OR AGPL-3.0-only"},
+			Decision{true, "MIT OR AGPL-3.0-only is accepted by the license policy."},
+		},
+		{
+			"example.com/m", []string{"MIT AND GPL-3.0-only"},
+			Decision{false, "MIT AND GPL-3.0-only is not accepted by the license policy."},
+		},
+		{
+			"example.com/m", nil,
+			Decision{false, "No license was detected."},
+		},
+		{
+			"example.com/org/m", nil,
+			Decision{true, "The license policy treats modules under example.com/org as redistributable, regardless of their licenses. Our own code."},
+		},
+		{
+			"example.com/org/vendored/x", []string{"MIT"},
+			Decision{false, "The license policy treats modules under example.com/org/vendored as not redistributable, regardless of their licenses."},
+		},
+		{
+			"example.com/organization", nil,
+			Decision{false, "No license was detected."},
+		},
+	} {
+		got := p.Decide(test.modulePath, test.types)
+		if got != test.want {
+			t.Errorf("Decide(%q, %v) = %+v, want %+v", test.modulePath, test.types, got, test.want)
+		}
+	}
+}
+
+func TestSetPolicy(t *testing.T) {
+	defer SetPolicy(nil)
+
+	for _, bad := range []*Policy{
+		{Allow: []string{"MIT"}, Deny: []string{"MIT"}},
+		{Review: []string{"GPL2"}, Deny: []string{"GPL2"}},
+		{Modules: []*ModuleOverride{{Prefix: "/", Redistributable: true}}},
+	} {
+		if err := SetPolicy(bad); err == nil {
+			t.Errorf("SetPolicy(%+v) succeeded, want error", bad)
+		}
+	}
+
+	if err := SetPolicy(&Policy{
+		Allow:   []string{"SSPL"},
+		Deny:    []string{"MIT"},
+		Modules: []*ModuleOverride{{Prefix: "example.com/org", Redistributable: true}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if Redistributable([]string{"MIT"}) {
+		t.Error("MIT is redistributable, want denied")
+	}
+	if !Redistributable([]string{"SSPL"}) {
+		t.Error("SSPL is not redistributable, want allowed")
+	}
+	for _, l := range AcceptedLicenses() {
+		if l.Name == "MIT" {
+			t.Error("MIT is in AcceptedLicenses")
+		}
+	}
+
+	// The module override applies to the detector and to license contents.
+	zr := newZipReader(t, "example.com/org/m@v1.0.0", map[string]string{"LICENSE": "not a license"})
+	d := NewDetector("example.com/org/m", "v1.0.0", zr, nil)
+	if !d.ModuleIsRedistributable() {
+		t.Error("module under example.com/org is not redistributable")
+	}
+	if redist, _ := d.PackageInfo("p"); !redist {
+		t.Error("package under example.com/org is not redistributable")
+	}
+	lic := &License{Metadata: &Metadata{Types: []string{"UNKNOWN"}}, Contents: []byte("text")}
+	lic.RemoveNonRedistributableData("example.com/org/m")
+	if diff := cmp.Diff([]byte("text"), lic.Contents); diff != "" {
+		t.Errorf("contents mismatch (-want +got):\n%s", diff)
+	}
+	lic.RemoveNonRedistributableData("example.com/other")
+	if lic.Contents != nil {
+		t.Errorf("got contents %q for other module, want nil", lic.Contents)
+	}
+}
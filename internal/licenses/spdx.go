@@ -0,0 +1,417 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// An Expression is a parsed SPDX license expression, like
+// "MIT OR Apache-2.0". See
+// https://spdx.github.io/spdx-spec/appendix-IV-SPDX-license-expressions/.
+type Expression struct {
+	// Op is "AND" or "OR" for a compound expression, and empty for a single
+	// license.
+	Op string
+	// Args are the operands of a compound expression.
+	Args []*Expression
+	// ID is the license identifier of a single license, possibly ending in
+	// "+".
+	ID string
+	// Exception is the exception of a single license given with WITH, if any.
+	Exception string
+}
+
+// ParseExpression parses an SPDX license expression. Operators may be in any
+// case. Known license identifiers are put in their canonical case.
+func ParseExpression(s string) (_ *Expression, err error) {
+	p := &exprParser{toks: tokenizeExpression(s)}
+	if len(p.toks) == 0 {
+		return nil, errors.New("empty license expression")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("license expression %q: %v", s, err)
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("license expression %q: unexpected %q", s, p.toks[p.pos])
+	}
+	return e, nil
+}
+
+// String returns the normalized form of e, with upper-case operators and
+// only the parentheses that are needed.
+func (e *Expression) String() string {
+	if e.Op == "" {
+		if e.Exception != "" {
+			return e.ID + " WITH " + e.Exception
+		}
+		return e.ID
+	}
+	var args []string
+	for _, a := range e.Args {
+		s := a.String()
+		if e.Op == "AND" && a.Op == "OR" {
+			s = "(" + s + ")"
+		}
+		args = append(args, s)
+	}
+	return strings.Join(args, " "+e.Op+" ")
+}
+
+// IDs returns the license identifiers in e, in order of appearance.
+func (e *Expression) IDs() []string {
+	if e.Op == "" {
+		return []string{e.ID}
+	}
+	var ids []string
+	for _, a := range e.Args {
+		ids = append(ids, a.IDs()...)
+	}
+	return ids
+}
+
+// eval reports whether e is satisfied when the licenses for which accept
+// returns true are acceptable. An exception only grants additional
+// permissions, so it is ignored.
+func (e *Expression) eval(accept func(id string) bool) bool {
+	switch e.Op {
+	case "AND":
+		for _, a := range e.Args {
+			if !a.eval(accept) {
+				return false
+			}
+		}
+		return true
+	case "OR":
+		for _, a := range e.Args {
+			if a.eval(accept) {
+				return true
+			}
+		}
+		return false
+	default:
+		return accept(e.ID)
+	}
+}
+
+type exprParser struct {
+	toks []string
+	pos  int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *exprParser) parseOr() (*Expression, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (*Expression, error) {
+	return p.parseBinary("AND", p.parseLicense)
+}
+
+// parseBinary parses a sequence of operands separated by op, flattening
+// nested uses of the same operator.
+func (p *exprParser) parseBinary(op string, operand func() (*Expression, error)) (*Expression, error) {
+	var args []*Expression
+	for {
+		e, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == op {
+			args = append(args, e.Args...)
+		} else {
+			args = append(args, e)
+		}
+		if !strings.EqualFold(p.peek(), op) {
+			break
+		}
+		p.next()
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &Expression{Op: op, Args: args}, nil
+}
+
+func (p *exprParser) parseLicense() (*Expression, error) {
+	t := p.next()
+	switch {
+	case t == "":
+		return nil, errors.New("unexpected end")
+	case t == "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, errors.New("missing )")
+		}
+		return e, nil
+	case t == ")" || isExpressionOperator(t):
+		return nil, fmt.Errorf("unexpected %q", t)
+	}
+	e := &Expression{ID: canonicalSPDX(t)}
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.next()
+		x := p.next()
+		if x == "" || x == "(" || x == ")" || isExpressionOperator(x) {
+			return nil, errors.New("missing exception after WITH")
+		}
+		e.Exception = x
+	}
+	return e, nil
+}
+
+func isExpressionOperator(t string) bool {
+	return strings.EqualFold(t, "AND") || strings.EqualFold(t, "OR") || strings.EqualFold(t, "WITH")
+}
+
+func tokenizeExpression(s string) []string {
+	s = strings.ReplaceAll(s, "(", " ( ")
+	s = strings.ReplaceAll(s, ")", " ) ")
+	return strings.Fields(s)
+}
+
+// knownSPDX maps the lower-case form of known license identifiers to their
+// canonical form.
+var knownSPDX = map[string]string{}
+
+func init() {
+	add := func(id string) { knownSPDX[strings.ToLower(id)] = id }
+	for _, l := range licensecheck.BuiltinLicenses() {
+		add(l.Name)
+	}
+	for t := range redistributableLicenseTypes {
+		add(t)
+	}
+	for _, id := range spdxIdentifierOverrides {
+		add(id)
+		add(id + "-only")
+		add(id + "-or-later")
+	}
+	for _, id := range []string{"AGPL-3.0", "LGPL-2.1", "LGPL-3.0"} {
+		add(id + "-only")
+		add(id + "-or-later")
+	}
+}
+
+// canonicalSPDX returns id in its canonical case, if it is known.
+func canonicalSPDX(id string) string {
+	plus := strings.HasSuffix(id, "+")
+	if c, ok := knownSPDX[strings.ToLower(strings.TrimSuffix(id, "+"))]; ok {
+		id = c
+		if plus {
+			id += "+"
+		}
+	}
+	return id
+}
+
+// typeForSPDX returns the license type, as used by licensecheck and the
+// license policy, for an SPDX license identifier.
+func typeForSPDX(id string) string {
+	id = strings.TrimSuffix(id, "+")
+	id = strings.TrimSuffix(id, "-only")
+	id = strings.TrimSuffix(id, "-or-later")
+	for t, s := range spdxIdentifierOverrides {
+		if s == id {
+			return t
+		}
+	}
+	return id
+}
+
+// isCompoundType reports whether the license type t is a compound SPDX
+// license expression rather than a single license type.
+func isCompoundType(t string) bool {
+	return strings.Contains(t, " ")
+}
+
+// typesForExpression returns the license types for e. A single license is
+// reported as its license type; a compound expression is reported as its
+// normalized string, so that the expression is stored and displayed along
+// with other license types, and evaluated by the license policy.
+func typesForExpression(e *Expression) []string {
+	if e.Op == "" && e.Exception == "" {
+		return []string{typeForSPDX(e.ID)}
+	}
+	return []string{e.String()}
+}
+
+// maxSPDXHeaderLines is the number of lines at the start of a file that are
+// searched for an SPDX-License-Identifier tag.
+const maxSPDXHeaderLines = 20
+
+const spdxTag = "SPDX-License-Identifier:"
+
+// spdxHeaderExpression returns the license expression in the
+// SPDX-License-Identifier tag near the start of contents. It returns nil if
+// there is none, or if it cannot be parsed.
+func spdxHeaderExpression(contents []byte) *Expression {
+	s := bufio.NewScanner(bytes.NewReader(contents))
+	for i := 0; i < maxSPDXHeaderLines && s.Scan(); i++ {
+		line := s.Text()
+		j := strings.Index(line, spdxTag)
+		if j < 0 {
+			continue
+		}
+		line = strings.TrimSpace(line[j+len(spdxTag):])
+		// Remove the end of a block comment.
+		for _, end := range []string{"*/", "-->"} {
+			line = strings.TrimSpace(strings.TrimSuffix(line, end))
+		}
+		e, err := ParseExpression(line)
+		if err != nil {
+			return nil
+		}
+		return e
+	}
+	return nil
+}
+
+// maxSPDXHeaderSize is the number of bytes at the start of a source file that
+// are read when looking for an SPDX-License-Identifier tag.
+const maxSPDXHeaderSize = 4096
+
+// sourceHeaderLicenses returns a License for each distinct SPDX license
+// expression in the headers of the Go files of each directory of the module.
+// The License's FilePath is the first file in the directory with the
+// expression. Expressions that only name licenses of the module add nothing,
+// and are skipped.
+func (d *Detector) sourceHeaderLicenses() []*License {
+	prefix := pathPrefix(contentsDir(d.modulePath, d.version))
+	moduleTypes := map[string]bool{}
+	for _, t := range types(d.moduleLicenses) {
+		moduleTypes[t] = true
+	}
+	seen := map[string]bool{} // directory and expression
+	var lics []*License
+	for _, f := range d.zr.File {
+		name := strings.TrimPrefix(f.Name, prefix)
+		if !strings.HasPrefix(f.Name, prefix) || !strings.HasSuffix(name, ".go") || isVendoredFile(name) || ignoredSourceDir(path.Dir(name)) {
+			continue
+		}
+		header, err := readZipFileHeader(f, maxSPDXHeaderSize)
+		if err != nil {
+			d.logf("reading zip file %s: %v", f.Name, err)
+			continue
+		}
+		e := spdxHeaderExpression(header)
+		if e == nil {
+			continue
+		}
+		if coveredBy(e, moduleTypes) {
+			continue
+		}
+		key := path.Dir(name) + " " + e.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		lics = append(lics, &License{
+			Metadata: &Metadata{
+				Types:    typesForExpression(e),
+				FilePath: name,
+			},
+			Contents: []byte(spdxTag + " " + e.String() + "\n"),
+		})
+	}
+	return lics
+}
+
+// ignoredSourceDir reports whether the Go files in dir, relative to the module
+// root, are not part of any package: those under testdata directories, and
+// under directories whose names begin with "." or "_".
+func ignoredSourceDir(dir string) bool {
+	if dir == "." {
+		return false
+	}
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "testdata" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// coveredBy reports whether all the licenses of e are among types.
+func coveredBy(e *Expression, types map[string]bool) bool {
+	for _, id := range e.IDs() {
+		if !types[typeForSPDX(id)] && !types[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// readZipFileHeader reads at most n bytes from the start of f.
+func readZipFileHeader(f *zip.File, n int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(io.LimitReader(rc, n))
+}
+
+// nonSPDXTypes are license types reported by licensecheck that have no SPDX
+// identifier.
+var nonSPDXTypes = map[string]bool{
+	unknownLicenseType: true,
+	"CommonsClause":    true,
+}
+
+// SPDXExpression returns an SPDX license expression for licenseTypes, the
+// types of licenses that all apply to some content. It returns the empty
+// string if some type has no SPDX identifier. Types that are not licenses,
+// like notices, are omitted.
+func SPDXExpression(licenseTypes []string) string {
+	var args []string
+	seen := map[string]bool{}
+	for _, t := range licenseTypes {
+		if ignorableLicenseTypes[t] || seen[t] {
+			continue
+		}
+		seen[t] = true
+		if nonSPDXTypes[t] {
+			return ""
+		}
+		if isCompoundType(t) {
+			if len(licenseTypes) > 1 && strings.Contains(t, " OR ") {
+				t = "(" + t + ")"
+			}
+			args = append(args, t)
+			continue
+		}
+		if id := spdxIdentifierOverrides[t]; id != "" {
+			t = id
+		}
+		args = append(args, t)
+	}
+	return strings.Join(args, " AND ")
+}
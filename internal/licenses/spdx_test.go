@@ -0,0 +1,177 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseExpression(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"MIT", "MIT"},
+		{"mit or apache-2.0", "MIT OR Apache-2.0"},
+		{"(MIT OR Apache-2.0)", "MIT OR Apache-2.0"},
+		{"MIT AND (BSD-3-Clause OR Apache-2.0)", "MIT AND (BSD-3-Clause OR Apache-2.0)"},
+		{"(MIT AND BSD-3-Clause) OR Apache-2.0", "MIT AND BSD-3-Clause OR Apache-2.0"},
+		{"MIT OR (Apache-2.0 OR BSD-2-Clause)", "MIT OR Apache-2.0 OR BSD-2-Clause"},
+		{"gpl-2.0-only with Classpath-exception-2.0", "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{"GPL-2.0+", "GPL-2.0+"},
+		{"LicenseRef-Custom", "LicenseRef-Custom"},
+	} {
+		e, err := ParseExpression(test.in)
+		if err != nil {
+			t.Errorf("ParseExpression(%q): %v", test.in, err)
+			continue
+		}
+		if got := e.String(); got != test.want {
+			t.Errorf("ParseExpression(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+
+	for _, bad := range []string{"", "MIT OR", "(MIT", "MIT)", "AND MIT", "MIT WITH", "MIT Apache-2.0"} {
+		if _, err := ParseExpression(bad); err == nil {
+			t.Errorf("ParseExpression(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestEvalExpression(t *testing.T) {
+	p := &Policy{Deny: []string{"GPL2"}}
+	for _, test := range []struct {
+		in   string
+		want bool
+	}{
+		{"MIT OR GPL-2.0-only", true},
+		{"MIT AND GPL-2.0-or-later", false},
+		{"MIT AND (GPL-2.0-only OR Apache-2.0)", true},
+		{"LicenseRef-Custom OR BSD-3-Clause", true},
+		{"LicenseRef-Custom AND BSD-3-Clause", false},
+		{"0BSD AND Apache-2.0 WITH LLVM-exception", true},
+	} {
+		e, err := ParseExpression(test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := e.eval(p.acceptsSPDX); got != test.want {
+			t.Errorf("%q: got %t, want %t", test.in, got, test.want)
+		}
+	}
+}
+
+func TestDetectFileSPDX(t *testing.T) {
+	for _, test := range []struct {
+		contents string
+		want     []string
+	}{
+		{"// SPDX-License-Identifier: MIT\n\npackage p\n", []string{"MIT"}},
+		{"// SPDX-License-Identifier: GPL-2.0-or-later\n", []string{"GPL2"}},
+		{"/* SPDX-License-Identifier: apache-2.0 or mit */\n", []string{"Apache-2.0 OR MIT"}},
+		{"# SPDX-License-Identifier: (MIT OR Apache-2.0) AND BSD-3-Clause\n", []string{"(MIT OR Apache-2.0) AND BSD-3-Clause"}},
+		{"// SPDX-License-Identifier: MIT OR\n", []string{unknownLicenseType}},
+	} {
+		got, _ := DetectFile([]byte(test.contents), "f", nil)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%q: mismatch (-want +got):\n%s", test.contents, diff)
+		}
+	}
+}
+
+func TestDetectFileSPDXWithText(t *testing.T) {
+	const (
+		header = "SPDX-License-Identifier: MIT\n\n"
+		clause = `
+Notwithstanding the above, this Software is proprietary to Example Corp. You
+may not copy, modify, merge, publish, distribute, sublicense or sell copies of
+the Software, or permit any third party to do so, without the prior written
+consent of Example Corp. Any use of the Software not expressly permitted by a
+separate written agreement with Example Corp is prohibited.
+`
+	)
+	for _, test := range []struct {
+		name, contents string
+		want           []string
+	}{
+		{"agrees", header + mitLicense, []string{"MIT"}},
+		{"other license", header + bsd0License, []string{"BSD-0-Clause", "MIT"}},
+		{"proprietary clause", header + mitLicense + clause, []string{"MIT", unknownLicenseType}},
+	} {
+		got, _ := DetectFile([]byte(test.contents), "LICENSE", nil)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+
+	// The tag cannot make the file with the proprietary clause
+	// redistributable.
+	got, _ := DetectFile([]byte(header+mitLicense+clause), "LICENSE", nil)
+	if Redistributable(got) {
+		t.Errorf("MIT tag, MIT text and a proprietary clause: %v is redistributable", got)
+	}
+}
+
+func TestSourceHeaderLicenses(t *testing.T) {
+	zr := newZipReader(t, "example.com/m@v1.0.0", map[string]string{
+		"LICENSE":           mitLicense,
+		"a.go":              "// SPDX-License-Identifier: MIT\n\npackage m\n",
+		"dual/a.go":         "// SPDX-License-Identifier: MIT OR GPL-2.0-only\n\npackage dual\n",
+		"dual/b.go":         "// SPDX-License-Identifier: mit or gpl-2.0-only\n\npackage dual\n",
+		"gpl/a.go":          "// SPDX-License-Identifier: GPL-3.0-only\n\npackage gpl\n",
+		"gpl/testdata/x.go": "// SPDX-License-Identifier: AGPL-3.0-only\n",
+		"priv/a.go":         "// SPDX-License-Identifier: LicenseRef-Proprietary\n\npackage priv\n",
+	})
+	d := NewDetector("example.com/m", "v1.0.0", zr, nil)
+	var got []string
+	for _, l := range d.AllLicenses() {
+		got = append(got, path.Dir(l.FilePath)+": "+l.Types[0])
+	}
+	sort.Strings(got)
+	want := []string{
+		".: MIT",
+		"dual: MIT OR GPL-2.0-only",
+		"gpl: GPL3",
+		"priv: LicenseRef-Proprietary",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("AllLicenses mismatch (-want +got):\n%s", diff)
+	}
+	for _, test := range []struct {
+		dir  string
+		want bool
+	}{
+		{".", true},
+		{"dual", true},
+		{"gpl", true},
+		{"priv", false},
+	} {
+		if got, _ := d.PackageInfo(test.dir); got != test.want {
+			t.Errorf("PackageInfo(%q) redistributable = %t, want %t", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestSPDXExpression(t *testing.T) {
+	for _, test := range []struct {
+		types []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"MIT"}, "MIT"},
+		{[]string{"BSD-0-Clause", "GPL2"}, "0BSD AND GPL-2.0"},
+		{[]string{"Apache-2.0", "GooglePatentClause"}, "Apache-2.0"},
+		{[]string{"MIT OR Apache-2.0"}, "MIT OR Apache-2.0"},
+		{[]string{"MIT OR Apache-2.0", "BSD-3-Clause"}, "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{[]string{"MIT", "UNKNOWN"}, ""},
+	} {
+		if got := SPDXExpression(test.types); got != test.want {
+			t.Errorf("SPDXExpression(%q) = %q, want %q", test.types, got, test.want)
+		}
+	}
+}
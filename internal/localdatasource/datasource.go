@@ -19,14 +19,11 @@ import (
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/fetch"
-	"golang.org/x/pkgsite/internal/source"
 )
 
 // DataSource implements an in-memory internal.DataSource used to display documentation
 // locally. DataSource is not backed by a database or a proxy instance.
 type DataSource struct {
-	sourceClient *source.Client
-
 	mu            sync.Mutex
 	loadedModules map[string]*internal.Module
 }
@@ -64,7 +61,7 @@ func (ds *DataSource) LoadFromGOPATH(ctx context.Context, importPath string) (er
 // fetch fetches a module using FetchLocalModule and adds it to the datasource.
 // If the fetching fails, an error is returned.
 func (ds *DataSource) fetch(ctx context.Context, modulePath, localPath string) error {
-	fr := fetch.FetchLocalModule(ctx, modulePath, localPath, ds.sourceClient)
+	fr := fetch.FetchLocalModule(ctx, modulePath, localPath)
 	if fr.Error != nil {
 		return fr.Error
 	}
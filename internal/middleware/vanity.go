@@ -0,0 +1,77 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+// VanityImports answers requests to the hosts of vanity import paths, so that
+// the server can act as the vanity domain for the modules described by
+// imports. docsURL is the base URL of the documentation pages, like
+// "https://pkg.example.com"; it should be on a different host from the vanity
+// import paths.
+//
+// A request whose host and path form an import path under one of the imports
+// is answered as follows. If it has the query parameter go-get=1, as sent by
+// the go command, the response is a page with the go-import and go-source meta
+// tags for the import path. Otherwise the request is from a browser, and it is
+// redirected to the documentation for the import path at docsURL; if docsURL
+// is empty, the browser is served the same page as the go command. All other
+// requests are passed to h.
+func VanityImports(imports []*source.VanityImport, docsURL string) Middleware {
+	docsURL = strings.TrimSuffix(docsURL, "/")
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			if hostname, _, err := net.SplitHostPort(host); err == nil {
+				host = hostname
+			}
+			// Paths that begin with the host are requests for documentation
+			// pages, like the ones browsers are redirected to below.
+			if len(imports) == 0 || strings.HasPrefix(r.URL.Path+"/", "/"+host+"/") {
+				h.ServeHTTP(w, r)
+				return
+			}
+			importPath := strings.TrimSuffix(host+r.URL.Path, "/")
+			for _, v := range imports {
+				goImport, goSource, ok := v.MetaTags(importPath)
+				if !ok {
+					continue
+				}
+				if docsURL != "" && r.FormValue("go-get") != "1" {
+					http.Redirect(w, r, docsURL+"/"+importPath, http.StatusFound)
+					return
+				}
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				if _, err := fmt.Fprint(w, vanityPage(importPath, goImport, goSource)); err != nil {
+					log.Errorf(r.Context(), "VanityImports, writing: %v", err)
+				}
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// vanityPage returns an HTML page with the given go-import and go-source meta
+// tag contents. goSource may be empty.
+func vanityPage(importPath, goImport, goSource string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	fmt.Fprintf(&b, "<meta name=\"go-import\" content=\"%s\">\n", html.EscapeString(goImport))
+	if goSource != "" {
+		fmt.Fprintf(&b, "<meta name=\"go-source\" content=\"%s\">\n", html.EscapeString(goSource))
+	}
+	fmt.Fprintf(&b, "</head>\n<body>\ngo get %s\n</body>\n</html>\n", html.EscapeString(importPath))
+	return b.String()
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/pkgsite/internal/source"
+)
+
+func TestVanityImports(t *testing.T) {
+	v, err := source.ParseVanityImport("go.example.com git https://github.com/example/{name}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("docs"))
+	})
+	mw := VanityImports([]*source.VanityImport{v}, "https://pkg.example.com/")
+
+	for _, test := range []struct {
+		url          string
+		wantCode     int
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			"https://go.example.com/lib/sub?go-get=1",
+			http.StatusOK, "",
+			`<meta name="go-import" content="go.example.com/lib git https://github.com/example/lib">`,
+		},
+		{
+			"https://go.example.com/lib/sub",
+			http.StatusFound, "https://pkg.example.com/go.example.com/lib/sub", "",
+		},
+		{
+			"https://pkg.example.com/go.example.com/lib/sub",
+			http.StatusOK, "", "docs",
+		},
+		{
+			"https://go.example.com/go.example.com/lib",
+			http.StatusOK, "", "docs",
+		},
+	} {
+		w := httptest.NewRecorder()
+		mw(handler).ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+		res := w.Result()
+		if res.StatusCode != test.wantCode {
+			t.Errorf("%s: got status %d, want %d", test.url, res.StatusCode, test.wantCode)
+		}
+		if got := res.Header.Get("Location"); got != test.wantLocation {
+			t.Errorf("%s: got Location %q, want %q", test.url, got, test.wantLocation)
+		}
+		if got := w.Body.String(); !strings.Contains(got, test.wantBody) {
+			t.Errorf("%s: body %q does not contain %q", test.url, got, test.wantBody)
+		}
+	}
+
+	// Without a documentation URL, browsers get the meta tags too.
+	w := httptest.NewRecorder()
+	VanityImports([]*source.VanityImport{v}, "")(handler).ServeHTTP(w, httptest.NewRequest("GET", "https://go.example.com/lib/sub", nil))
+	if res := w.Result(); res.StatusCode != http.StatusOK || res.Header.Get("Location") != "" {
+		t.Errorf("no docs URL: got status %d, Location %q; want 200 and no redirect", res.StatusCode, res.Header.Get("Location"))
+	}
+	if got, want := w.Body.String(), `<meta name="go-import"`; !strings.Contains(got, want) {
+		t.Errorf("no docs URL: body %q does not contain %q", got, want)
+	}
+}
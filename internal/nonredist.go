@@ -9,7 +9,7 @@ package internal
 
 func (m *Module) RemoveNonRedistributableData() {
 	for _, l := range m.Licenses {
-		l.RemoveNonRedistributableData()
+		l.RemoveNonRedistributableData(m.ModulePath)
 	}
 	for _, d := range m.Units {
 		d.RemoveNonRedistributableData()
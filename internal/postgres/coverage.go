@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+// GetDocCoverage returns the documentation coverage of each package in the
+// given module version, keyed by package path. Packages whose coverage was
+// not computed when they were processed are omitted.
+func (db *DB) GetDocCoverage(ctx context.Context, modulePath, resolvedVersion string) (_ map[string]*internal.DocCoverage, err error) {
+	defer derrors.Wrap(&err, "GetDocCoverage(ctx, %q, %q)", modulePath, resolvedVersion)
+	defer middleware.ElapsedStat(ctx, "GetDocCoverage")()
+
+	query := `
+		SELECT
+			u.path,
+			d.has_package_doc,
+			d.num_exported,
+			d.num_documented
+		FROM modules m
+		INNER JOIN units u
+		ON u.module_id = m.id
+		INNER JOIN documentation d
+		ON d.unit_id = u.id
+		WHERE
+			m.module_path = $1
+			AND m.version = $2
+			AND d.num_exported IS NOT NULL`
+	cov := map[string]*internal.DocCoverage{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			path                       string
+			hasDoc                     sql.NullBool
+			numExported, numDocumented sql.NullInt64
+		)
+		if err := rows.Scan(&path, &hasDoc, &numExported, &numDocumented); err != nil {
+			return err
+		}
+		cov[path] = makeDocCoverage(hasDoc, numExported, numDocumented)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, resolvedVersion); err != nil {
+		return nil, err
+	}
+	return cov, nil
+}
+
+// makeDocCoverage constructs the documentation coverage of a single package
+// from the nullable columns of the documentation table. It returns nil if
+// coverage was not computed.
+func makeDocCoverage(hasDoc sql.NullBool, numExported, numDocumented sql.NullInt64) *internal.DocCoverage {
+	if !numExported.Valid {
+		return nil
+	}
+	c := &internal.DocCoverage{
+		NumPackages:   1,
+		NumExported:   int(numExported.Int64),
+		NumDocumented: int(numDocumented.Int64),
+	}
+	if !hasDoc.Bool {
+		c.NumMissingPackageDocs = 1
+	}
+	return c
+}
+
+// docCoverageValues returns the values to insert into the has_package_doc,
+// num_exported and num_documented columns of the documentation table for c.
+func docCoverageValues(c *internal.DocCoverage) []interface{} {
+	if c == nil {
+		return []interface{}{nil, nil, nil}
+	}
+	return []interface{}{c.NumMissingPackageDocs == 0, c.NumExported, c.NumDocumented}
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+// DependencyInfo describes a processed module version, for use in reports
+// about the dependencies of other modules.
+type DependencyInfo struct {
+	ModulePath        string
+	Version           string
+	IsRedistributable bool
+	// LicenseTypes are the types of the licenses at the root of the module.
+	LicenseTypes []string
+	// Retracted reports whether the version is retracted by the go.mod file
+	// of the latest version of the module.
+	Retracted           bool
+	RetractionRationale string
+	// GoMod holds the require and replace directives of the module's go.mod
+	// file. It is nil if they were not stored when the module was processed.
+	GoMod *internal.GoMod
+}
+
+// GetDependencyInfo returns information about each of the given module
+// versions that has been processed, keyed by module version. Versions that
+// have not been processed are missing from the map.
+func (db *DB) GetDependencyInfo(ctx context.Context, mods []module.Version) (_ map[module.Version]*DependencyInfo, err error) {
+	defer derrors.Wrap(&err, "GetDependencyInfo(ctx, %d modules)", len(mods))
+	defer middleware.ElapsedStat(ctx, "GetDependencyInfo")()
+
+	var paths, versions []string
+	for _, m := range mods {
+		paths = append(paths, m.Path)
+		versions = append(versions, m.Version)
+	}
+	query := `
+		SELECT
+			m.module_path,
+			m.version,
+			m.redistributable,
+			m.retracted,
+			m.retraction_rationale,
+			ARRAY(
+				SELECT DISTINCT unnest(l.types)
+				FROM licenses l
+				WHERE l.module_id = m.id AND position('/' in l.file_path) = 0
+				ORDER BY 1
+			),
+			g.module_id IS NOT NULL,
+			g.requires,
+			g.replaces
+		FROM modules m
+		INNER JOIN unnest($1::text[], $2::text[]) AS mv(module_path, version)
+		ON m.module_path = mv.module_path AND m.version = mv.version
+		LEFT JOIN go_mods g
+		ON g.module_id = m.id`
+	infos := map[module.Version]*DependencyInfo{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			info     DependencyInfo
+			hasGoMod bool
+			gm       internal.GoMod
+		)
+		if err := rows.Scan(&info.ModulePath, &info.Version, &info.IsRedistributable,
+			&info.Retracted, &info.RetractionRationale,
+			pq.Array(&info.LicenseTypes), &hasGoMod, jsonbScanner{&gm.Requires}, jsonbScanner{&gm.Replaces}); err != nil {
+			return err
+		}
+		if hasGoMod {
+			info.GoMod = &gm
+		}
+		infos[module.Version{Path: info.ModulePath, Version: info.Version}] = &info
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, pq.Array(paths), pq.Array(versions)); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// LatestModuleVersions describes the latest versions of a module.
+type LatestModuleVersions struct {
+	// Version is the latest processed version of the module, or the empty
+	// string if none has been processed.
+	Version string
+	// MajorModulePath is the module path of the latest processed version in
+	// the series of the module, like "example.com/m/v2".
+	MajorModulePath string
+}
+
+// GetLatestModuleVersions returns the latest versions of each of
+// modulePaths, keyed by module path, choosing them in the order of
+// orderByLatest. Modules none of whose series has been processed are missing
+// from the map.
+func (db *DB) GetLatestModuleVersions(ctx context.Context, modulePaths []string) (_ map[string]*LatestModuleVersions, err error) {
+	defer derrors.Wrap(&err, "GetLatestModuleVersions(ctx, %d modules)", len(modulePaths))
+	defer middleware.ElapsedStat(ctx, "GetLatestModuleVersions")()
+
+	var seriesPaths []string
+	for _, p := range modulePaths {
+		seriesPaths = append(seriesPaths, internal.SeriesPathForModule(p))
+	}
+	query := `
+		SELECT
+			p.module_path,
+			(
+				SELECT m.version
+				FROM modules m
+				WHERE m.module_path = p.module_path` + orderByLatestStmt + `
+				LIMIT 1
+			),
+			(
+				SELECT m.module_path
+				FROM modules m
+				WHERE m.series_path = p.series_path` + orderByLatestStmt + `
+				LIMIT 1
+			)
+		FROM unnest($1::text[], $2::text[]) AS p(module_path, series_path)`
+	latest := map[string]*LatestModuleVersions{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			modulePath string
+			lv         LatestModuleVersions
+		)
+		if err := rows.Scan(&modulePath, database.NullIsEmpty(&lv.Version), database.NullIsEmpty(&lv.MajorModulePath)); err != nil {
+			return err
+		}
+		if lv.MajorModulePath != "" {
+			latest[modulePath] = &lv
+		}
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, pq.Array(modulePaths), pq.Array(seriesPaths)); err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
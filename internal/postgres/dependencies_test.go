@@ -0,0 +1,96 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetDependencyInfo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	a := sample.Module("example.com/a", "v1.0.0", "")
+	a.GoMod = &internal.GoMod{
+		Requires: []*internal.GoModRequire{{ModulePath: "example.com/b", Version: "v1.2.0"}},
+		Replaces: []*internal.GoModReplace{{OldPath: "example.com/c", NewPath: "../c"}},
+	}
+	b := sample.Module("example.com/b", "v1.2.0", "")
+	for _, m := range []*internal.Module{a, b} {
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := testDB.GetDependencyInfo(ctx, []module.Version{
+		{Path: "example.com/a", Version: "v1.0.0"},
+		{Path: "example.com/b", Version: "v1.2.0"},
+		{Path: "example.com/b", Version: "v1.1.0"},
+		{Path: "example.com/c", Version: "v1.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[module.Version]*DependencyInfo{
+		{Path: "example.com/a", Version: "v1.0.0"}: {
+			ModulePath:        "example.com/a",
+			Version:           "v1.0.0",
+			IsRedistributable: true,
+			LicenseTypes:      []string{sample.LicenseType},
+			GoMod: &internal.GoMod{
+				Requires: a.GoMod.Requires,
+				Replaces: a.GoMod.Replaces,
+			},
+		},
+		{Path: "example.com/b", Version: "v1.2.0"}: {
+			ModulePath:        "example.com/b",
+			Version:           "v1.2.0",
+			IsRedistributable: true,
+			LicenseTypes:      []string{sample.LicenseType},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetLatestModuleVersions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	for _, mv := range []struct{ path, version string }{
+		{"example.com/a", "v1.0.0"},
+		{"example.com/a", "v1.2.0"},
+		{"example.com/a", "v1.3.0-pre"},
+		{"example.com/a/v2", "v2.1.0"},
+		{"example.com/b", "v0.1.0"},
+	} {
+		if err := testDB.InsertModule(ctx, sample.Module(mv.path, mv.version, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := testDB.GetLatestModuleVersions(ctx, []string{"example.com/a", "example.com/a/v3", "example.com/b", "example.com/c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*LatestModuleVersions{
+		"example.com/a": {Version: "v1.2.0", MajorModulePath: "example.com/a/v2"},
+		// Only another major version of example.com/a/v3 has been processed.
+		"example.com/a/v3": {MajorModulePath: "example.com/a/v2"},
+		"example.com/b":    {Version: "v0.1.0", MajorModulePath: "example.com/b"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
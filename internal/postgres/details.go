@@ -31,7 +31,11 @@ func (db *DB) GetNestedModules(ctx context.Context, modulePath string) (_ []*int
 			m.commit_time,
 			m.redistributable,
 			m.has_go_mod,
-			m.source_info
+			m.source_info,
+			m.deprecated,
+			m.deprecation_comment,
+			m.retracted,
+			m.retraction_rationale
 		FROM
 			modules m
 		WHERE
@@ -146,7 +150,11 @@ func (db *DB) GetModuleInfo(ctx context.Context, modulePath, resolvedVersion str
 			commit_time,
 			redistributable,
 			has_go_mod,
-			source_info
+			source_info,
+			deprecated,
+			deprecation_comment,
+			retracted,
+			retraction_rationale
 		FROM
 			modules
 		WHERE
@@ -197,7 +205,8 @@ func (s jsonbScanner) Scan(value interface{}) (err error) {
 func scanModuleInfo(scan func(dest ...interface{}) error) (*internal.ModuleInfo, error) {
 	var mi internal.ModuleInfo
 	if err := scan(&mi.ModulePath, &mi.Version, &mi.CommitTime,
-		&mi.IsRedistributable, &mi.HasGoMod, jsonbScanner{&mi.SourceInfo}); err != nil {
+		&mi.IsRedistributable, &mi.HasGoMod, jsonbScanner{&mi.SourceInfo},
+		&mi.Deprecated, &mi.DeprecationComment, &mi.Retracted, &mi.RetractionRationale); err != nil {
 		return nil, err
 	}
 	return &mi, nil
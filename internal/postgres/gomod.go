@@ -0,0 +1,227 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Masterminds/squirrel"
+	"go.opencensus.io/trace"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+// insertGoMod inserts the go.mod directives of m into the go_mods table,
+// replacing any that were there before. If m has no go.mod file, any existing
+// row is removed.
+func insertGoMod(ctx context.Context, db *database.DB, m *internal.Module, moduleID int) (err error) {
+	ctx, span := trace.StartSpan(ctx, "insertGoMod")
+	defer span.End()
+	defer derrors.Wrap(&err, "insertGoMod(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if err := insertModuleRequires(ctx, db, m, moduleID); err != nil {
+		return err
+	}
+	if m.GoMod == nil {
+		_, err := db.Exec(ctx, `DELETE FROM go_mods WHERE module_id = $1`, moduleID)
+		return err
+	}
+	var values []interface{}
+	for _, v := range []interface{}{m.GoMod.Requires, m.GoMod.Replaces, m.GoMod.Excludes, m.GoMod.Retracts} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		values = append(values, b)
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO go_mods (module_id, go_version, deprecated, deprecation_comment, requires, replaces, excludes, retracts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (module_id)
+		DO UPDATE SET
+			go_version=excluded.go_version,
+			deprecated=excluded.deprecated,
+			deprecation_comment=excluded.deprecation_comment,
+			requires=excluded.requires,
+			replaces=excluded.replaces,
+			excludes=excluded.excludes,
+			retracts=excluded.retracts`,
+		append([]interface{}{moduleID, m.GoMod.GoVersion, m.GoMod.Deprecated, m.GoMod.DeprecationComment}, values...)...)
+	return err
+}
+
+// insertModuleRequires replaces the rows of the module_requires table for m,
+// the edges of the module requirement graph that start at m, with the require
+// directives of its go.mod file.
+func insertModuleRequires(ctx context.Context, db *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer derrors.Wrap(&err, "insertModuleRequires(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM module_requires WHERE module_id = $1`, moduleID); err != nil {
+		return err
+	}
+	if m.GoMod == nil {
+		return nil
+	}
+	var values []interface{}
+	seen := map[[2]string]bool{}
+	for _, r := range m.GoMod.Requires {
+		// The go command rejects duplicate requirements, but they parse.
+		k := [2]string{r.ModulePath, r.Version}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		values = append(values, moduleID, r.ModulePath, r.Version, r.Indirect)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"module_id", "required_path", "required_version", "indirect"}
+	return db.BulkInsert(ctx, "module_requires", cols, values, "")
+}
+
+// GetGoMod returns the go.mod directives for the given module version. It
+// returns an error wrapping derrors.NotFound if the module version does not
+// exist or has no go.mod file.
+func (db *DB) GetGoMod(ctx context.Context, modulePath, resolvedVersion string) (_ *internal.GoMod, err error) {
+	defer derrors.Wrap(&err, "GetGoMod(ctx, %q, %q)", modulePath, resolvedVersion)
+	defer middleware.ElapsedStat(ctx, "GetGoMod")()
+
+	var gm internal.GoMod
+	err = db.db.QueryRow(ctx, `
+		SELECT g.go_version, g.deprecated, g.deprecation_comment, g.requires, g.replaces, g.excludes, g.retracts
+		FROM go_mods g
+		INNER JOIN modules m
+		ON g.module_id = m.id
+		WHERE
+			m.module_path = $1
+			AND m.version = $2`, modulePath, resolvedVersion).Scan(
+		&gm.GoVersion,
+		&gm.Deprecated,
+		&gm.DeprecationComment,
+		jsonbScanner{&gm.Requires},
+		jsonbScanner{&gm.Replaces},
+		jsonbScanner{&gm.Excludes},
+		jsonbScanner{&gm.Retracts})
+	switch err {
+	case sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case nil:
+		return &gm, nil
+	default:
+		return nil, err
+	}
+}
+
+// updateRetractionsAndDeprecation applies the retract directives and
+// deprecation comment of the go.mod file at the latest version of modulePath
+// to every version of modulePath in the modules table. As with the go
+// command, the latest version is chosen without regard to retractions, so a
+// version may retract itself.
+func updateRetractionsAndDeprecation(ctx context.Context, db *database.DB, modulePath string) (err error) {
+	ctx, span := trace.StartSpan(ctx, "updateRetractionsAndDeprecation")
+	defer span.End()
+	defer derrors.Wrap(&err, "updateRetractionsAndDeprecation(ctx, tx, %q)", modulePath)
+
+	q, args, err := orderByLatestIgnoringRetractions(squirrel.Select(
+		"COALESCE(g.deprecated, FALSE)",
+		"COALESCE(g.deprecation_comment, '')",
+		"g.retracts").
+		From("modules m").
+		LeftJoin("go_mods g ON g.module_id = m.id").
+		Where(squirrel.Eq{"m.module_path": modulePath})).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return err
+	}
+	var (
+		deprecated         bool
+		deprecationComment string
+		retracts           []*internal.GoModRetract
+	)
+	err = db.QueryRow(ctx, q, args...).Scan(&deprecated, &deprecationComment, jsonbScanner{&retracts})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Collect the rows whose values need to change.
+	var ids, retracteds, rationales, deprecateds, comments []interface{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			id                 int
+			version            string
+			retracted, depr    bool
+			rationale, comment string
+		)
+		if err := rows.Scan(&id, &version, &retracted, &rationale, &depr, &comment); err != nil {
+			return err
+		}
+		newRetracted, newRationale := false, ""
+		for _, r := range retracts {
+			if r.Includes(version) {
+				newRetracted, newRationale = true, r.Rationale
+				break
+			}
+		}
+		if retracted == newRetracted && rationale == newRationale && depr == deprecated && comment == deprecationComment {
+			return nil
+		}
+		ids = append(ids, id)
+		retracteds = append(retracteds, newRetracted)
+		rationales = append(rationales, newRationale)
+		deprecateds = append(deprecateds, deprecated)
+		comments = append(comments, deprecationComment)
+		return nil
+	}
+	if err := db.RunQuery(ctx, `
+		SELECT id, version, retracted, retraction_rationale, deprecated, deprecation_comment
+		FROM modules
+		WHERE module_path = $1`, collect, modulePath); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := db.BulkUpdate(ctx, "modules",
+		[]string{"id", "retracted", "retraction_rationale", "deprecated", "deprecation_comment"},
+		[]string{"INTEGER", "BOOLEAN", "TEXT", "BOOLEAN", "TEXT"},
+		[][]interface{}{ids, retracteds, rationales, deprecateds, comments}); err != nil {
+		return err
+	}
+	return updateSearchDocumentsDeprecation(ctx, db, modulePath)
+}
+
+// updateSearchDocumentsDeprecation sets the deprecated column of the
+// search_documents rows of modulePath from the modules and documentation
+// tables, as upsertSearchStatement does. It is needed because the rows are
+// only rewritten when the latest version of the module changes, but the
+// deprecation of a module can be changed by any later version.
+func updateSearchDocumentsDeprecation(ctx context.Context, db *database.DB, modulePath string) (err error) {
+	defer derrors.Wrap(&err, "updateSearchDocumentsDeprecation(ctx, tx, %q)", modulePath)
+
+	_, err = db.Exec(ctx, `
+		UPDATE search_documents sd
+		SET deprecated = m.deprecated OR COALESCE(d.deprecated, FALSE)
+		FROM modules m
+		INNER JOIN units u
+		ON u.module_id = m.id
+		LEFT JOIN documentation d
+		ON d.unit_id = u.id
+		WHERE sd.module_path = $1
+		AND m.module_path = sd.module_path
+		AND m.version = sd.version
+		AND u.path = sd.package_path
+		AND sd.deprecated IS DISTINCT FROM (m.deprecated OR COALESCE(d.deprecated, FALSE))`,
+		modulePath)
+	return err
+}
@@ -0,0 +1,224 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetGoMod(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	m := sample.DefaultModule()
+	m.GoMod = &internal.GoMod{
+		GoVersion: "1.15",
+		Requires: []*internal.GoModRequire{
+			{ModulePath: "example.com/a", Version: "v1.2.3"},
+			{ModulePath: "example.com/b", Version: "v0.1.0", Indirect: true},
+		},
+		Replaces: []*internal.GoModReplace{
+			{OldPath: "example.com/a", NewPath: "../a"},
+		},
+		Excludes: []*internal.GoModExclude{
+			{ModulePath: "example.com/a", Version: "v1.2.2"},
+		},
+		Retracts: []*internal.GoModRetract{
+			{Low: "v1.0.1", High: "v1.0.1", Rationale: "Published accidentally."},
+		},
+	}
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := testDB.GetGoMod(ctx, m.ModulePath, m.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(m.GoMod, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// Reinserting the module without a go.mod file removes the directives.
+	m.GoMod = nil
+	if err := testDB.InsertModule(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.GetGoMod(ctx, m.ModulePath, m.Version); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("got error %v, want NotFound", err)
+	}
+}
+
+func TestRetractionsAndDeprecation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/retract"
+	for _, v := range []string{"v1.0.0", "v1.1.0", "v1.2.0"} {
+		m := sample.Module(modulePath, v)
+		m.HasGoMod = true
+		m.GoMod = &internal.GoMod{}
+		if v == "v1.2.0" {
+			// The latest version retracts itself and v1.1.0, and deprecates
+			// the module.
+			m.GoMod.Deprecated = true
+			m.GoMod.DeprecationComment = "use example.com/other"
+			m.GoMod.Retracts = []*internal.GoModRetract{
+				{Low: "v1.1.0", High: "v1.2.0", Rationale: "bad"},
+			}
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		version   string
+		retracted bool
+	}{
+		{"v1.0.0", false},
+		{"v1.1.0", true},
+		{"v1.2.0", true},
+	} {
+		mi, err := testDB.GetModuleInfo(ctx, modulePath, test.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mi.Retracted != test.retracted {
+			t.Errorf("%s: got retracted %t, want %t", test.version, mi.Retracted, test.retracted)
+		}
+		if test.retracted && mi.RetractionRationale != "bad" {
+			t.Errorf("%s: got rationale %q, want %q", test.version, mi.RetractionRationale, "bad")
+		}
+		if !mi.Deprecated || mi.DeprecationComment != "use example.com/other" {
+			t.Errorf("%s: got deprecated (%t, %q), want (true, %q)",
+				test.version, mi.Deprecated, mi.DeprecationComment, "use example.com/other")
+		}
+	}
+
+	// The latest version is the highest one that is not retracted.
+	um, err := testDB.GetUnitMeta(ctx, modulePath, internal.UnknownModulePath, internal.LatestVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := um.Version, "v1.0.0"; got != want {
+		t.Errorf("latest version: got %s, want %s", got, want)
+	}
+}
+
+func TestDeprecationUpdatesSearchDocuments(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/deprecate"
+	insert := func(version string, gm *internal.GoMod) {
+		t.Helper()
+		m := sample.Module(modulePath, version, "pkg")
+		m.HasGoMod = true
+		m.GoMod = gm
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkDeprecated := func(want bool) {
+		t.Helper()
+		var (
+			version    string
+			deprecated bool
+		)
+		err := testDB.db.QueryRow(ctx, `SELECT version, deprecated FROM search_documents WHERE package_path = $1`,
+			modulePath+"/pkg").Scan(&version, &deprecated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != "v1.0.0" || deprecated != want {
+			t.Errorf("got (%s, deprecated=%t), want (v1.0.0, deprecated=%t)", version, deprecated, want)
+		}
+	}
+
+	insert("v1.0.0", &internal.GoMod{})
+	checkDeprecated(false)
+
+	// v1.1.0 retracts itself, so the search document stays at v1.0.0, but
+	// its deprecation applies to the whole module.
+	insert("v1.1.0", &internal.GoMod{
+		Deprecated: true,
+		Retracts:   []*internal.GoModRetract{{Low: "v1.1.0", High: "v1.1.0"}},
+	})
+	checkDeprecated(true)
+
+	insert("v1.2.0", &internal.GoMod{
+		Retracts: []*internal.GoModRetract{{Low: "v1.1.0", High: "v1.2.0"}},
+	})
+	checkDeprecated(false)
+}
+
+func TestSelfRetractingReleaseUpdatesLatest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/selfretract"
+	insert := func(version, imp string, gm *internal.GoMod) {
+		t.Helper()
+		m := sample.Module(modulePath, version, "pkg")
+		m.HasGoMod = true
+		m.GoMod = gm
+		for _, p := range m.Packages() {
+			p.Imports = []string{imp}
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(wantVersion, wantImport string) {
+		t.Helper()
+		var version string
+		if err := testDB.db.QueryRow(ctx, `SELECT version FROM search_documents WHERE package_path = $1`,
+			modulePath+"/pkg").Scan(&version); err != nil {
+			t.Fatal(err)
+		}
+		if version != wantVersion {
+			t.Errorf("search_documents: got version %s, want %s", version, wantVersion)
+		}
+		var imports []string
+		collect := func(rows *sql.Rows) error {
+			var to string
+			if err := rows.Scan(&to); err != nil {
+				return err
+			}
+			imports = append(imports, to)
+			return nil
+		}
+		if err := testDB.db.RunQuery(ctx, `SELECT to_path FROM imports_unique WHERE from_path = $1`,
+			collect, modulePath+"/pkg"); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{wantImport}; !cmp.Equal(imports, want) {
+			t.Errorf("imports_unique: got %v, want %v", imports, want)
+		}
+	}
+
+	insert("v1.0.0", "fmt", &internal.GoMod{})
+	insert("v1.1.0", "errors", &internal.GoMod{})
+	check("v1.1.0", "errors")
+
+	// v1.2.0 retracts itself and v1.1.0, so v1.0.0 is the latest version
+	// again, although v1.2.0 is not.
+	insert("v1.2.0", "strings", &internal.GoMod{
+		Retracts: []*internal.GoModRetract{{Low: "v1.1.0", High: "v1.2.0"}},
+	})
+	check("v1.0.0", "fmt")
+}
@@ -64,7 +64,7 @@ func (db *DB) InsertModule(ctx context.Context, m *internal.Module) (err error)
 }
 
 // saveModule inserts a Module into the database along with its packages,
-// imports, and licenses.  If any of these rows already exist, the module and
+// imports, licenses and go.mod directives.  If any of these rows already exist, the module and
 // corresponding will be deleted and reinserted.
 // If the module is malformed then insertion will fail.
 //
@@ -84,12 +84,15 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module) (err error) {
 		// inserted. Rows that currently exist should not be missing from the
 		// new module. We want to be sure that we will overwrite every row that
 		// pertains to the module.
-		if err := db.compareLicenses(ctx, moduleID, m.Licenses); err != nil {
+		if err := db.compareLicenses(ctx, m.ModulePath, moduleID, m.Licenses); err != nil {
 			return err
 		}
 		if err := insertLicenses(ctx, tx, m, moduleID); err != nil {
 			return err
 		}
+		if err := insertGoMod(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
 		if err := db.insertUnits(ctx, tx, m, moduleID); err != nil {
 			return err
 		}
@@ -104,14 +107,32 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module) (err error) {
 			return err
 		}
 
+		// Apply the retractions and deprecation from the go.mod file of the
+		// latest version to all versions of the module. This must happen
+		// before determining whether m is the latest version, since retracted
+		// versions are not preferred.
+		if err := updateRetractionsAndDeprecation(ctx, tx, m.ModulePath); err != nil {
+			return err
+		}
+
+		// Record how the license files changed since the previous version,
+		// so that users can be alerted to them.
+		if err := updateLicenseChanges(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
+
 		// We only insert into imports_unique and search_documents if this is
 		// the latest version of the module.
-		isLatest, err := isLatestVersion(ctx, tx, m.ModulePath, m.Version)
+		latest, err := latestVersion(ctx, tx, m.ModulePath)
 		if err != nil {
 			return err
 		}
-		if !isLatest {
-			return nil
+		if latest != m.Version {
+			// The retractions of m may still have changed the latest version,
+			// for instance if m retracts both itself and the previous latest
+			// version. Then imports_unique and search_documents must be
+			// rebuilt from the version that is now the latest.
+			return db.updateLatestVersion(ctx, tx, m.ModulePath, latest)
 		}
 
 		if err := insertImportsUnique(ctx, tx, m); err != nil {
@@ -138,13 +159,8 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module) (err error) {
 		// (github.com/Sirupsen/logrus@v1.1.0 in the example) and then see the valid
 		// one. The "if code == 491" section of internal/worker.fetchAndUpdateState
 		// handles the case where we fetch the versions in the other order.
-		row := tx.QueryRow(ctx, `
-			SELECT 1 FROM module_version_states
-			WHERE module_path = $1 AND sort_version > $2 and status = 491`,
-			m.ModulePath, version.ForSorting(m.Version))
-		var x int
-		if err := row.Scan(&x); err != sql.ErrNoRows {
-			log.Infof(ctx, "%s@%s: not inserting into search documents", m.ModulePath, m.Version)
+		alt, err := hasNewerAlternative(ctx, tx, m.ModulePath, m.Version)
+		if err != nil || alt {
 			return err
 		}
 		// Insert the module's packages into search_documents.
@@ -152,6 +168,126 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module) (err error) {
 	})
 }
 
+// hasNewerAlternative reports whether a version of modulePath newer than
+// resolvedVersion has an alternative module path, in which case the packages
+// of resolvedVersion must not be inserted into search_documents. See
+// saveModule.
+func hasNewerAlternative(ctx context.Context, tx *database.DB, modulePath, resolvedVersion string) (bool, error) {
+	row := tx.QueryRow(ctx, `
+		SELECT 1 FROM module_version_states
+		WHERE module_path = $1 AND sort_version > $2 and status = 491`,
+		modulePath, version.ForSorting(resolvedVersion))
+	var x int
+	switch err := row.Scan(&x); err {
+	case sql.ErrNoRows:
+		return false, nil
+	case nil:
+		log.Infof(ctx, "%s@%s: not inserting into search documents", modulePath, resolvedVersion)
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// updateLatestVersion rebuilds the imports_unique and search_documents rows
+// of modulePath from latest, its latest version, which was inserted earlier,
+// if search_documents holds another version of a package of latest. It does
+// nothing otherwise, so inserting an older version of a module is cheap.
+func (db *DB) updateLatestVersion(ctx context.Context, tx *database.DB, modulePath, latest string) (err error) {
+	defer derrors.Wrap(&err, "updateLatestVersion(ctx, tx, %q, %q)", modulePath, latest)
+
+	var stale bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM search_documents sd
+			INNER JOIN units u
+			ON u.path = sd.package_path
+			INNER JOIN modules m
+			ON m.id = u.module_id
+			WHERE sd.module_path = $1
+			AND m.module_path = $1
+			AND m.version = $2
+			AND sd.version <> $2
+		)`, modulePath, latest).Scan(&stale); err != nil {
+		return err
+	}
+	if !stale {
+		return nil
+	}
+	log.Infof(ctx, "%s: latest version changed to %s; updating imports_unique and search_documents", modulePath, latest)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM imports_unique WHERE from_module_path = $1`,
+		modulePath); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO imports_unique (from_path, from_module_path, to_path)
+		SELECT u.path, m.module_path, i.to_path
+		FROM modules m
+		INNER JOIN units u
+		ON u.module_id = m.id
+		INNER JOIN package_imports i
+		ON i.unit_id = u.id
+		WHERE m.module_path = $1 AND m.version = $2
+		ON CONFLICT DO NOTHING`,
+		modulePath, latest); err != nil {
+		return err
+	}
+
+	alt, err := hasNewerAlternative(ctx, tx, modulePath, latest)
+	if err != nil || alt {
+		return err
+	}
+	var argsList []upsertSearchDocumentArgs
+	collect := func(rows *sql.Rows) error {
+		var (
+			a      upsertSearchDocumentArgs
+			redist bool
+		)
+		if err := rows.Scan(&a.PackagePath, &a.ModulePath, database.NullIsEmpty(&a.Synopsis), &redist,
+			database.NullIsEmpty(&a.ReadmeFilePath), database.NullIsEmpty(&a.ReadmeContents)); err != nil {
+			return err
+		}
+		if isInternalPackage(a.PackagePath) {
+			return nil
+		}
+		if !redist && !db.bypassLicenseCheck {
+			a.Synopsis = ""
+			a.ReadmeFilePath = ""
+			a.ReadmeContents = ""
+		}
+		argsList = append(argsList, a)
+		return nil
+	}
+	if err := tx.RunQuery(ctx, `
+		SELECT DISTINCT ON (u.path)
+			u.path,
+			m.module_path,
+			d.synopsis,
+			u.redistributable,
+			r.file_path,
+			r.contents
+		FROM modules m
+		INNER JOIN units u
+		ON u.module_id = m.id
+		LEFT JOIN documentation d
+		ON d.unit_id = u.id
+		LEFT JOIN readmes r
+		ON r.unit_id = u.id
+		WHERE m.module_path = $1 AND m.version = $2 AND u.name <> ''
+		ORDER BY u.path`, collect, modulePath, latest); err != nil {
+		return err
+	}
+	for _, a := range argsList {
+		if err := db.UpsertSearchDocument(ctx, tx, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func insertModule(ctx context.Context, db *database.DB, m *internal.Module) (_ int, err error) {
 	ctx, span := trace.StartSpan(ctx, "insertModule")
 	defer span.End()
@@ -211,9 +347,14 @@ func insertLicenses(ctx context.Context, db *database.DB, m *internal.Module, mo
 		if err != nil {
 			return fmt.Errorf("marshalling %+v: %v", l.Coverage, err)
 		}
+		// Licenses that were not read by a licenses.Detector have no key.
+		var contentsKey interface{}
+		if l.ContentsKey != "" {
+			contentsKey = l.ContentsKey
+		}
 		licenseValues = append(licenseValues, l.FilePath,
 			makeValidUnicode(string(l.Contents)), pq.Array(l.Types), covJSON,
-			moduleID)
+			contentsKey, moduleID)
 	}
 	if len(licenseValues) > 0 {
 		licenseCols := []string{
@@ -221,6 +362,7 @@ func insertLicenses(ctx context.Context, db *database.DB, m *internal.Module, mo
 			"contents",
 			"types",
 			"coverage",
+			"contents_key",
 			"module_id",
 		}
 		return db.BulkUpsert(ctx, "licenses", licenseCols, licenseValues,
@@ -433,10 +575,11 @@ func (pdb *DB) insertUnits(ctx context.Context, db *database.DB, m *internal.Mod
 				continue
 			}
 			unitID := pathToUnitID[path]
-			docValues = append(docValues, unitID, doc.GOOS, doc.GOARCH, doc.Synopsis, doc.Source)
+			docValues = append(docValues, unitID, doc.GOOS, doc.GOARCH, doc.Synopsis, doc.Deprecated, doc.Source)
+			docValues = append(docValues, docCoverageValues(doc.Coverage)...)
 		}
 		uniqueCols := []string{"unit_id", "goos", "goarch"}
-		docCols := append(uniqueCols, "synopsis", "source")
+		docCols := append(uniqueCols, "synopsis", "deprecated", "source", "has_package_doc", "num_exported", "num_documented")
 		if err := db.BulkUpsert(ctx, "documentation", docCols, docValues, uniqueCols); err != nil {
 			return err
 		}
@@ -498,23 +641,33 @@ func isIncompatible(version string) bool {
 func isLatestVersion(ctx context.Context, ddb *database.DB, modulePath, resolvedVersion string) (_ bool, err error) {
 	defer derrors.Wrap(&err, "isLatestVersion(ctx, tx, %q)", modulePath)
 
+	v, err := latestVersion(ctx, ddb, modulePath)
+	if err != nil {
+		return false, err
+	}
+	if v == "" {
+		return true, nil // It's the only version, so it's also the latest.
+	}
+	return resolvedVersion == v, nil
+}
+
+// latestVersion returns the latest version of the module, or the empty string
+// if there is no version of it in the database.
+func latestVersion(ctx context.Context, ddb *database.DB, modulePath string) (_ string, err error) {
 	q, args, err := orderByLatest(squirrel.Select("m.version").
 		From("modules m").
 		Where(squirrel.Eq{"m.module_path": modulePath})).
 		Limit(1).
 		ToSql()
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	row := ddb.QueryRow(ctx, q, args...)
 	var v string
-	if err := row.Scan(&v); err != nil {
-		if err == sql.ErrNoRows {
-			return true, nil // It's the only version, so it's also the latest.
-		}
-		return false, err
+	err = ddb.QueryRow(ctx, q, args...).Scan(&v)
+	if err == sql.ErrNoRows {
+		return "", nil
 	}
-	return resolvedVersion == v, nil
+	return v, err
 }
 
 // validateModule checks that fields needed to insert a module into the database
@@ -562,9 +715,9 @@ func validateModule(m *internal.Module) (err error) {
 // compareLicenses compares m.Licenses with the existing licenses for
 // m.ModulePath and m.Version in the database. It returns an error if there
 // are licenses in the licenses table that are not present in m.Licenses.
-func (db *DB) compareLicenses(ctx context.Context, moduleID int, lics []*licenses.License) (err error) {
-	defer derrors.Wrap(&err, "compareLicenses(ctx, %d)", moduleID)
-	dbLicenses, err := db.getModuleLicenses(ctx, moduleID)
+func (db *DB) compareLicenses(ctx context.Context, modulePath string, moduleID int, lics []*licenses.License) (err error) {
+	defer derrors.Wrap(&err, "compareLicenses(ctx, %q, %d)", modulePath, moduleID)
+	dbLicenses, err := db.getModuleLicenses(ctx, modulePath, moduleID)
 	if err != nil {
 		return err
 	}
@@ -630,6 +783,55 @@ func (db *DB) DeleteModule(ctx context.Context, modulePath, resolvedVersion stri
 	})
 }
 
+// DeleteOldStdlibMasterVersions deletes the pseudo-versions of the standard
+// library other than keep. They are builds of the master branch, each of
+// which supersedes the previous ones, so only the latest one, keep, is kept.
+// It returns the versions that were deleted.
+func (db *DB) DeleteOldStdlibMasterVersions(ctx context.Context, keep string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "DeleteOldStdlibMasterVersions(ctx, %q)", keep)
+
+	var deleted []string
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		deleted = nil
+		// As in DeleteModule, deleting from the modules table deletes the
+		// rows of the other tables that refer to it.
+		collect := func(rows *sql.Rows) error {
+			var v string
+			if err := rows.Scan(&v); err != nil {
+				return err
+			}
+			deleted = append(deleted, v)
+			return nil
+		}
+		if err := tx.RunQuery(ctx, `
+			DELETE FROM modules
+			WHERE module_path = $1 AND version_type = 'pseudo' AND version <> $2
+			RETURNING version`, collect, stdlib.ModulePath, keep); err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return nil
+		}
+		// The module_version_states rows are deleted too, so that the old
+		// versions are not fetched again when modules are reprocessed.
+		for _, table := range []string{"version_map", "search_documents", "module_version_states"} {
+			column := "version"
+			if table == "version_map" {
+				column = "resolved_version"
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE module_path = $1 AND `+column+` = ANY($2)`,
+				stdlib.ModulePath, pq.Array(deleted)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
 // makeValidUnicode removes null runes from a string that will be saved in a
 // column of type TEXT, because pq doesn't like them. It also replaces non-unicode
 // characters with the Unicode replacement character, which is the behavior of
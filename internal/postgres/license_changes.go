@@ -0,0 +1,253 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/lib/pq"
+	"go.opencensus.io/trace"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// seriesVersion identifies a row of the modules table.
+type seriesVersion struct {
+	id         int
+	modulePath string
+	version    string
+}
+
+// updateLicenseChanges records the license changes of m, whose ID in the
+// modules table is moduleID, compared with the previous version of its
+// series. Since m may have been inserted after later versions, it also
+// records the changes of the version that follows m.
+//
+// Pseudo-versions are only compared with other pseudo-versions, and tagged
+// versions with tagged versions.
+func updateLicenseChanges(ctx context.Context, tx *database.DB, m *internal.Module, moduleID int) (err error) {
+	ctx, span := trace.StartSpan(ctx, "updateLicenseChanges")
+	defer span.End()
+	defer derrors.Wrap(&err, "updateLicenseChanges(ctx, tx, %q, %q)", m.ModulePath, m.Version)
+
+	cur := &seriesVersion{id: moduleID, modulePath: m.ModulePath, version: m.Version}
+	prev, err := adjacentSeriesVersion(ctx, tx, m, false)
+	if err != nil {
+		return err
+	}
+	if err := recordLicenseChanges(ctx, tx, prev, cur); err != nil {
+		return err
+	}
+	next, err := adjacentSeriesVersion(ctx, tx, m, true)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		return nil
+	}
+	return recordLicenseChanges(ctx, tx, cur, next)
+}
+
+// adjacentSeriesVersion returns the version of m's series that precedes m, or
+// the one that follows it if after is true. It returns nil if there is none.
+func adjacentSeriesVersion(ctx context.Context, tx *database.DB, m *internal.Module, after bool) (_ *seriesVersion, err error) {
+	query := `
+		SELECT id, module_path, version
+		FROM modules
+		WHERE
+			series_path = $1
+			AND sort_version < $2
+			AND (version_type = 'pseudo') = $3
+		ORDER BY sort_version DESC, module_path
+		LIMIT 1`
+	if after {
+		query = `
+		SELECT id, module_path, version
+		FROM modules
+		WHERE
+			series_path = $1
+			AND sort_version > $2
+			AND (version_type = 'pseudo') = $3
+		ORDER BY sort_version, module_path
+		LIMIT 1`
+	}
+	var sv seriesVersion
+	err = tx.QueryRow(ctx, query, m.SeriesPath(), version.ForSorting(m.Version), version.IsPseudo(m.Version)).Scan(
+		&sv.id, &sv.modulePath, &sv.version)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, nil
+	case nil:
+		return &sv, nil
+	default:
+		return nil, err
+	}
+}
+
+// recordLicenseChanges replaces the license changes of cur with the changes
+// to its license files compared with prev. If prev is nil, cur has no changes.
+//
+// Changes that were already recorded keep their creation time, so that
+// reprocessing a module does not make its changes appear to be new.
+func recordLicenseChanges(ctx context.Context, tx *database.DB, prev, cur *seriesVersion) (err error) {
+	var changes []*internal.LicenseChange
+	if prev != nil {
+		prevLicenses, err := licenseMetadataForModuleID(ctx, tx, prev.id)
+		if err != nil {
+			return err
+		}
+		curLicenses, err := licenseMetadataForModuleID(ctx, tx, cur.id)
+		if err != nil {
+			return err
+		}
+		changes = diffLicenses(prevLicenses, curLicenses)
+	}
+	// filePaths must not be nil, or pq.Array would make it NULL and no rows
+	// would be deleted.
+	filePaths := []string{}
+	var values []interface{}
+	for _, c := range changes {
+		filePaths = append(filePaths, c.FilePath)
+		values = append(values, cur.id, c.FilePath, prev.modulePath, prev.version,
+			pq.Array(c.OldTypes), pq.Array(c.NewTypes))
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM license_changes
+		WHERE module_id = $1 AND NOT (file_path = ANY($2))`,
+		cur.id, pq.Array(filePaths)); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	// created_at is not among the columns, so an existing row keeps it.
+	cols := []string{"module_id", "file_path", "previous_module_path", "previous_version", "old_types", "new_types"}
+	return tx.BulkUpsert(ctx, "license_changes", cols, values, []string{"module_id", "file_path"})
+}
+
+// licenseMetadataForModuleID returns the metadata of all the licenses of the
+// module with the given ID.
+func licenseMetadataForModuleID(ctx context.Context, tx *database.DB, moduleID int) ([]*licenses.Metadata, error) {
+	var lics []*licenses.Metadata
+	collect := func(rows *sql.Rows) error {
+		var lic licenses.Metadata
+		if err := rows.Scan(pq.Array(&lic.Types), &lic.FilePath); err != nil {
+			return err
+		}
+		lics = append(lics, &lic)
+		return nil
+	}
+	if err := tx.RunQuery(ctx, `SELECT types, file_path FROM licenses WHERE module_id = $1`, collect, moduleID); err != nil {
+		return nil, err
+	}
+	return lics, nil
+}
+
+// diffLicenses returns the changes to the license files cur compared with
+// prev, sorted by file path. Only the FilePath, OldTypes and NewTypes fields
+// of the changes are set.
+func diffLicenses(prev, cur []*licenses.Metadata) []*internal.LicenseChange {
+	prevTypes := map[string][]string{}
+	for _, l := range prev {
+		prevTypes[l.FilePath] = sortedTypes(l.Types)
+	}
+	var changes []*internal.LicenseChange
+	for _, l := range cur {
+		types := sortedTypes(l.Types)
+		old, ok := prevTypes[l.FilePath]
+		delete(prevTypes, l.FilePath)
+		if ok && equalStrings(old, types) {
+			continue
+		}
+		changes = append(changes, &internal.LicenseChange{FilePath: l.FilePath, OldTypes: old, NewTypes: types})
+	}
+	for fp, old := range prevTypes {
+		changes = append(changes, &internal.LicenseChange{FilePath: fp, OldTypes: old})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].FilePath < changes[j].FilePath })
+	return changes
+}
+
+// sortedTypes returns a sorted copy of types. It is never nil, so that an
+// empty list of types can be distinguished from a missing file.
+func sortedTypes(types []string) []string {
+	s := append([]string{}, types...)
+	sort.Strings(s)
+	return s
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLicenseChanges returns the license changes of the versions of the
+// modules in the given series, sorted by descending version and then by file
+// path.
+func (db *DB) GetLicenseChanges(ctx context.Context, seriesPath string) (_ []*internal.LicenseChange, err error) {
+	defer derrors.Wrap(&err, "GetLicenseChanges(ctx, %q)", seriesPath)
+	defer middleware.ElapsedStat(ctx, "GetLicenseChanges")()
+
+	return db.getLicenseChanges(ctx, `
+		WHERE m.series_path = $1
+		ORDER BY m.sort_version DESC, m.module_path, c.file_path`, seriesPath)
+}
+
+// GetRecentLicenseChanges returns the limit most recently detected license
+// changes of all modules.
+func (db *DB) GetRecentLicenseChanges(ctx context.Context, limit int) (_ []*internal.LicenseChange, err error) {
+	defer derrors.Wrap(&err, "GetRecentLicenseChanges(ctx, %d)", limit)
+	defer middleware.ElapsedStat(ctx, "GetRecentLicenseChanges")()
+
+	return db.getLicenseChanges(ctx, `
+		ORDER BY c.created_at DESC, m.module_path, m.sort_version DESC, c.file_path
+		LIMIT $1`, limit)
+}
+
+// getLicenseChanges returns the license changes selected by the given WHERE
+// and ORDER BY clauses, which may refer to the modules table as m and the
+// license_changes table as c.
+func (db *DB) getLicenseChanges(ctx context.Context, clauses string, args ...interface{}) ([]*internal.LicenseChange, error) {
+	query := `
+		SELECT
+			m.module_path,
+			m.version,
+			c.previous_module_path,
+			c.previous_version,
+			c.file_path,
+			c.old_types,
+			c.new_types,
+			c.created_at
+		FROM license_changes c
+		INNER JOIN modules m
+		ON c.module_id = m.id` + clauses
+	var changes []*internal.LicenseChange
+	collect := func(rows *sql.Rows) error {
+		var c internal.LicenseChange
+		if err := rows.Scan(&c.ModulePath, &c.Version, &c.PreviousModulePath, &c.PreviousVersion,
+			&c.FilePath, pq.Array(&c.OldTypes), pq.Array(&c.NewTypes), &c.CreatedAt); err != nil {
+			return err
+		}
+		changes = append(changes, &c)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, args...); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
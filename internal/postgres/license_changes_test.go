@@ -0,0 +1,181 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestDiffLicenses(t *testing.T) {
+	md := func(filePath string, types ...string) *licenses.Metadata {
+		return &licenses.Metadata{FilePath: filePath, Types: types}
+	}
+	prev := []*licenses.Metadata{
+		md("LICENSE", "MIT"),
+		md("COPYING", "Apache-2.0", "BSD-3-Clause"),
+		md("a/LICENSE", "BSD-3-Clause"),
+	}
+	cur := []*licenses.Metadata{
+		md("b/LICENSE", "Apache-2.0"),
+		md("LICENSE", "BUSL-1.1"),
+		md("COPYING", "BSD-3-Clause", "Apache-2.0"),
+	}
+	got := diffLicenses(prev, cur)
+	want := []*internal.LicenseChange{
+		{FilePath: "LICENSE", OldTypes: []string{"MIT"}, NewTypes: []string{"BUSL-1.1"}},
+		{FilePath: "a/LICENSE", OldTypes: []string{"BSD-3-Clause"}},
+		{FilePath: "b/LICENSE", NewTypes: []string{"Apache-2.0"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if !got[1].Removed() || got[1].Added() {
+		t.Errorf("%s: got Removed() = %t, Added() = %t; want true, false", got[1].FilePath, got[1].Removed(), got[1].Added())
+	}
+	if !got[2].Added() || got[2].Removed() {
+		t.Errorf("%s: got Added() = %t, Removed() = %t; want true, false", got[2].FilePath, got[2].Added(), got[2].Removed())
+	}
+}
+
+func TestLicenseChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const modulePath = "example.com/mod"
+	insert := func(modulePath, version string, lics ...*licenses.Metadata) {
+		t.Helper()
+		m := sample.Module(modulePath, version)
+		m.Licenses = nil
+		for _, l := range lics {
+			m.Licenses = append(m.Licenses, &licenses.License{Metadata: l, Contents: []byte(l.Types[0])})
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mit := &licenses.Metadata{FilePath: "LICENSE", Types: []string{"MIT"}}
+	bsd := &licenses.Metadata{FilePath: "LICENSE", Types: []string{"BSD-3-Clause"}}
+	apache := &licenses.Metadata{FilePath: "COPYING", Types: []string{"Apache-2.0"}}
+
+	check := func(want []*internal.LicenseChange) {
+		t.Helper()
+		got, err := testDB.GetLicenseChanges(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(internal.LicenseChange{}, "CreatedAt")); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	insert(modulePath, "v1.0.0", mit)
+	insert(modulePath, "v1.2.0", bsd)
+	// A pseudo-version is not compared with tagged versions.
+	insert(modulePath, "v1.2.1-0.20200101000000-0123456789ab", apache)
+	check([]*internal.LicenseChange{
+		{
+			ModulePath: modulePath, Version: "v1.2.0",
+			PreviousModulePath: modulePath, PreviousVersion: "v1.0.0",
+			FilePath: "LICENSE", OldTypes: []string{"MIT"}, NewTypes: []string{"BSD-3-Clause"},
+		},
+	})
+
+	// Inserting a version between two others updates the changes of the
+	// later one.
+	insert(modulePath, "v1.1.0", mit, apache)
+	check([]*internal.LicenseChange{
+		{
+			ModulePath: modulePath, Version: "v1.2.0",
+			PreviousModulePath: modulePath, PreviousVersion: "v1.1.0",
+			FilePath: "COPYING", OldTypes: []string{"Apache-2.0"},
+		},
+		{
+			ModulePath: modulePath, Version: "v1.2.0",
+			PreviousModulePath: modulePath, PreviousVersion: "v1.1.0",
+			FilePath: "LICENSE", OldTypes: []string{"MIT"}, NewTypes: []string{"BSD-3-Clause"},
+		},
+		{
+			ModulePath: modulePath, Version: "v1.1.0",
+			PreviousModulePath: modulePath, PreviousVersion: "v1.0.0",
+			FilePath: "COPYING", NewTypes: []string{"Apache-2.0"},
+		},
+	})
+
+	// The next major version is compared with the last version of the
+	// previous one.
+	insert(modulePath+"/v2", "v2.0.0", mit)
+	got, err := testDB.GetLicenseChanges(ctx, modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &internal.LicenseChange{
+		ModulePath: modulePath + "/v2", Version: "v2.0.0",
+		PreviousModulePath: modulePath, PreviousVersion: "v1.2.0",
+		FilePath: "LICENSE", OldTypes: []string{"BSD-3-Clause"}, NewTypes: []string{"MIT"},
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d changes, want 4", len(got))
+	}
+	if diff := cmp.Diff(want, got[0], cmpopts.IgnoreFields(internal.LicenseChange{}, "CreatedAt")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	recent, err := testDB.GetRecentLicenseChanges(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 {
+		t.Errorf("got %d recent changes, want 2", len(recent))
+	}
+}
+
+func TestLicenseChangesReprocess(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	insert := func(modulePath, version string, l *licenses.Metadata) {
+		t.Helper()
+		m := sample.Module(modulePath, version)
+		m.Licenses = []*licenses.License{{Metadata: l, Contents: []byte(l.Types[0])}}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recent := func() []*internal.LicenseChange {
+		t.Helper()
+		changes, err := testDB.GetRecentLicenseChanges(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changes
+	}
+	mit := &licenses.Metadata{FilePath: "LICENSE", Types: []string{"MIT"}}
+	bsd := &licenses.Metadata{FilePath: "LICENSE", Types: []string{"BSD-3-Clause"}}
+
+	insert("example.com/a", "v1.0.0", mit)
+	insert("example.com/a", "v1.1.0", bsd)
+	insert("example.com/b", "v1.0.0", mit)
+	insert("example.com/b", "v1.1.0", bsd)
+	before := recent()
+	if len(before) != 2 || before[0].ModulePath != "example.com/b" {
+		t.Fatalf("got %+v, want the change of example.com/b first", before)
+	}
+
+	// Reprocessing a module keeps the creation time of its changes, so the
+	// order of the feed does not change.
+	insert("example.com/a", "v1.1.0", bsd)
+	if diff := cmp.Diff(before, recent()); diff != "" {
+		t.Errorf("recent changes after reprocessing mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// InsertLicenseException inserts e into the license_exceptions table,
+// replacing any exception with the same key.
+func (db *DB) InsertLicenseException(ctx context.Context, e *licenses.Exception) (err error) {
+	defer derrors.Wrap(&err, "DB.InsertLicenseException(ctx, %q)", e.Key)
+
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO license_exceptions (contents_key, types, reviewer, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (contents_key) DO UPDATE
+		SET
+			types = excluded.types,
+			reviewer = excluded.reviewer,
+			reason = excluded.reason,
+			created_at = CURRENT_TIMESTAMP`,
+		e.Key, pq.Array(e.Types), e.Reviewer, e.Reason)
+	return err
+}
+
+// DeleteLicenseException deletes the exception with key from the
+// license_exceptions table. It returns an error wrapping derrors.NotFound if
+// there is none.
+func (db *DB) DeleteLicenseException(ctx context.Context, key string) (err error) {
+	defer derrors.Wrap(&err, "DB.DeleteLicenseException(ctx, %q)", key)
+
+	n, err := db.db.Exec(ctx, `DELETE FROM license_exceptions WHERE contents_key = $1`, key)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return derrors.NotFound
+	}
+	return nil
+}
+
+// GetLicenseExceptions returns all the exceptions in the license_exceptions
+// table, oldest first.
+func (db *DB) GetLicenseExceptions(ctx context.Context) (_ []*licenses.Exception, err error) {
+	defer derrors.Wrap(&err, "DB.GetLicenseExceptions(ctx)")
+
+	query := `
+		SELECT contents_key, types, reviewer, reason, created_at
+		FROM license_exceptions
+		ORDER BY created_at, contents_key`
+	var excs []*licenses.Exception
+	collect := func(rows *sql.Rows) error {
+		var e licenses.Exception
+		if err := rows.Scan(&e.Key, pq.Array(&e.Types), &e.Reviewer, &e.Reason, &e.CreatedAt); err != nil {
+			return err
+		}
+		excs = append(excs, &e)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect); err != nil {
+		return nil, err
+	}
+	return excs, nil
+}
+
+// UpdateModuleVersionStatesForLicenseKey marks the module versions that have
+// a license file whose contents have key (see licenses.ExceptionKey) to be
+// reprocessed, and returns their number. It is used to apply a change to the
+// exception for key.
+//
+// Licenses inserted before their keys were recorded are not matched; the
+// modules they belong to can be reprocessed with
+// UpdateModuleVersionStatesForReprocessing.
+func (db *DB) UpdateModuleVersionStatesForLicenseKey(ctx context.Context, key string) (_ int64, err error) {
+	defer derrors.Wrap(&err, "UpdateModuleVersionStatesForLicenseKey(ctx, %q)", key)
+
+	cond := `(module_path, version) IN (
+		SELECT m.module_path, m.version
+		FROM licenses l
+		INNER JOIN modules m
+		ON l.module_id = m.id
+		WHERE l.contents_key = $3)`
+	var total int64
+	// Only processed modules have licenses.
+	for _, status := range []int{
+		http.StatusOK,
+		derrors.ToStatus(derrors.HasIncompletePackages),
+	} {
+		n, err := db.updateModuleVersionStatesWithStatus(ctx, status, cond, key)
+		if err != nil {
+			return 0, fmt.Errorf("status %d: %v", status, err)
+		}
+		total += n
+	}
+	log.Infof(ctx, "Updated module_version_states with a license whose key is %q; %d affected", key, total)
+	return total, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestLicenseExceptions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	contents := []byte("You may use this code if you are nice.")
+	key := licenses.ExceptionKey(contents)
+
+	// Insert two versions of a module whose license is not detected, and a
+	// module with another license.
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		m := sample.Module(sample.ModulePath, v, "")
+		m.Licenses = []*licenses.License{{
+			Metadata:    &licenses.Metadata{FilePath: "LICENSE", Types: []string{"UNKNOWN"}},
+			Contents:    contents,
+			ContentsKey: key,
+		}}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := testDB.InsertModule(ctx, sample.Module("example.com/other", "v1.0.0", "")); err != nil {
+		t.Fatal(err)
+	}
+	for _, mv := range [][2]string{
+		{sample.ModulePath, "v1.0.0"},
+		{sample.ModulePath, "v1.1.0"},
+		{"example.com/other", "v1.0.0"},
+	} {
+		if err := testDB.UpsertModuleVersionState(ctx, mv[0], mv[1], "appVersion", time.Now(), http.StatusOK, "", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exc := &licenses.Exception{
+		Key:      key,
+		Types:    []string{"Nice"},
+		Reviewer: "gopher",
+		Reason:   "reviewed by legal",
+	}
+	if err := testDB.InsertLicenseException(ctx, exc); err != nil {
+		t.Fatal(err)
+	}
+	// Inserting it again replaces it.
+	exc.Types = []string{"MIT"}
+	if err := testDB.InsertLicenseException(ctx, exc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := testDB.GetLicenseExceptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*licenses.Exception{exc}, got, cmpopts.IgnoreFields(licenses.Exception{}, "CreatedAt")); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+
+	n, err := testDB.UpdateModuleVersionStatesForLicenseKey(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d module versions to reprocess, want 2", n)
+	}
+	for _, mv := range [][3]interface{}{
+		{sample.ModulePath, "v1.0.0", derrors.ToReprocessStatus(http.StatusOK)},
+		{sample.ModulePath, "v1.1.0", derrors.ToReprocessStatus(http.StatusOK)},
+		{"example.com/other", "v1.0.0", http.StatusOK},
+	} {
+		vs, err := testDB.GetModuleVersionState(ctx, mv[0].(string), mv[1].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vs.Status != mv[2].(int) {
+			t.Errorf("%s@%s: got status %d, want %d", mv[0], mv[1], vs.Status, mv[2])
+		}
+	}
+
+	if err := testDB.DeleteLicenseException(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.DeleteLicenseException(ctx, key); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("deleting again: got %v, want NotFound", err)
+	}
+	got, err = testDB.GetLicenseExceptions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d exceptions after deleting, want 0", len(got))
+	}
+}
@@ -49,7 +49,7 @@ func (db *DB) getLicenses(ctx context.Context, fullPath, modulePath string, unit
 	}
 	defer rows.Close()
 
-	moduleLicenses, err := collectLicenses(rows, db.bypassLicenseCheck)
+	moduleLicenses, err := collectLicenses(rows, modulePath, db.bypassLicenseCheck)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +71,7 @@ func (db *DB) getLicenses(ctx context.Context, fullPath, modulePath string, unit
 	}
 	if !db.bypassLicenseCheck {
 		for _, l := range lics {
-			l.RemoveNonRedistributableData()
+			l.RemoveNonRedistributableData(modulePath)
 		}
 	}
 	return lics, nil
@@ -80,8 +80,8 @@ func (db *DB) getLicenses(ctx context.Context, fullPath, modulePath string, unit
 // getModuleLicenses returns all licenses associated with the given module path and
 // version. These are the top-level licenses in the module zip file.
 // It returns an InvalidArgument error if the module path or version is invalid.
-func (db *DB) getModuleLicenses(ctx context.Context, moduleID int) (_ []*licenses.License, err error) {
-	defer derrors.Wrap(&err, "getModuleLicenses(ctx, %d)", moduleID)
+func (db *DB) getModuleLicenses(ctx context.Context, modulePath string, moduleID int) (_ []*licenses.License, err error) {
+	defer derrors.Wrap(&err, "getModuleLicenses(ctx, %q, %d)", modulePath, moduleID)
 
 	query := `
 	SELECT
@@ -96,12 +96,46 @@ func (db *DB) getModuleLicenses(ctx context.Context, moduleID int) (_ []*license
 		return nil, err
 	}
 	defer rows.Close()
-	return collectLicenses(rows, db.bypassLicenseCheck)
+	return collectLicenses(rows, modulePath, db.bypassLicenseCheck)
 }
 
-// collectLicenses converts the sql rows to a list of licenses. The columns
-// must be types, file_path and contents, in that order.
-func collectLicenses(rows *sql.Rows, bypassLicenseCheck bool) ([]*licenses.License, error) {
+// GetLicenseMetadata returns the metadata of all the licenses in the given
+// module version, including those in subdirectories, sorted by file path.
+func (db *DB) GetLicenseMetadata(ctx context.Context, modulePath, resolvedVersion string) (_ []*licenses.Metadata, err error) {
+	defer derrors.Wrap(&err, "GetLicenseMetadata(ctx, %q, %q)", modulePath, resolvedVersion)
+	defer middleware.ElapsedStat(ctx, "GetLicenseMetadata")()
+
+	query := `
+		SELECT
+			l.types,
+			l.file_path,
+			l.coverage
+		FROM licenses l
+		INNER JOIN modules m
+		ON l.module_id = m.id
+		WHERE
+			m.module_path = $1
+			AND m.version = $2
+		ORDER BY l.file_path`
+	var lics []*licenses.Metadata
+	collect := func(rows *sql.Rows) error {
+		var lic licenses.Metadata
+		if err := rows.Scan(pq.Array(&lic.Types), &lic.FilePath, jsonbScanner{&lic.Coverage}); err != nil {
+			return err
+		}
+		lics = append(lics, &lic)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, modulePath, resolvedVersion); err != nil {
+		return nil, err
+	}
+	return lics, nil
+}
+
+// collectLicenses converts the sql rows to a list of licenses of the module at
+// modulePath. The columns must be types, file_path and contents, in that
+// order.
+func collectLicenses(rows *sql.Rows, modulePath string, bypassLicenseCheck bool) ([]*licenses.License, error) {
 	mustHaveColumns(rows, "types", "file_path", "contents", "coverage")
 	var lics []*licenses.License
 	for rows.Next() {
@@ -114,7 +148,7 @@ func collectLicenses(rows *sql.Rows, bypassLicenseCheck bool) ([]*licenses.Licen
 		}
 		lic.Types = licenseTypes
 		if !bypassLicenseCheck {
-			lic.RemoveNonRedistributableData()
+			lic.RemoveNonRedistributableData(modulePath)
 		}
 		lics = append(lics, lic)
 	}
@@ -154,7 +154,7 @@ func TestGetModuleLicenses(t *testing.T) {
 	if err := testDB.db.QueryRow(ctx, query, modulePath, testModule.Version).Scan(&moduleID); err != nil {
 		t.Fatal(err)
 	}
-	got, err := testDB.getModuleLicenses(ctx, moduleID)
+	got, err := testDB.getModuleLicenses(ctx, modulePath, moduleID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -163,6 +163,20 @@ func TestGetModuleLicenses(t *testing.T) {
 	if diff := cmp.Diff(wantLicenses, got); diff != "" {
 		t.Errorf("testDB.getModuleLicenses(ctx, %q, %q) mismatch (-want +got):\n%s", modulePath, testModule.Version, diff)
 	}
+
+	// GetLicenseMetadata returns the metadata of all licenses, sorted by path.
+	gotMeta, err := testDB.GetLicenseMetadata(ctx, modulePath, testModule.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMeta := []*licenses.Metadata{
+		testModule.Licenses[0].Metadata,
+		testModule.Licenses[2].Metadata,
+		testModule.Licenses[1].Metadata,
+	}
+	if diff := cmp.Diff(wantMeta, gotMeta); diff != "" {
+		t.Errorf("testDB.GetLicenseMetadata(ctx, %q, %q) mismatch (-want +got):\n%s", modulePath, testModule.Version, diff)
+	}
 }
 
 func TestGetLicensesBypass(t *testing.T) {
@@ -0,0 +1,114 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+)
+
+// GetRequirementEdges returns the edges of the module requirement graph that
+// start at modulePath@version, or, if transitive is true, all the edges that
+// can be reached from it through module versions that have been processed.
+// No more than limit edges are returned, in no particular order.
+//
+// The requirement graph may have cycles. Each edge is returned once.
+func (db *DB) GetRequirementEdges(ctx context.Context, modulePath, version string, transitive bool, limit int) (_ []*internal.RequirementEdge, err error) {
+	defer derrors.Wrap(&err, "GetRequirementEdges(ctx, %q, %q, %t, %d)", modulePath, version, transitive, limit)
+	defer middleware.ElapsedStat(ctx, "GetRequirementEdges")()
+
+	const direct = `
+		SELECT m.module_path, m.version, r.required_path, r.required_version, r.indirect
+		FROM module_requires r
+		INNER JOIN modules m
+		ON r.module_id = m.id
+		WHERE m.module_path = $1 AND m.version = $2`
+	query := direct + ` LIMIT $3`
+	if transitive {
+		// UNION, unlike UNION ALL, discards the edges that were already
+		// found, so the recursion ends even if the graph has cycles. Since
+		// rows of a WITH query are only computed as they are fetched, the
+		// LIMIT also bounds the work done for large graphs.
+		query = `
+			WITH RECURSIVE edges(module_path, version, required_path, required_version, indirect) AS (` +
+			direct + `
+				UNION
+				SELECT m.module_path, m.version, r.required_path, r.required_version, r.indirect
+				FROM edges e
+				INNER JOIN modules m
+				ON m.module_path = e.required_path AND m.version = e.required_version
+				INNER JOIN module_requires r
+				ON r.module_id = m.id
+			)
+			SELECT module_path, version, required_path, required_version, indirect
+			FROM edges
+			LIMIT $3`
+	}
+	return collectRequirementEdges(ctx, db, query, modulePath, version, limit)
+}
+
+// GetRequiredBy returns the edges of the module requirement graph that end at
+// a version of modulePath and start at the latest processed version of
+// another module, so the result says which modules require modulePath in
+// their latest versions, and which version of it they require. The latest
+// version is chosen as elsewhere on the site, preferring unretracted versions,
+// and releases to pre-releases and pseudo-versions. Modules that required
+// modulePath only in earlier versions are omitted. The edges are sorted by the
+// path of the requiring module. No more than limit edges are returned.
+func (db *DB) GetRequiredBy(ctx context.Context, modulePath string, limit int) (_ []*internal.RequirementEdge, err error) {
+	defer derrors.Wrap(&err, "GetRequiredBy(ctx, %q, %d)", modulePath, limit)
+	defer middleware.ElapsedStat(ctx, "GetRequiredBy")()
+
+	// The latest version of each module that has ever required modulePath
+	// is found first, and only then are its requirements checked.
+	query := `
+		WITH paths AS (
+			SELECT DISTINCT m2.module_path
+			FROM module_requires r2
+			INNER JOIN modules m2
+			ON r2.module_id = m2.id
+			WHERE r2.required_path = $1 AND m2.module_path <> $1
+		), latest AS (
+			SELECT (
+				SELECT m.id
+				FROM modules m
+				WHERE m.module_path = p.module_path` + orderByLatestStmt + `
+				LIMIT 1
+			) AS id
+			FROM paths p
+		)
+		SELECT m.module_path, m.version, r.required_path, r.required_version, r.indirect
+		FROM latest l
+		INNER JOIN modules m
+		ON m.id = l.id
+		INNER JOIN module_requires r
+		ON r.module_id = l.id
+		WHERE r.required_path = $1
+		ORDER BY m.module_path
+		LIMIT $2`
+	return collectRequirementEdges(ctx, db, query, modulePath, limit)
+}
+
+// collectRequirementEdges runs query, which must select the columns of a
+// requirement edge, and returns the edges.
+func collectRequirementEdges(ctx context.Context, db *DB, query string, args ...interface{}) ([]*internal.RequirementEdge, error) {
+	var edges []*internal.RequirementEdge
+	collect := func(rows *sql.Rows) error {
+		var e internal.RequirementEdge
+		if err := rows.Scan(&e.ModulePath, &e.Version, &e.RequiredPath, &e.RequiredVersion, &e.Indirect); err != nil {
+			return err
+		}
+		edges = append(edges, &e)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, args...); err != nil {
+		return nil, err
+	}
+	return edges, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestRequirementGraph(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	// example.com/a@v1.0.0 requires b@v1.0.0, which requires a@v1.0.0 and
+	// c@v1.0.0, which has not been processed. example.com/d requires a
+	// version of a in each of its versions. example.com/e required a, but
+	// its latest version no longer does. The latest version of example.com/f
+	// is its release, not the pseudo-version with a higher semantic version.
+	insert := func(modulePath, version string, reqs ...*internal.GoModRequire) {
+		t.Helper()
+		m := sample.Module(modulePath, version, "")
+		m.GoMod = &internal.GoMod{Requires: reqs}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := func(modulePath, version string) *internal.GoModRequire {
+		return &internal.GoModRequire{ModulePath: modulePath, Version: version}
+	}
+	insert("example.com/a", "v1.0.0", req("example.com/b", "v1.0.0"))
+	insert("example.com/b", "v1.0.0", req("example.com/a", "v1.0.0"), req("example.com/c", "v1.0.0"))
+	insert("example.com/d", "v1.0.0", req("example.com/a", "v0.9.0"))
+	insert("example.com/d", "v1.1.0", req("example.com/a", "v1.0.0"))
+	insert("example.com/e", "v1.0.0", req("example.com/a", "v1.0.0"))
+	insert("example.com/e", "v1.1.0", req("example.com/b", "v1.0.0"))
+	insert("example.com/f", "v1.0.0", req("example.com/a", "v1.0.0"))
+	insert("example.com/f", "v1.1.1-0.20200101000000-0123456789ab", req("example.com/a", "v0.9.0"))
+
+	edge := func(from, fromVersion, to, toVersion string) *internal.RequirementEdge {
+		return &internal.RequirementEdge{ModulePath: from, Version: fromVersion, RequiredPath: to, RequiredVersion: toVersion}
+	}
+	sortEdges := func(edges []*internal.RequirementEdge) {
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].ModulePath+edges[i].RequiredPath < edges[j].ModulePath+edges[j].RequiredPath
+		})
+	}
+	for _, test := range []struct {
+		name       string
+		transitive bool
+		limit      int
+		want       []*internal.RequirementEdge
+	}{
+		{"direct", false, 10, []*internal.RequirementEdge{
+			edge("example.com/a", "v1.0.0", "example.com/b", "v1.0.0"),
+		}},
+		{"transitive", true, 10, []*internal.RequirementEdge{
+			edge("example.com/a", "v1.0.0", "example.com/b", "v1.0.0"),
+			edge("example.com/b", "v1.0.0", "example.com/a", "v1.0.0"),
+			edge("example.com/b", "v1.0.0", "example.com/c", "v1.0.0"),
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := testDB.GetRequirementEdges(ctx, "example.com/a", "v1.0.0", test.transitive, test.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sortEdges(got)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+	got, err := testDB.GetRequirementEdges(ctx, "example.com/a", "v1.0.0", true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("with limit 2: got %d edges, want 2", len(got))
+	}
+
+	got, err = testDB.GetRequiredBy(ctx, "example.com/a", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.RequirementEdge{
+		edge("example.com/b", "v1.0.0", "example.com/a", "v1.0.0"),
+		edge("example.com/d", "v1.1.0", "example.com/a", "v1.0.0"),
+		edge("example.com/f", "v1.0.0", "example.com/a", "v1.0.0"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetRequiredBy mismatch (-want +got):\n%s", diff)
+	}
+}
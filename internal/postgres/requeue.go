@@ -35,15 +35,7 @@ func (db *DB) UpdateModuleVersionStatesForReprocessing(ctx context.Context, appV
 }
 
 func (db *DB) UpdateModuleVersionStatesWithStatus(ctx context.Context, status int, appVersion string) (err error) {
-	query := `UPDATE module_version_states
-			SET
-				status = $2,
-				next_processed_after = CURRENT_TIMESTAMP,
-				last_processed_at = NULL
-			WHERE
-				app_version < $1
-				AND status = $3;`
-	affected, err := db.db.Exec(ctx, query, appVersion, derrors.ToReprocessStatus(status), status)
+	affected, err := db.updateModuleVersionStatesWithStatus(ctx, status, "app_version < $3", appVersion)
 	if err != nil {
 		return err
 	}
@@ -53,6 +45,22 @@ func (db *DB) UpdateModuleVersionStatesWithStatus(ctx context.Context, status in
 	return nil
 }
 
+// updateModuleVersionStatesWithStatus marks the module versions with status
+// that satisfy cond to be reprocessed, and returns their number. The
+// arguments of cond are args, which it refers to starting with $3.
+func (db *DB) updateModuleVersionStatesWithStatus(ctx context.Context, status int, cond string, args ...interface{}) (int64, error) {
+	query := `UPDATE module_version_states
+			SET
+				status = $1,
+				next_processed_after = CURRENT_TIMESTAMP,
+				last_processed_at = NULL
+			WHERE
+				status = $2
+				AND ` + cond + `;`
+	args = append([]interface{}{derrors.ToReprocessStatus(status), status}, args...)
+	return db.db.Exec(ctx, query, args...)
+}
+
 // largeModulePackageThresold represents the package threshold at which it
 // becomes difficult to process packages. Modules with more than this number
 // of packages are generally different versions or forks of kubernetes,
@@ -118,18 +118,38 @@ func (db *DB) Search(ctx context.Context, q string, limit, offset, maxResultCoun
 	if err != nil {
 		return nil, err
 	}
-	// Filter out excluded paths.
-	var results []*internal.SearchResult
-	for _, r := range resp.results {
+	return db.removeExcluded(ctx, resp.results)
+}
+
+// SearchWithMinDocCoverage is like Search, but only returns packages at least
+// minCoverage of whose exported identifiers have doc comments. minCoverage is
+// a fraction between 0 and 1. Since popular search cannot filter its results,
+// only deep search is used.
+func (db *DB) SearchWithMinDocCoverage(ctx context.Context, q string, limit, offset, maxResultCount int, minCoverage float64) (_ []*internal.SearchResult, err error) {
+	defer derrors.Wrap(&err, "DB.SearchWithMinDocCoverage(ctx, %q, %d, %d, %g)", q, limit, offset, minCoverage)
+	deep := func(db *DB, ctx context.Context, q string, limit, offset, maxResultCount int) searchResponse {
+		return db.deepSearchWithMinDocCoverage(ctx, q, limit, offset, maxResultCount, minCoverage)
+	}
+	resp, err := db.hedgedSearch(ctx, q, limit, offset, maxResultCount, map[string]searcher{"deep": deep}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return db.removeExcluded(ctx, resp.results)
+}
+
+// removeExcluded returns the results whose paths are not excluded.
+func (db *DB) removeExcluded(ctx context.Context, results []*internal.SearchResult) ([]*internal.SearchResult, error) {
+	var filtered []*internal.SearchResult
+	for _, r := range results {
 		ex, err := db.IsExcluded(ctx, r.PackagePath)
 		if err != nil {
 			return nil, err
 		}
 		if !ex {
-			results = append(results, r)
+			filtered = append(filtered, r)
 		}
 	}
-	return results, nil
+	return filtered, nil
 }
 
 // Penalties to search scores, applied as multipliers to the score.
@@ -140,6 +160,9 @@ const (
 	// Start this off gently (close to 1), but consider lowering
 	// it as time goes by and more of the ecosystem converts to modules.
 	noGoModPenalty = 0.8
+	// Module is marked deprecated in its go.mod file, or package is marked
+	// deprecated in its documentation.
+	deprecatedPenalty = 0.5
 )
 
 // scoreExpr is the expression that computes the search score.
@@ -151,6 +174,8 @@ const (
 //   dramatic: being 2x as popular only has an additive effect.
 // - A penalty factor for non-redistributable modules, since a lot of
 //   details cannot be displayed.
+// - A penalty factor for deprecated modules and packages, so that their
+//   replacements rank higher.
 // The first argument to ts_rank is an array of weights for the four tsvector sections,
 // in the order D, C, B, A.
 // The weights below match the defaults except for B.
@@ -158,8 +183,9 @@ var scoreExpr = fmt.Sprintf(`
 		ts_rank('{0.1, 0.2, 1.0, 1.0}', tsv_search_tokens, websearch_to_tsquery($1)) *
 		ln(exp(1)+imported_by_count) *
 		CASE WHEN redistributable THEN 1 ELSE %f END *
-		CASE WHEN COALESCE(has_go_mod, true) THEN 1 ELSE %f END
-	`, nonRedistributablePenalty, noGoModPenalty)
+		CASE WHEN COALESCE(has_go_mod, true) THEN 1 ELSE %f END *
+		CASE WHEN deprecated THEN %f ELSE 1 END
+	`, nonRedistributablePenalty, noGoModPenalty, deprecatedPenalty)
 
 // hedgedSearch executes multiple search methods and returns the first
 // available result.
@@ -222,6 +248,19 @@ const hllRegisterCount = 128
 // deepSearch searches all packages for the query. It is slower, but results
 // are always valid.
 func (db *DB) deepSearch(ctx context.Context, q string, limit, offset, maxResultCount int) searchResponse {
+	return db.deepSearchWithMinDocCoverage(ctx, q, limit, offset, maxResultCount, 0)
+}
+
+// deepSearchWithMinDocCoverage is like deepSearch, but if minCoverage is
+// positive it only returns packages whose documentation coverage is known
+// and at least minCoverage.
+func (db *DB) deepSearchWithMinDocCoverage(ctx context.Context, q string, limit, offset, maxResultCount int, minCoverage float64) searchResponse {
+	args := []interface{}{q, limit, offset}
+	var coverageFilter string
+	if minCoverage > 0 {
+		coverageFilter = "AND doc_coverage >= $4"
+		args = append(args, minCoverage)
+	}
 	query := fmt.Sprintf(`
 		SELECT *, COUNT(*) OVER() AS total
 		FROM (
@@ -235,6 +274,7 @@ func (db *DB) deepSearch(ctx context.Context, q string, limit, offset, maxResult
 				FROM
 					search_documents
 				WHERE tsv_search_tokens @@ websearch_to_tsquery($1)
+				%s
 				ORDER BY
 					score DESC,
 					commit_time DESC,
@@ -242,7 +282,7 @@ func (db *DB) deepSearch(ctx context.Context, q string, limit, offset, maxResult
 		) r
 		WHERE r.score > 0.1
 		LIMIT $2
-		OFFSET $3`, scoreExpr)
+		OFFSET $3`, scoreExpr, coverageFilter)
 	var results []*internal.SearchResult
 	collect := func(rows *sql.Rows) error {
 		var r internal.SearchResult
@@ -253,7 +293,7 @@ func (db *DB) deepSearch(ctx context.Context, q string, limit, offset, maxResult
 		results = append(results, &r)
 		return nil
 	}
-	err := db.db.RunQuery(ctx, query, collect, q, limit, offset)
+	err := db.db.RunQuery(ctx, query, collect, args...)
 	if err != nil {
 		results = nil
 	}
@@ -278,7 +318,7 @@ func (db *DB) popularSearch(ctx context.Context, searchQuery string, limit, offs
 			commit_time,
 			imported_by_count,
 			score
-		FROM popular_search($1, $2, $3, $4, $5)`
+		FROM popular_search($1, $2, $3, $4, $5, $6)`
 	var results []*internal.SearchResult
 	collect := func(rows *sql.Rows) error {
 		var r internal.SearchResult
@@ -289,7 +329,7 @@ func (db *DB) popularSearch(ctx context.Context, searchQuery string, limit, offs
 		results = append(results, &r)
 		return nil
 	}
-	err := db.db.RunQuery(ctx, query, collect, searchQuery, limit, offset, nonRedistributablePenalty, noGoModPenalty)
+	err := db.db.RunQuery(ctx, query, collect, searchQuery, limit, offset, nonRedistributablePenalty, noGoModPenalty, deprecatedPenalty)
 	if err != nil {
 		results = nil
 	}
@@ -335,6 +375,7 @@ func (db *DB) addPackageDataToSearchResults(ctx context.Context, results []*inte
 			u.path,
 			u.name,
 			d.synopsis,
+			m.deprecated OR COALESCE(d.deprecated, FALSE),
 			u.license_types,
 			u.redistributable
 		FROM
@@ -351,9 +392,9 @@ func (db *DB) addPackageDataToSearchResults(ctx context.Context, results []*inte
 		var (
 			path, name, synopsis string
 			licenseTypes         []string
-			redist               bool
+			redist, deprecated   bool
 		)
-		if err := rows.Scan(&path, &name, database.NullIsEmpty(&synopsis), pq.Array(&licenseTypes), &redist); err != nil {
+		if err := rows.Scan(&path, &name, database.NullIsEmpty(&synopsis), &deprecated, pq.Array(&licenseTypes), &redist); err != nil {
 			return fmt.Errorf("rows.Scan(): %v", err)
 		}
 		r, ok := resultMap[path]
@@ -361,6 +402,7 @@ func (db *DB) addPackageDataToSearchResults(ctx context.Context, results []*inte
 			return fmt.Errorf("BUG: unexpected package path: %q", path)
 		}
 		r.Name = name
+		r.Deprecated = deprecated
 		if redist || db.bypassLicenseCheck {
 			r.Synopsis = synopsis
 		}
@@ -386,6 +428,8 @@ var upsertSearchStatement = fmt.Sprintf(`
 		version_updated_at,
 		commit_time,
 		has_go_mod,
+		deprecated,
+		doc_coverage,
 		tsv_search_tokens,
 		hll_register,
 		hll_leading_zeros
@@ -401,6 +445,12 @@ var upsertSearchStatement = fmt.Sprintf(`
 		CURRENT_TIMESTAMP,
 		m.commit_time,
 		m.has_go_mod,
+		m.deprecated OR COALESCE(d.deprecated, FALSE),
+		CASE
+			WHEN d.num_exported IS NULL THEN NULL
+			WHEN d.num_exported = 0 THEN 1
+			ELSE d.num_documented::real / d.num_exported
+		END,
 		(
 			SETWEIGHT(TO_TSVECTOR('path_tokens', $2), 'A') ||
 			SETWEIGHT(TO_TSVECTOR($3), 'B') ||
@@ -434,6 +484,8 @@ var upsertSearchStatement = fmt.Sprintf(`
 		redistributable=excluded.redistributable,
 		commit_time=excluded.commit_time,
 		has_go_mod=excluded.has_go_mod,
+		deprecated=excluded.deprecated,
+		doc_coverage=excluded.doc_coverage,
 		tsv_search_tokens=excluded.tsv_search_tokens,
 		-- the hll fields are functions of path, so they don't change
 		version_updated_at=(
@@ -0,0 +1,55 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+// DB implements source.DiscoveryCache.
+var _ source.DiscoveryCache = (*DB)(nil)
+
+// GetSourceDiscovery returns the cached meta tag discovery result for
+// modulePath, if there is one that has not expired.
+func (db *DB) GetSourceDiscovery(ctx context.Context, modulePath string) (_ []byte, found bool, err error) {
+	defer derrors.Wrap(&err, "DB.GetSourceDiscovery(ctx, %q)", modulePath)
+
+	var data []byte
+	err = db.db.QueryRow(ctx, `
+		SELECT data
+		FROM source_discovery
+		WHERE module_path = $1 AND expires_at > CURRENT_TIMESTAMP`,
+		modulePath).Scan(&data)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// PutSourceDiscovery caches the meta tag discovery result for modulePath until
+// expires.
+func (db *DB) PutSourceDiscovery(ctx context.Context, modulePath string, data []byte, expires time.Time) (err error) {
+	defer derrors.Wrap(&err, "DB.PutSourceDiscovery(ctx, %q)", modulePath)
+
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO source_discovery (module_path, data, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (module_path)
+		DO UPDATE SET
+			data = excluded.data,
+			expires_at = excluded.expires_at,
+			updated_at = CURRENT_TIMESTAMP`,
+		modulePath, data, expires)
+	return err
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSourceDiscovery(t *testing.T) {
+	defer ResetTestDB(testDB, t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	check := func(modulePath, want string, wantFound bool) {
+		t.Helper()
+		got, found, err := testDB.GetSourceDiscovery(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != wantFound || string(got) != want {
+			t.Errorf("%s: got (%q, %t), want (%q, %t)", modulePath, got, found, want, wantFound)
+		}
+	}
+
+	future := time.Now().Add(time.Hour)
+	if err := testDB.PutSourceDiscovery(ctx, "example.com/a", []byte(`{"RepoURL": "x"}`), future); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDB.PutSourceDiscovery(ctx, "example.com/b", []byte(`{"NotFound": "y"}`), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	check("example.com/a", `{"RepoURL": "x"}`, true)
+	check("example.com/b", "", false) // expired
+	check("example.com/c", "", false)
+
+	// Putting again replaces the data and expiration.
+	if err := testDB.PutSourceDiscovery(ctx, "example.com/b", []byte(`{"RepoURL": "z"}`), future); err != nil {
+		t.Fatal(err)
+	}
+	check("example.com/b", `{"RepoURL": "z"}`, true)
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/testing/sample"
 )
@@ -58,3 +59,57 @@ func TestGetStdlibPaths(t *testing.T) {
 		t.Errorf("got %v, want %v", got, want)
 	}
 }
+
+func TestDeleteOldStdlibMasterVersions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	const (
+		release   = "v1.15.0"
+		oldMaster = "v0.0.0-20200101000000-0123456789ab"
+		newMaster = "v0.0.0-20200102000000-abcdef012345"
+	)
+	for _, v := range []string{release, oldMaster, newMaster} {
+		m := sample.Module(stdlib.ModulePath, v, "errors")
+		for _, p := range m.Packages() {
+			p.Imports = nil
+		}
+		if err := testDB.InsertModule(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+		if err := testDB.UpsertVersionMap(ctx, &internal.VersionMap{
+			ModulePath:       stdlib.ModulePath,
+			RequestedVersion: v,
+			ResolvedVersion:  v,
+			Status:           200,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := testDB.DeleteOldStdlibMasterVersions(ctx, newMaster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{oldMaster}; !cmp.Equal(deleted, want) {
+		t.Errorf("deleted %v, want %v", deleted, want)
+	}
+	for _, test := range []struct {
+		version string
+		want    bool
+	}{
+		{release, true},
+		{oldMaster, false},
+		{newMaster, true},
+	} {
+		_, err := testDB.GetModuleInfo(ctx, stdlib.ModulePath, test.version)
+		if got := err == nil; got != test.want {
+			t.Errorf("%s: exists = %t, want %t (err = %v)", test.version, got, test.want, err)
+		}
+		_, err = testDB.GetVersionMap(ctx, stdlib.ModulePath, test.version)
+		if got := err == nil; got != test.want {
+			t.Errorf("%s: version map exists = %t, want %t (err = %v)", test.version, got, test.want, err)
+		}
+	}
+}
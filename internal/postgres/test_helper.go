@@ -119,6 +119,15 @@ func ResetTestDB(db *DB, t *testing.T) {
 		if _, err := tx.Exec(ctx, `TRUNCATE excluded_prefixes;`); err != nil {
 			return err
 		}
+		if _, err := tx.Exec(ctx, `TRUNCATE source_discovery;`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `TRUNCATE license_exceptions;`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `TRUNCATE vulns CASCADE;`); err != nil {
+			return err
+		}
 		return nil
 	}); err != nil {
 		t.Fatalf("error resetting test DB: %v", err)
@@ -56,6 +56,10 @@ func (db *DB) GetUnitMeta(ctx context.Context, fullPath, requestedModulePath, re
 		&um.CommitTime,
 		jsonbScanner{&um.SourceInfo},
 		&um.HasGoMod,
+		&um.Deprecated,
+		&um.DeprecationComment,
+		&um.Retracted,
+		&um.RetractionRationale,
 		&um.Name,
 		&um.IsRedistributable,
 		pq.Array(&licenseTypes),
@@ -87,6 +91,10 @@ func getUnitMetaQuery(fullPath, requestedModulePath, requestedVersion string) sq
 		"m.commit_time",
 		"m.source_info",
 		"m.has_go_mod",
+		"m.deprecated",
+		"m.deprecation_comment",
+		"m.retracted",
+		"m.retraction_rationale",
 		"u.name",
 		"u.redistributable",
 		"u.license_types",
@@ -116,6 +124,10 @@ func getUnitMetaQuery(fullPath, requestedModulePath, requestedVersion string) sq
 		"m.commit_time",
 		"m.source_info",
 		"m.has_go_mod",
+		"m.deprecated",
+		"m.deprecation_comment",
+		"m.retracted",
+		"m.retraction_rationale",
 		"u.id AS unit_id",
 	).From("modules m").
 		Join("units u ON u.module_id = m.id").
@@ -135,6 +147,10 @@ func legacyGetUnitMetaQuery(fullPath, requestedModulePath, requestedVersion stri
 		"m.commit_time",
 		"m.source_info",
 		"m.has_go_mod",
+		"m.deprecated",
+		"m.deprecation_comment",
+		"m.retracted",
+		"m.retraction_rationale",
 		"u.name",
 		"u.redistributable",
 		"u.license_types",
@@ -154,7 +170,8 @@ func legacyGetUnitMetaQuery(fullPath, requestedModulePath, requestedVersion stri
 }
 
 // orderByLatest orders paths according to the go command.
-// Versions are ordered by:
+// Versions that are not retracted are preferred to those that are. Then
+// versions are ordered by:
 // (1) release (non-incompatible)
 // (2) prerelease (non-incompatible)
 // (3) release, incompatible
@@ -163,6 +180,13 @@ func legacyGetUnitMetaQuery(fullPath, requestedModulePath, requestedVersion stri
 // They are then sorted based on semver, then decreasing module path length (so
 // that nested modules are preferred).
 func orderByLatest(q squirrel.SelectBuilder) squirrel.SelectBuilder {
+	return orderByLatestIgnoringRetractions(q.OrderBy("m.retracted"))
+}
+
+// orderByLatestIgnoringRetractions is like orderByLatest, but does not
+// consider whether versions are retracted. It determines the version whose
+// go.mod file is authoritative for retractions and deprecation.
+func orderByLatestIgnoringRetractions(q squirrel.SelectBuilder) squirrel.SelectBuilder {
 	return q.OrderBy(
 		`CASE
 			WHEN m.version_type = 'release' AND NOT m.incompatible THEN 1
@@ -178,6 +202,7 @@ func orderByLatest(q squirrel.SelectBuilder) squirrel.SelectBuilder {
 
 const orderByLatestStmt = `
 			ORDER BY
+				m.retracted,
 				CASE
 					WHEN m.version_type = 'release' AND NOT m.incompatible THEN 1
 					WHEN m.version_type = 'prerelease' AND NOT m.incompatible THEN 2
@@ -280,6 +305,12 @@ func (db *DB) getImports(ctx context.Context, unitID int) (_ []string, err error
 	return imports, nil
 }
 
+// GetPackagesInModule returns the metadata of the packages in the given module
+// version, sorted by path.
+func (db *DB) GetPackagesInModule(ctx context.Context, modulePath, resolvedVersion string) (_ []*internal.PackageMeta, err error) {
+	return db.getPackagesInUnit(ctx, modulePath, modulePath, resolvedVersion)
+}
+
 // getPackagesInUnit returns all of the packages in a unit from a
 // module version, including the package that lives at fullPath, if present.
 func (db *DB) getPackagesInUnit(ctx context.Context, fullPath, modulePath, resolvedVersion string) (_ []*internal.PackageMeta, err error) {
@@ -292,6 +323,10 @@ func (db *DB) getPackagesInUnit(ctx context.Context, fullPath, modulePath, resol
 			u.name,
 			u.redistributable,
 			d.synopsis,
+			d.deprecated,
+			d.has_package_doc,
+			d.num_exported,
+			d.num_documented,
 			u.license_types,
 			u.license_paths
 		FROM modules m
@@ -306,15 +341,21 @@ func (db *DB) getPackagesInUnit(ctx context.Context, fullPath, modulePath, resol
 	var packages []*internal.PackageMeta
 	collect := func(rows *sql.Rows) error {
 		var (
-			pkg          internal.PackageMeta
-			licenseTypes []string
-			licensePaths []string
+			pkg                        internal.PackageMeta
+			licenseTypes               []string
+			licensePaths               []string
+			hasDoc                     sql.NullBool
+			numExported, numDocumented sql.NullInt64
 		)
 		if err := rows.Scan(
 			&pkg.Path,
 			&pkg.Name,
 			&pkg.IsRedistributable,
 			&pkg.Synopsis,
+			&pkg.Deprecated,
+			&hasDoc,
+			&numExported,
+			&numDocumented,
 			pq.Array(&licenseTypes),
 			pq.Array(&licensePaths),
 		); err != nil {
@@ -326,6 +367,7 @@ func (db *DB) getPackagesInUnit(ctx context.Context, fullPath, modulePath, resol
 				return err
 			}
 			pkg.Licenses = lics
+			pkg.Coverage = makeDocCoverage(hasDoc, numExported, numDocumented)
 			packages = append(packages, &pkg)
 		}
 		return nil
@@ -352,6 +394,10 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta) (
 			d.goos,
 			d.goarch,
 			d.synopsis,
+			COALESCE(d.deprecated, FALSE),
+			d.has_package_doc,
+			d.num_exported,
+			d.num_documented,
 			d.source,
 			r.file_path,
 			r.contents,
@@ -381,14 +427,20 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta) (
 			AND m.version = $3;`
 
 	var (
-		d internal.Documentation
-		r internal.Readme
-		u internal.Unit
+		d                          internal.Documentation
+		r                          internal.Readme
+		u                          internal.Unit
+		hasDoc                     sql.NullBool
+		numExported, numDocumented sql.NullInt64
 	)
 	err = db.db.QueryRow(ctx, query, um.Path, um.ModulePath, um.Version).Scan(
 		database.NullIsEmpty(&d.GOOS),
 		database.NullIsEmpty(&d.GOARCH),
 		database.NullIsEmpty(&d.Synopsis),
+		&d.Deprecated,
+		&hasDoc,
+		&numExported,
+		&numDocumented,
 		&d.Source,
 		database.NullIsEmpty(&r.Filepath),
 		database.NullIsEmpty(&r.Contents),
@@ -400,6 +452,7 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta) (
 		return nil, derrors.NotFound
 	case nil:
 		if d.GOOS != "" {
+			d.Coverage = makeDocCoverage(hasDoc, numExported, numDocumented)
 			u.Documentation = &d
 		}
 		if r.Filepath != "" {
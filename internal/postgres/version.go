@@ -13,12 +13,16 @@ import (
 	"github.com/Masterminds/squirrel"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/version"
 )
 
 // GetVersionsForPath returns a list of tagged versions sorted in
 // descending semver order if any exist. If none, it returns the 10 most
 // recent from a list of pseudo-versions sorted in descending semver order.
+//
+// For the standard library, the most recent pseudo-version, which is a commit
+// on the master branch of the Go repo, precedes the tagged versions.
 func (db *DB) GetVersionsForPath(ctx context.Context, path string) (_ []*internal.ModuleInfo, err error) {
 	defer derrors.Wrap(&err, "GetVersionsForPath(ctx, %q)", path)
 
@@ -27,6 +31,16 @@ func (db *DB) GetVersionsForPath(ctx context.Context, path string) (_ []*interna
 		return nil, err
 	}
 	if len(versions) != 0 {
+		if !stdlib.Contains(path) {
+			return versions, nil
+		}
+		pseudos, err := getPathVersions(ctx, db, path, version.TypePseudo)
+		if err != nil {
+			return nil, err
+		}
+		if len(pseudos) > 0 {
+			versions = append([]*internal.ModuleInfo{pseudos[0]}, versions...)
+		}
 		return versions, nil
 	}
 	versions, err = getPathVersions(ctx, db, path, version.TypePseudo)
@@ -49,7 +63,11 @@ func getPathVersions(ctx context.Context, db *DB, path string, versionTypes ...v
 		m.commit_time,
 		m.redistributable,
 		m.has_go_mod,
-		m.source_info
+		m.source_info,
+		m.deprecated,
+		m.deprecation_comment,
+		m.retracted,
+		m.retraction_rationale
 	FROM modules m
 	INNER JOIN units u
 		ON u.module_id = m.id
@@ -34,6 +34,8 @@ func TestGetVersions(t *testing.T) {
 		testModules           = []*internal.Module{
 			sample.Module(stdlib.ModulePath, "v1.15.0-beta.1", "cmd/go"),
 			sample.Module(stdlib.ModulePath, "v1.14.6", "cmd/go"),
+			sample.Module(stdlib.ModulePath, "v0.0.0-20200801120000-000000000000", "cmd/go"),
+			sample.Module(stdlib.ModulePath, "v0.0.0-20200901120000-000000000000", "cmd/go"),
 			sample.Module(taggedModuleV3, "v3.2.0-beta", "bar"),
 			sample.Module(taggedModuleV3, "v3.2.0-alpha.2", "bar"),
 			sample.Module(taggedModuleV3, "v3.2.0-alpha.1", "bar"),
@@ -66,7 +68,13 @@ func TestGetVersions(t *testing.T) {
 		}
 	}
 
+	// Only the most recent pseudo-version of std is expected, before the
+	// tagged versions.
 	stdModuleVersions := []*internal.ModuleInfo{
+		{
+			ModulePath: stdlib.ModulePath,
+			Version:    "v0.0.0-20200901120000-000000000000",
+		},
 		{
 			ModulePath: stdlib.ModulePath,
 			Version:    "v1.15.0-beta.1",
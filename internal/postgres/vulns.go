@@ -0,0 +1,184 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// UpdateVulnEntries makes the vulns table hold exactly entries, which are the
+// advisories of a vulnerability database. Entries whose modification time
+// has not changed are left alone. It returns the number of entries that were
+// added or changed, and the number that were removed.
+func (db *DB) UpdateVulnEntries(ctx context.Context, entries []*vuln.Entry) (changed, removed int, err error) {
+	defer derrors.Wrap(&err, "UpdateVulnEntries(ctx, %d entries)", len(entries))
+
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		changed, removed = 0, 0
+		modified := map[string]time.Time{}
+		collect := func(rows *sql.Rows) error {
+			var (
+				id string
+				t  time.Time
+			)
+			if err := rows.Scan(&id, &t); err != nil {
+				return err
+			}
+			modified[id] = t
+			return nil
+		}
+		if err := tx.RunQuery(ctx, `SELECT id, modified FROM vulns`, collect); err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, e := range entries {
+			seen[e.ID] = true
+			if t, ok := modified[e.ID]; ok && t.Equal(e.Modified) {
+				continue
+			}
+			if err := upsertVulnEntry(ctx, tx, e); err != nil {
+				return err
+			}
+			changed++
+		}
+		var gone []string
+		for id := range modified {
+			if !seen[id] {
+				gone = append(gone, id)
+			}
+		}
+		if len(gone) > 0 {
+			// Rows of vuln_modules are deleted by the cascade.
+			if _, err := tx.Exec(ctx, `DELETE FROM vulns WHERE id = ANY($1)`, pq.Array(gone)); err != nil {
+				return err
+			}
+			removed = len(gone)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return changed, removed, nil
+}
+
+// upsertVulnEntry inserts e into the vulns table, replacing any entry with
+// the same ID, and replaces its rows of the vuln_modules table.
+func upsertVulnEntry(ctx context.Context, tx *database.DB, e *vuln.Entry) (err error) {
+	defer derrors.Wrap(&err, "upsertVulnEntry(ctx, %q)", e.ID)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO vulns (id, modified, entry)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE
+		SET
+			modified = excluded.modified,
+			entry = excluded.entry`,
+		e.ID, e.Modified, []byte(e.Raw)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM vuln_modules WHERE vuln_id = $1`, e.ID); err != nil {
+		return err
+	}
+	var values []interface{}
+	for _, p := range e.ModulePaths() {
+		values = append(values, e.ID, p)
+	}
+	return tx.BulkInsert(ctx, "vuln_modules", []string{"vuln_id", "module_path"}, values, "")
+}
+
+// GetVulnEntry returns the advisory with the given ID. It returns an error
+// wrapping derrors.NotFound if there is none.
+func (db *DB) GetVulnEntry(ctx context.Context, id string) (_ *vuln.Entry, err error) {
+	defer derrors.Wrap(&err, "GetVulnEntry(ctx, %q)", id)
+
+	var data []byte
+	err = db.db.QueryRow(ctx, `SELECT entry FROM vulns WHERE id = $1`, id).Scan(&data)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case nil:
+		return vuln.Parse(data)
+	default:
+		return nil, err
+	}
+}
+
+// GetVulnEntriesForModule returns the advisories that affect some version of
+// modulePath, sorted by ID. Whether they affect a particular version or
+// package is decided by vuln.Entry.Affects.
+func (db *DB) GetVulnEntriesForModule(ctx context.Context, modulePath string) (_ []*vuln.Entry, err error) {
+	defer derrors.Wrap(&err, "GetVulnEntriesForModule(ctx, %q)", modulePath)
+	defer middleware.ElapsedStat(ctx, "GetVulnEntriesForModule")()
+
+	query := `
+		SELECT v.entry
+		FROM vulns v
+		INNER JOIN vuln_modules m
+		ON v.id = m.vuln_id
+		WHERE m.module_path = $1
+		ORDER BY v.id`
+	var entries []*vuln.Entry
+	collect := func(rows *sql.Rows) error {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		e, err := vuln.Parse(data)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, modulePath); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetVulnEntriesForModules is like GetVulnEntriesForModule for each of
+// modulePaths. It returns the advisories keyed by module path. Modules that
+// are not affected by any advisory are missing from the map.
+func (db *DB) GetVulnEntriesForModules(ctx context.Context, modulePaths []string) (_ map[string][]*vuln.Entry, err error) {
+	defer derrors.Wrap(&err, "GetVulnEntriesForModules(ctx, %d modules)", len(modulePaths))
+	defer middleware.ElapsedStat(ctx, "GetVulnEntriesForModules")()
+
+	query := `
+		SELECT m.module_path, v.entry
+		FROM vulns v
+		INNER JOIN vuln_modules m
+		ON v.id = m.vuln_id
+		WHERE m.module_path = ANY($1)
+		ORDER BY m.module_path, v.id`
+	entries := map[string][]*vuln.Entry{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			modulePath string
+			data       []byte
+		)
+		if err := rows.Scan(&modulePath, &data); err != nil {
+			return err
+		}
+		e, err := vuln.Parse(data)
+		if err != nil {
+			return err
+		}
+		entries[modulePath] = append(entries[modulePath], e)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, pq.Array(modulePaths)); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
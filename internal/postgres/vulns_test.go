@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+func TestVulnEntries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	defer ResetTestDB(testDB, t)
+
+	parse := func(s string) *vuln.Entry {
+		t.Helper()
+		e, err := vuln.Parse([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	a := parse(`{"id": "GO-1", "modified": "2020-01-01T00:00:00Z", "affected": [{"package": {"ecosystem": "Go", "name": "example.com/a"}}]}`)
+	b := parse(`{"id": "GO-2", "modified": "2020-01-01T00:00:00Z", "affected": [{"package": {"ecosystem": "Go", "name": "example.com/b"}}]}`)
+	a2 := parse(`{"id": "GO-1", "modified": "2020-02-01T00:00:00Z", "affected": [{"package": {"ecosystem": "Go", "name": "example.com/b"}}]}`)
+
+	update := func(entries []*vuln.Entry, wantChanged, wantRemoved int) {
+		t.Helper()
+		changed, removed, err := testDB.UpdateVulnEntries(ctx, entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != wantChanged || removed != wantRemoved {
+			t.Errorf("UpdateVulnEntries: got %d changed, %d removed; want %d, %d", changed, removed, wantChanged, wantRemoved)
+		}
+	}
+	checkIDs := func(modulePath string, want ...string) {
+		t.Helper()
+		got, err := testDB.GetVulnEntriesForModule(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, e := range got {
+			ids = append(ids, e.ID)
+		}
+		if len(ids) != len(want) || (len(ids) > 0 && ids[0] != want[0]) || (len(ids) > 1 && ids[1] != want[1]) {
+			t.Errorf("GetVulnEntriesForModule(%q): got %v, want %v", modulePath, ids, want)
+		}
+	}
+
+	update([]*vuln.Entry{a, b}, 2, 0)
+	checkIDs("example.com/a", "GO-1")
+	checkIDs("example.com/b", "GO-2")
+	byModule, err := testDB.GetVulnEntriesForModules(ctx, []string{"example.com/a", "example.com/b", "example.com/c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotIDs := map[string][]string{}
+	for path, entries := range byModule {
+		for _, e := range entries {
+			gotIDs[path] = append(gotIDs[path], e.ID)
+		}
+	}
+	if diff := cmp.Diff(map[string][]string{"example.com/a": {"GO-1"}, "example.com/b": {"GO-2"}}, gotIDs); diff != "" {
+		t.Errorf("GetVulnEntriesForModules mismatch (-want +got):\n%s", diff)
+	}
+	update([]*vuln.Entry{a, b}, 0, 0)
+
+	// GO-1 now affects example.com/b, and GO-2 is gone.
+	update([]*vuln.Entry{a2}, 1, 1)
+	checkIDs("example.com/a")
+	checkIDs("example.com/b", "GO-1")
+
+	got, err := testDB.GetVulnEntry(ctx, "GO-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Modified.Equal(a2.Modified) {
+		t.Errorf("GetVulnEntry: got modified %v, want %v", got.Modified, a2.Modified)
+	}
+	if _, err := testDB.GetVulnEntry(ctx, "GO-2"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetVulnEntry(GO-2): got %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal/licenses"
+)
+
+// The following types are the subset of the CycloneDX 1.4 JSON schema used by
+// pkgsite. See https://cyclonedx.org/docs/1.4/json/.
+
+type cdxBOM struct {
+	BOMFormat    string           `json:"bomFormat"`
+	SpecVersion  string           `json:"specVersion"`
+	SerialNumber string           `json:"serialNumber"`
+	Version      int              `json:"version"`
+	Metadata     cdxMetadata      `json:"metadata"`
+	Components   []*cdxComponent  `json:"components"`
+	Dependencies []*cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp"`
+	Tools     []cdxTool     `json:"tools"`
+	Component *cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Name string `json:"name"`
+}
+
+type cdxComponent struct {
+	Type               string                 `json:"type"`
+	BOMRef             string                 `json:"bom-ref"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version"`
+	PURL               string                 `json:"purl"`
+	Scope              string                 `json:"scope,omitempty"`
+	Licenses           []*cdxLicenseChoice    `json:"licenses,omitempty"`
+	ExternalReferences []cdxExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty          `json:"properties,omitempty"`
+}
+
+// A cdxLicenseChoice holds either a license or an SPDX license expression.
+type cdxLicenseChoice struct {
+	License    *cdxLicense `json:"license,omitempty"`
+	Expression string      `json:"expression,omitempty"`
+}
+
+type cdxLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX returns a CycloneDX 1.4 JSON BOM describing m.
+//
+// The module is the BOM's main component. Its packages and dependencies are
+// the other components; dependencies have the "optional" scope if they are
+// indirect. The licenses of the module and its packages are the licenses that
+// pkgsite detected. Their file paths and coverage, as reported by
+// licensecheck, are recorded as properties named "pkgsite:license".
+func CycloneDX(m *Module) ([]byte, error) {
+	info := m.Info
+	purl := packageURL(info.ModulePath, info.Version, info.ModulePath)
+	mod := &cdxComponent{
+		Type:       "library",
+		BOMRef:     purl,
+		Name:       info.ModulePath,
+		Version:    info.Version,
+		PURL:       purl,
+		Licenses:   cdxLicenses(m.moduleLicenseTypes()),
+		Properties: licenseProperties(m.Licenses),
+	}
+	if info.SourceInfo != nil {
+		mod.ExternalReferences = []cdxExternalReference{{Type: "vcs", URL: info.SourceInfo.RepoURL()}}
+	}
+	bom := &cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.4",
+		SerialNumber: serialNumber(info.ModulePath + "@" + info.Version),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: info.CommitTime.UTC().Format(time.RFC3339),
+			Tools:     []cdxTool{{Name: toolName}},
+			Component: mod,
+		},
+	}
+	deps := &cdxDependency{Ref: purl, DependsOn: []string{}}
+	for _, p := range m.sortedPackages() {
+		// The package at the module root has the same package URL as the
+		// module, so the import path is used as the unique reference.
+		purl := packageURL(info.ModulePath, info.Version, p.Path)
+		bom.Components = append(bom.Components, &cdxComponent{
+			Type:       "library",
+			BOMRef:     p.Path,
+			Name:       p.Path,
+			Version:    info.Version,
+			PURL:       purl,
+			Licenses:   cdxLicenses(licenseTypes(p.Licenses)),
+			Properties: licenseProperties(p.Licenses),
+		})
+	}
+	for _, d := range m.sortedDependencies() {
+		purl := packageURL(d.ModulePath, d.Version, d.ModulePath)
+		c := &cdxComponent{
+			Type:     "library",
+			BOMRef:   purl,
+			Name:     d.ModulePath,
+			Version:  d.Version,
+			PURL:     purl,
+			Scope:    "required",
+			Licenses: cdxLicenses(d.LicenseTypes),
+		}
+		if d.Indirect {
+			c.Scope = "optional"
+		}
+		bom.Components = append(bom.Components, c)
+		deps.DependsOn = append(deps.DependsOn, purl)
+	}
+	bom.Dependencies = []*cdxDependency{deps}
+	return marshal(bom)
+}
+
+// cdxLicenses returns the CycloneDX licenses for licenseTypes. Since
+// CycloneDX does not allow licenses and expressions to be mixed, a single
+// expression is used if some type is an SPDX license expression and all types
+// can be expressed in SPDX.
+func cdxLicenses(licenseTypes []string) []*cdxLicenseChoice {
+	var choices []*cdxLicenseChoice
+	for _, t := range licenseTypes {
+		if strings.Contains(t, " ") {
+			if expr := licenses.SPDXExpression(licenseTypes); expr != "" {
+				return []*cdxLicenseChoice{{Expression: expr}}
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, t := range licenseTypes {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if id := licenses.SPDXExpression([]string{t}); id != "" && !strings.Contains(id, " ") {
+			choices = append(choices, &cdxLicenseChoice{License: &cdxLicense{ID: id}})
+		} else {
+			choices = append(choices, &cdxLicenseChoice{License: &cdxLicense{Name: t}})
+		}
+	}
+	return choices
+}
+
+// licenseProperties returns a property describing the file path, types and
+// coverage of each license in lics.
+func licenseProperties(lics []*licenses.Metadata) []cdxProperty {
+	var props []cdxProperty
+	for _, l := range sortedLicenses(lics) {
+		props = append(props, cdxProperty{
+			Name:  "pkgsite:license",
+			Value: licenseComment([]*licenses.Metadata{l}),
+		})
+	}
+	return props
+}
+
+// serialNumber returns a URN holding a UUID derived from name, in the manner
+// of a version 5 UUID, so that the same name always has the same serial
+// number.
+func serialNumber(name string) string {
+	h := sha1.Sum([]byte("pkgsite:sbom:" + name))
+	h[6] = (h[6] & 0x0f) | 0x50 // version 5
+	h[8] = (h[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
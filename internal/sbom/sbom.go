@@ -0,0 +1,127 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sbom generates software bills of materials (SBOMs) for module
+// versions, in the SPDX and CycloneDX JSON formats.
+//
+// The output depends only on its input: the same module data always produces
+// the same bytes. In particular, timestamps are taken from the module's commit
+// time, and identifiers are derived from module paths and versions.
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/licenses"
+)
+
+// toolName identifies pkgsite as the creator of an SBOM.
+const toolName = "pkgsite"
+
+// Module holds the data about a module version from which an SBOM is
+// generated.
+type Module struct {
+	Info *internal.ModuleInfo
+	// Packages are the packages of the module.
+	Packages []*internal.PackageMeta
+	// Licenses are all the licenses detected in the module.
+	Licenses []*licenses.Metadata
+	// Dependencies are the requirements of the module's go.mod file.
+	Dependencies []*Dependency
+}
+
+// A Dependency is a module version required by a module's go.mod file.
+type Dependency struct {
+	ModulePath string
+	Version    string
+	Indirect   bool
+	// LicenseTypes are the types of the licenses at the root of the
+	// dependency. It is nil if the dependency has not been processed.
+	LicenseTypes []string
+}
+
+// packageURL returns the package URL (see https://github.com/package-url/purl-spec)
+// of the package with the given import path in the given module version.
+func packageURL(modulePath, version, pkgPath string) string {
+	purl := "pkg:golang/" + modulePath + "@" + version
+	if pkgPath != modulePath {
+		purl += "#" + internal.Suffix(pkgPath, modulePath)
+	}
+	return purl
+}
+
+// sortedPackages returns the packages of m sorted by path.
+func (m *Module) sortedPackages() []*internal.PackageMeta {
+	pkgs := append([]*internal.PackageMeta(nil), m.Packages...)
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Path < pkgs[j].Path })
+	return pkgs
+}
+
+// sortedDependencies returns the dependencies of m sorted by module path and
+// version.
+func (m *Module) sortedDependencies() []*Dependency {
+	deps := append([]*Dependency(nil), m.Dependencies...)
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].ModulePath != deps[j].ModulePath {
+			return deps[i].ModulePath < deps[j].ModulePath
+		}
+		return deps[i].Version < deps[j].Version
+	})
+	return deps
+}
+
+// moduleLicenseTypes returns the types of the licenses at the module root.
+func (m *Module) moduleLicenseTypes() []string {
+	var types []string
+	for _, l := range sortedLicenses(m.Licenses) {
+		if !strings.Contains(l.FilePath, "/") {
+			types = append(types, l.Types...)
+		}
+	}
+	return types
+}
+
+// sortedLicenses returns lics sorted by file path.
+func sortedLicenses(lics []*licenses.Metadata) []*licenses.Metadata {
+	lics = append([]*licenses.Metadata(nil), lics...)
+	sort.Slice(lics, func(i, j int) bool { return lics[i].FilePath < lics[j].FilePath })
+	return lics
+}
+
+// licenseTypes returns the types of the licenses in lics, in order of file
+// path.
+func licenseTypes(lics []*licenses.Metadata) []string {
+	var types []string
+	for _, l := range sortedLicenses(lics) {
+		types = append(types, l.Types...)
+	}
+	return types
+}
+
+// licenseComment describes the licenses in lics and their coverage.
+func licenseComment(lics []*licenses.Metadata) string {
+	var parts []string
+	for _, l := range sortedLicenses(lics) {
+		parts = append(parts, fmt.Sprintf("%s: %s (%.1f%% coverage)",
+			l.FilePath, strings.Join(l.Types, ", "), l.Coverage.Percent))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// marshal encodes v as indented JSON, without escaping HTML characters.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
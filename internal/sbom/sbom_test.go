@@ -0,0 +1,118 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/licensecheck"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/source"
+)
+
+var update = flag.Bool("update", false, "update goldens instead of checking against them")
+
+func testModule() *Module {
+	mit := &licenses.Metadata{
+		Types:    []string{"MIT"},
+		FilePath: "LICENSE",
+		Coverage: licensecheck.Coverage{Percent: 100},
+	}
+	dual := &licenses.Metadata{
+		Types:    []string{"Apache-2.0 OR BSD-3-Clause"},
+		FilePath: "sub/a.go",
+	}
+	return &Module{
+		Info: &internal.ModuleInfo{
+			ModulePath: "example.com/m",
+			Version:    "v1.2.3",
+			CommitTime: time.Date(2020, 10, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+			SourceInfo: source.NewGitHubInfo("https://github.com/example/m", "", "v1.2.3"),
+		},
+		Packages: []*internal.PackageMeta{
+			{Path: "example.com/m/sub", Licenses: []*licenses.Metadata{dual, mit}},
+			{Path: "example.com/m", Licenses: []*licenses.Metadata{mit}},
+		},
+		Licenses: []*licenses.Metadata{mit, dual},
+		Dependencies: []*Dependency{
+			{ModulePath: "golang.org/x/text", Version: "v0.3.3", Indirect: true},
+			{ModulePath: "example.com/dep", Version: "v0.1.0", LicenseTypes: []string{"GPL2"}},
+			{ModulePath: "example.com/nolicense", Version: "v1.0.0", LicenseTypes: []string{}},
+		},
+	}
+}
+
+func TestSBOM(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		generate func(*Module) ([]byte, error)
+	}{
+		{"spdx", func(m *Module) ([]byte, error) {
+			return SPDX(m, "https://pkg.go.dev/sbom/example.com/m@v1.2.3.spdx.json")
+		}},
+		{"cdx", CycloneDX},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.generate(testModule())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !json.Valid(got) {
+				t.Fatalf("invalid JSON:\n%s", got)
+			}
+			// The output does not depend on the order of the input.
+			m := testModule()
+			m.Packages[0], m.Packages[1] = m.Packages[1], m.Packages[0]
+			m.Dependencies[0], m.Dependencies[2] = m.Dependencies[2], m.Dependencies[0]
+			m.Licenses[0], m.Licenses[1] = m.Licenses[1], m.Licenses[0]
+			lics := m.Packages[1].Licenses
+			lics[0], lics[1] = lics[1], lics[0]
+			again, err := test.generate(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, again) {
+				t.Errorf("output depends on input order:\n%s", cmp.Diff(string(got), string(again)))
+			}
+
+			golden := filepath.Join("testdata", test.name+".json")
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSerialNumber(t *testing.T) {
+	got := serialNumber("example.com/m@v1.2.3")
+	if got != serialNumber("example.com/m@v1.2.3") {
+		t.Error("serial number is not deterministic")
+	}
+	if got == serialNumber("example.com/m@v1.2.4") {
+		t.Error("serial numbers of different versions are equal")
+	}
+	// urn:uuid:xxxxxxxx-xxxx-5xxx-[89ab]xxx-xxxxxxxxxxxx
+	if len(got) != len("urn:uuid:")+36 || got[len("urn:uuid:")+14] != '5' {
+		t.Errorf("got %q, want a version 5 UUID URN", got)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/pkgsite/internal/licenses"
+)
+
+const (
+	// noAssertion is the SPDX value for information that is not provided.
+	noAssertion = "NOASSERTION"
+	// none is the SPDX value for information that is known to be absent.
+	none = "NONE"
+)
+
+// The following types are the subset of the SPDX 2.3 JSON schema used by
+// pkgsite. See https://spdx.github.io/spdx-spec/v2.3/.
+
+type spdxDocument struct {
+	SPDXVersion       string              `json:"spdxVersion"`
+	DataLicense       string              `json:"dataLicense"`
+	SPDXID            string              `json:"SPDXID"`
+	Name              string              `json:"name"`
+	DocumentNamespace string              `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo    `json:"creationInfo"`
+	Packages          []*spdxPackage      `json:"packages"`
+	Relationships     []*spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo"`
+	DownloadLocation string            `json:"downloadLocation"`
+	Homepage         string            `json:"homepage,omitempty"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	LicenseComments  string            `json:"licenseComments,omitempty"`
+	CopyrightText    string            `json:"copyrightText"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX returns an SPDX 2.3 JSON document describing m. The namespace must be
+// a URI that is unique to the module version, such as the URL from which the
+// document is served.
+//
+// The module, each of its packages and each of its dependencies is an SPDX
+// package. The declared licenses of the module and its packages are the
+// licenses that pkgsite detected. Their coverage, as reported by licensecheck,
+// is described in the license comments.
+func SPDX(m *Module, namespace string) ([]byte, error) {
+	const moduleID = "SPDXRef-Module"
+	info := m.Info
+	doc := &spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              info.ModulePath + "@" + info.Version,
+		DocumentNamespace: namespace,
+		CreationInfo: spdxCreationInfo{
+			Created:  info.CommitTime.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+	}
+	relate := func(a, typ, b string) {
+		doc.Relationships = append(doc.Relationships, &spdxRelationship{a, typ, b})
+	}
+
+	mod := newSPDXPackage(moduleID, info.ModulePath, info.Version, packageURL(info.ModulePath, info.Version, info.ModulePath), m.moduleLicenseTypes(), true)
+	mod.LicenseComments = licenseComment(m.Licenses)
+	if info.SourceInfo != nil {
+		mod.Homepage = info.SourceInfo.RepoURL()
+	}
+	doc.Packages = append(doc.Packages, mod)
+	relate(doc.SPDXID, "DESCRIBES", moduleID)
+
+	for i, p := range m.sortedPackages() {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		sp := newSPDXPackage(id, p.Path, info.Version, packageURL(info.ModulePath, info.Version, p.Path), licenseTypes(p.Licenses), true)
+		sp.LicenseComments = licenseComment(p.Licenses)
+		doc.Packages = append(doc.Packages, sp)
+		relate(moduleID, "CONTAINS", id)
+	}
+
+	for i, d := range m.sortedDependencies() {
+		id := fmt.Sprintf("SPDXRef-Dependency-%d", i+1)
+		sp := newSPDXPackage(id, d.ModulePath, d.Version, packageURL(d.ModulePath, d.Version, d.ModulePath), d.LicenseTypes, d.LicenseTypes != nil)
+		if d.Indirect {
+			sp.Comment = "Indirect dependency."
+		}
+		doc.Packages = append(doc.Packages, sp)
+		relate(moduleID, "DEPENDS_ON", id)
+	}
+	return marshal(doc)
+}
+
+// newSPDXPackage returns an SPDX package for a Go module or package, whose
+// declared license is given by licenseTypes. If known is false, the licenses
+// are unknown.
+func newSPDXPackage(id, name, version, purl string, licenseTypes []string, known bool) *spdxPackage {
+	declared := licenses.SPDXExpression(licenseTypes)
+	switch {
+	case !known:
+		declared = noAssertion
+	case len(licenseTypes) == 0:
+		declared = none
+	case declared == "":
+		declared = noAssertion
+	}
+	return &spdxPackage{
+		SPDXID:           id,
+		Name:             name,
+		VersionInfo:      version,
+		DownloadLocation: noAssertion,
+		LicenseConcluded: noAssertion,
+		LicenseDeclared:  declared,
+		CopyrightText:    noAssertion,
+		ExternalRefs: []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl,
+		}},
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// SetHostTransport arranges for the client to make requests to host with rt
+// instead of its default transport. If host begins with "*.", rt is used for
+// all subdomains of the rest of host. A host without a wildcard takes
+// precedence over a wildcard that matches it.
+//
+// SetHostTransport must be called before the client is used.
+func (c *Client) SetHostTransport(host string, rt http.RoundTripper) {
+	ht, ok := c.httpClient.Transport.(*hostTransport)
+	if !ok {
+		ht = &hostTransport{
+			base:  c.httpClient.Transport,
+			hosts: map[string]http.RoundTripper{},
+		}
+		c.httpClient.Transport = ht
+	}
+	ht.hosts[strings.ToLower(host)] = rt
+}
+
+// SetHostCredentials arranges for the client to authenticate HTTPS requests to
+// host, which may begin with "*." as for SetHostTransport. If user is empty,
+// password is sent as a bearer token; otherwise user and password are sent
+// with basic authentication. Credentials are never sent over plain HTTP.
+//
+// SetHostCredentials must be called before the client is used.
+func (c *Client) SetHostCredentials(host, user, password string) {
+	base := c.httpClient.Transport
+	if ht, ok := base.(*hostTransport); ok {
+		base = ht.base
+	}
+	c.SetHostTransport(host, &authTransport{
+		base:     base,
+		user:     user,
+		password: password,
+	})
+}
+
+// hostTransport routes requests to a transport chosen by the request's host.
+type hostTransport struct {
+	base  http.RoundTripper
+	hosts map[string]http.RoundTripper
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt := t.lookup(req.URL.Hostname()); rt != nil {
+		return rt.RoundTrip(req)
+	}
+	return transportOrDefault(t.base).RoundTrip(req)
+}
+
+// lookup returns the transport for host, or nil if there is none.
+func (t *hostTransport) lookup(host string) http.RoundTripper {
+	host = strings.ToLower(host)
+	if rt := t.hosts[host]; rt != nil {
+		return rt
+	}
+	for h := host; ; {
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			return nil
+		}
+		h = h[i+1:]
+		if rt := t.hosts["*."+h]; rt != nil {
+			return rt
+		}
+	}
+}
+
+// HostCredentials returns the credentials set for host with
+// SetHostCredentials, so that they can be used for other requests to the host,
+// like cloning a repository. If user is empty, password is a bearer token.
+func (c *Client) HostCredentials(host string) (user, password string, ok bool) {
+	if c == nil {
+		return "", "", false
+	}
+	ht, ok := c.httpClient.Transport.(*hostTransport)
+	if !ok {
+		return "", "", false
+	}
+	at, ok := ht.lookup(host).(*authTransport)
+	if !ok {
+		return "", "", false
+	}
+	return at.user, at.password, true
+}
+
+// authTransport adds credentials to HTTPS requests.
+type authTransport struct {
+	base           http.RoundTripper
+	user, password string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return transportOrDefault(t.base).RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it is given.
+	req = req.Clone(req.Context())
+	if t.user == "" {
+		req.Header.Set("Authorization", "Bearer "+t.password)
+	} else {
+		req.SetBasicAuth(t.user, t.password)
+	}
+	return transportOrDefault(t.base).RoundTrip(req)
+}
+
+func transportOrDefault(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// A DiscoveryCache stores the results of looking up the go-import and go-source
+// meta tags of module paths, so that they need not be fetched for every
+// version of a module.
+type DiscoveryCache interface {
+	// GetSourceDiscovery returns the data stored for modulePath, if it has not
+	// expired. If there is none, it returns found == false.
+	GetSourceDiscovery(ctx context.Context, modulePath string) (data []byte, found bool, err error)
+	// PutSourceDiscovery stores data for modulePath until the expiration time,
+	// replacing any data stored earlier.
+	PutSourceDiscovery(ctx context.Context, modulePath string, data []byte, expires time.Time) error
+}
+
+// SetDiscoveryCache arranges for the client to cache the results of meta tag
+// discovery in dc for the duration ttl. Both successful lookups and lookups
+// that found no meta tags are cached; lookups that fail for other reasons,
+// such as network errors, are not.
+//
+// SetDiscoveryCache must be called before the client is used.
+func (c *Client) SetDiscoveryCache(dc DiscoveryCache, ttl time.Duration) {
+	c.discoveryCache = dc
+	c.discoveryTTL = ttl
+}
+
+// cachedMeta is the form of a sourceMeta stored in a DiscoveryCache.
+type cachedMeta struct {
+	RepoRootPrefix string `json:",omitempty"`
+	RepoURL        string `json:",omitempty"`
+	DirTemplate    string `json:",omitempty"`
+	FileTemplate   string `json:",omitempty"`
+	// NotFound, if non-empty, is the reason that no meta tags were found.
+	NotFound string `json:",omitempty"`
+}
+
+// discoverMeta is like fetchMeta, but consults the client's discovery cache
+// first, and stores the result in the cache. Errors using the cache are
+// logged and otherwise ignored.
+func discoverMeta(ctx context.Context, client *Client, modulePath string) (*sourceMeta, error) {
+	if client == nil || client.discoveryCache == nil {
+		return fetchMeta(ctx, client, modulePath)
+	}
+	data, found, err := client.discoveryCache.GetSourceDiscovery(ctx, modulePath)
+	if err != nil {
+		log.Errorf(ctx, "source.discoverMeta(%q): %v", modulePath, err)
+	} else if found {
+		var cm cachedMeta
+		if err := json.Unmarshal(data, &cm); err != nil {
+			log.Errorf(ctx, "source.discoverMeta(%q): unmarshaling cached result: %v", modulePath, err)
+		} else if cm.NotFound != "" {
+			return nil, fmt.Errorf("fetchMeta(ctx, client, %q) (cached): %s: %w", modulePath, cm.NotFound, derrors.NotFound)
+		} else {
+			return &sourceMeta{
+				repoRootPrefix: cm.RepoRootPrefix,
+				repoURL:        cm.RepoURL,
+				dirTemplate:    cm.DirTemplate,
+				fileTemplate:   cm.FileTemplate,
+			}, nil
+		}
+	}
+
+	sm, err := fetchMeta(ctx, client, modulePath)
+	var cm cachedMeta
+	switch {
+	case err == nil:
+		cm = cachedMeta{
+			RepoRootPrefix: sm.repoRootPrefix,
+			RepoURL:        sm.repoURL,
+			DirTemplate:    sm.dirTemplate,
+			FileTemplate:   sm.fileTemplate,
+		}
+	case errors.Is(err, derrors.NotFound):
+		cm.NotFound = err.Error()
+	default:
+		return nil, err
+	}
+	data, merr := json.Marshal(cm)
+	if merr != nil {
+		return nil, merr
+	}
+	expires := time.Now().Add(client.discoveryTTL)
+	if perr := client.discoveryCache.PutSourceDiscovery(ctx, modulePath, data, expires); perr != nil {
+		log.Errorf(ctx, "source.discoverMeta(%q): %v", modulePath, perr)
+	}
+	return sm, err
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// authRecorder records the Authorization header of each request by URL.
+type authRecorder map[string]string
+
+func (a authRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	a[req.URL.String()] = req.Header.Get("Authorization")
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func TestHostCredentials(t *testing.T) {
+	rec := authRecorder{}
+	other := authRecorder{}
+	client := &Client{httpClient: &http.Client{Transport: rec}}
+	client.SetHostCredentials("private.example.com", "", "tok")
+	client.SetHostCredentials("*.corp.example.com", "user", "pw")
+	client.SetHostTransport("other.example.com", other)
+
+	for _, u := range []string{
+		"https://private.example.com/m",
+		"http://private.example.com/m",
+		"https://sub.private.example.com/m",
+		"https://git.corp.example.com:8443/m",
+		"https://corp.example.com/m",
+		"https://other.example.com/m",
+	} {
+		resp, err := client.doURL(context.Background(), "GET", u, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	want := authRecorder{
+		"https://private.example.com/m":       "Bearer tok",
+		"http://private.example.com/m":        "",
+		"https://sub.private.example.com/m":   "",
+		"https://git.corp.example.com:8443/m": "Basic dXNlcjpwdw==",
+		"https://corp.example.com/m":          "",
+	}
+	for u, w := range want {
+		got, ok := rec[u]
+		if !ok {
+			t.Errorf("%s: no request made", u)
+		} else if got != w {
+			t.Errorf("%s: got Authorization %q, want %q", u, got, w)
+		}
+	}
+	if _, ok := other["https://other.example.com/m"]; !ok || len(rec) != len(want) {
+		t.Errorf("request to other.example.com not routed to its transport")
+	}
+
+	for _, test := range []struct {
+		host, wantUser, wantPassword string
+		wantOK                       bool
+	}{
+		{"private.example.com", "", "tok", true},
+		{"git.corp.example.com", "user", "pw", true},
+		{"other.example.com", "", "", false},
+		{"example.com", "", "", false},
+	} {
+		user, password, ok := client.HostCredentials(test.host)
+		if user != test.wantUser || password != test.wantPassword || ok != test.wantOK {
+			t.Errorf("HostCredentials(%q) = %q, %q, %t; want %q, %q, %t",
+				test.host, user, password, ok, test.wantUser, test.wantPassword, test.wantOK)
+		}
+	}
+}
+
+type fakeDiscoveryCache map[string][]byte
+
+func (c fakeDiscoveryCache) GetSourceDiscovery(_ context.Context, modulePath string) ([]byte, bool, error) {
+	data, ok := c[modulePath]
+	return data, ok, nil
+}
+
+func (c fakeDiscoveryCache) PutSourceDiscovery(_ context.Context, modulePath string, data []byte, _ time.Time) error {
+	c[modulePath] = data
+	return nil
+}
+
+// countingTransport counts the requests it forwards.
+type countingTransport struct {
+	http.RoundTripper
+	n int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.n++
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestDiscoveryCache(t *testing.T) {
+	ctx := context.Background()
+	ct := &countingTransport{RoundTripper: testTransport(testWeb)}
+	client := &Client{httpClient: &http.Client{Transport: ct}}
+	cache := fakeDiscoveryCache{}
+	client.SetDiscoveryCache(cache, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		ct.n = 0
+		info, err := moduleInfoDynamic(ctx, client, "alice.org/pkg/sub", "v1.2.3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := info.ModuleURL(), "https://github.com/alice/pkg/tree/sub/v1.2.3/sub"; got != want {
+			t.Errorf("#%d: got module URL %q, want %q", i, got, want)
+		}
+		_, err = moduleInfoDynamic(ctx, client, "alice.org/pkg/missing", "v1.2.3")
+		if !errors.Is(err, derrors.NotFound) {
+			t.Errorf("#%d: got error %v, want NotFound", i, err)
+		}
+		if i == 1 && ct.n != 0 {
+			t.Errorf("made %d requests with a populated cache, want 0", ct.n)
+		}
+	}
+	if len(cache) != 2 {
+		t.Errorf("got %d cache entries, want 2", len(cache))
+	}
+}
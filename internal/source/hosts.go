@@ -0,0 +1,176 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// HostConfig describes a code host that is not built into this package, such
+// as a self-hosted GitLab or Bitbucket Server instance. HostConfigs are read
+// from configuration and registered with AddHosts.
+type HostConfig struct {
+	// Pattern is a regexp that matches a prefix of the module paths, or repo
+	// URLs without a scheme, served by the host. It must have a group named
+	// "repo" that matches the repository, for example
+	//   ^(?P<repo>git\.example\.com/[a-z0-9A-Z_.\-]+/[a-z0-9A-Z_.\-]+)(\.git|$)
+	// Other named groups can be used as variables in the URL templates.
+	Pattern string
+
+	// Kind, if non-empty, names a built-in set of URL templates: one of
+	// "github", "gitlab", "bitbucket", "gitea", "gogs" or "googlesource".
+	// Templates below override the corresponding templates of the kind.
+	Kind string
+
+	// URL templates, as described at urlTemplates, with the additional
+	// variables defined by Pattern. Directory, File and Line are required if
+	// Kind is empty.
+	Repo, Directory, File, Line, Raw string
+
+	// TagCommit and HashCommit, if non-empty, are templates with a {commit}
+	// variable that rewrite the commit of a version before it is substituted
+	// into the URL templates. TagCommit is used for tags and HashCommit for
+	// the commit hashes of pseudo-versions. For example, Bitbucket Server
+	// uses
+	//   TagCommit: "refs/tags/{commit}"
+	TagCommit, HashCommit string
+}
+
+// templateVars are the variables that may appear in URL templates.
+var templateVars = map[string]bool{
+	"repo":       true,
+	"importPath": true,
+	"commit":     true,
+	"dir":        true,
+	"file":       true,
+	"base":       true,
+	"line":       true,
+}
+
+var templateVarRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// AddHosts validates hosts and adds them to the patterns that ModuleInfo
+// matches module paths and repo URLs against. They take precedence over the
+// built-in patterns, and over hosts added by earlier calls.
+//
+// AddHosts is meant to be called at startup: it must not be called
+// concurrently with ModuleInfo. If any host is invalid, it returns an error and
+// adds none of them.
+func AddHosts(hosts []*HostConfig) (err error) {
+	defer derrors.Wrap(&err, "source.AddHosts")
+
+	var pats []hostPattern
+	for i, h := range hosts {
+		p, err := h.hostPattern()
+		if err != nil {
+			return fmt.Errorf("host %d (%q): %v", i, h.Pattern, err)
+		}
+		pats = append(pats, p)
+	}
+	patterns = append(pats, patterns...)
+	return nil
+}
+
+// hostPattern validates h and converts it to a hostPattern.
+func (h *HostConfig) hostPattern() (hostPattern, error) {
+	re, err := compilePattern(h.Pattern)
+	if err != nil {
+		return hostPattern{}, err
+	}
+	for _, n := range re.SubexpNames() {
+		if n != "repo" && templateVars[n] {
+			return hostPattern{}, fmt.Errorf("group name %q conflicts with a template variable", n)
+		}
+	}
+	var templates urlTemplates
+	var transformCommit func(string, bool) string
+	switch h.Kind {
+	case "":
+		if h.Directory == "" || h.File == "" || h.Line == "" {
+			return hostPattern{}, errors.New("the Directory, File and Line templates are required if Kind is empty")
+		}
+	case "github", "gitlab":
+		templates = githubURLTemplates
+	case "bitbucket":
+		templates = bitbucketURLTemplates
+	case "gitea":
+		templates = giteaURLTemplates
+		transformCommit = giteaTransformCommit
+	case "gogs":
+		templates = giteaURLTemplates
+	case "googlesource":
+		templates = googlesourceURLTemplates
+	default:
+		return hostPattern{}, fmt.Errorf("unknown kind %q", h.Kind)
+	}
+	for _, t := range []struct {
+		name       string
+		tmpl       string
+		field      *string
+		needCommit bool
+	}{
+		{"Repo", h.Repo, &templates.Repo, false},
+		{"Directory", h.Directory, &templates.Directory, true},
+		{"File", h.File, &templates.File, true},
+		{"Line", h.Line, &templates.Line, true},
+		{"Raw", h.Raw, &templates.Raw, true},
+	} {
+		if t.tmpl == "" {
+			continue
+		}
+		if err := checkTemplate(t.tmpl, re, t.needCommit); err != nil {
+			return hostPattern{}, fmt.Errorf("%s template: %v", t.name, err)
+		}
+		*t.field = t.tmpl
+	}
+	if h.TagCommit != "" || h.HashCommit != "" {
+		for _, t := range []string{h.TagCommit, h.HashCommit} {
+			if t != "" && !strings.Contains(t, "{commit}") {
+				return hostPattern{}, fmt.Errorf("commit template %q is missing {commit}", t)
+			}
+		}
+		tag, hash := h.TagCommit, h.HashCommit
+		transformCommit = func(commit string, isHash bool) string {
+			t := tag
+			if isHash {
+				t = hash
+			}
+			if t == "" {
+				return commit
+			}
+			return expand(t, map[string]string{"commit": commit})
+		}
+	}
+	return hostPattern{
+		pattern:         h.Pattern,
+		templates:       templates,
+		re:              re,
+		transformCommit: transformCommit,
+	}, nil
+}
+
+// checkTemplate reports an error if tmpl contains a variable that is neither
+// a standard variable nor a named group of re, or if needCommit is true and
+// tmpl does not contain {commit}.
+func checkTemplate(tmpl string, re *regexp.Regexp, needCommit bool) error {
+	groups := map[string]bool{}
+	for _, n := range re.SubexpNames() {
+		groups[n] = true
+	}
+	for _, m := range templateVarRegexp.FindAllStringSubmatch(tmpl, -1) {
+		if !templateVars[m[1]] && !groups[m[1]] {
+			return fmt.Errorf("%q has unknown variable {%s}", tmpl, m[1])
+		}
+	}
+	if needCommit && !strings.Contains(tmpl, "{commit}") {
+		return fmt.Errorf("%q is missing {commit}", tmpl)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAddHosts(t *testing.T) {
+	defer func(p []hostPattern) { patterns = p }(patterns)
+
+	err := AddHosts([]*HostConfig{
+		{
+			Pattern:    `^(?P<repo>bitbucket\.example\.com/scm/(?P<project>[a-z0-9A-Z_.\-]+)/(?P<name>[a-z0-9A-Z_.\-]+))(\.git|$)`,
+			Repo:       "https://bitbucket.example.com/projects/{project}/repos/{name}",
+			Directory:  "https://bitbucket.example.com/projects/{project}/repos/{name}/browse/{dir}?at={commit}",
+			File:       "https://bitbucket.example.com/projects/{project}/repos/{name}/browse/{file}?at={commit}",
+			Line:       "https://bitbucket.example.com/projects/{project}/repos/{name}/browse/{file}?at={commit}#{line}",
+			Raw:        "https://bitbucket.example.com/projects/{project}/repos/{name}/raw/{file}?at={commit}",
+			TagCommit:  "refs/tags/{commit}",
+			HashCommit: "{commit}",
+		},
+		{
+			Pattern: `^(?P<repo>git\.example\.com/[a-z0-9A-Z_.\-]+/[a-z0-9A-Z_.\-]+)`,
+			Kind:    "gitlab",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		modulePath, version                     string
+		wantRepo, wantModule, wantLine, wantRaw string
+	}{
+		{
+			"bitbucket.example.com/scm/proj/repo.git/sub", "v1.2.3",
+			"https://bitbucket.example.com/projects/proj/repos/repo",
+			"https://bitbucket.example.com/projects/proj/repos/repo/browse/sub?at=refs/tags/sub/v1.2.3",
+			"https://bitbucket.example.com/projects/proj/repos/repo/browse/sub/a.go?at=refs/tags/sub/v1.2.3#5",
+			"https://bitbucket.example.com/projects/proj/repos/repo/raw/sub/a.go?at=refs/tags/sub/v1.2.3",
+		},
+		{
+			"bitbucket.example.com/scm/proj/repo", "v0.0.0-20200101000000-0123456789ab",
+			"https://bitbucket.example.com/projects/proj/repos/repo",
+			"https://bitbucket.example.com/projects/proj/repos/repo/browse/?at=0123456789ab",
+			"https://bitbucket.example.com/projects/proj/repos/repo/browse/a.go?at=0123456789ab#5",
+			"https://bitbucket.example.com/projects/proj/repos/repo/raw/a.go?at=0123456789ab",
+		},
+		{
+			"git.example.com/a/b", "v1.0.0",
+			"https://git.example.com/a/b",
+			"https://git.example.com/a/b/tree/v1.0.0",
+			"https://git.example.com/a/b/blob/v1.0.0/a.go#L5",
+			"https://git.example.com/a/b/raw/v1.0.0/a.go",
+		},
+	} {
+		t.Run(test.modulePath, func(t *testing.T) {
+			info, err := ModuleInfo(context.Background(), nil, test.modulePath, test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range []struct {
+				name, got, want string
+			}{
+				{"repo", info.RepoURL(), test.wantRepo},
+				{"module", info.ModuleURL(), test.wantModule},
+				{"line", info.LineURL("a.go", 5), test.wantLine},
+				{"raw", info.RawURL("a.go"), test.wantRaw},
+			} {
+				if c.got != c.want {
+					t.Errorf("%s URL: got %q, want %q", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAddHostsInvalid(t *testing.T) {
+	defer func(p []hostPattern) { patterns = p }(patterns)
+
+	n := len(patterns)
+	for _, h := range []*HostConfig{
+		{Pattern: `^(?P<repo>x\.com/[^/]+`, Kind: "github"},
+		{Pattern: `^x\.com/[^/]+`, Kind: "github"},
+		{Pattern: `^(?P<repo>x\.com/(?P<commit>[^/]+))`, Kind: "github"},
+		{Pattern: `^(?P<repo>x\.com/[^/]+)`, Kind: "sourceforge"},
+		{Pattern: `^(?P<repo>x\.com/[^/]+)`, Directory: "{repo}/tree/{commit}/{dir}"},
+		{Pattern: `^(?P<repo>x\.com/[^/]+)`, Kind: "github", File: "{repo}/blob/{file}"},
+		{Pattern: `^(?P<repo>x\.com/[^/]+)`, Kind: "github", Line: "{repo}/blob/{commit}/{file}#L{lineno}"},
+		{Pattern: `^(?P<repo>x\.com/[^/]+)`, Kind: "github", TagCommit: "refs/tags/"},
+	} {
+		if err := AddHosts([]*HostConfig{{Pattern: `^(?P<repo>ok\.com/[^/]+)`, Kind: "github"}, h}); err == nil {
+			t.Errorf("%+v: got nil, want error", h)
+		}
+		if len(patterns) != n {
+			t.Fatalf("%+v: patterns added despite error", h)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// LocalInfo returns source information for a module in the local directory
+// dir.
+//
+// If dir is in a git repository whose remote is on a known code host, the
+// returned Info links to the commit checked out at HEAD on that host. The links
+// may not match the files in dir if they have uncommitted changes. Otherwise,
+// LocalInfo returns an Info with file:// links to the files in dir.
+func LocalInfo(dir string) (_ *Info, err error) {
+	defer derrors.Wrap(&err, "source.LocalInfo(%q)", dir)
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := gitInfo(dir)
+	if err != nil && !errors.Is(err, derrors.NotFound) {
+		return nil, err
+	}
+	if info != nil {
+		return info, nil
+	}
+	return newFileInfo(dir), nil
+}
+
+var fileURLTemplates = urlTemplates{
+	Directory: "{repo}/{dir}",
+	File:      "{repo}/{file}",
+	// Browsers ignore the fragment of a file URL, but editors and other
+	// tools that open the link may use it.
+	Line: "{repo}/{file}#L{line}",
+	Raw:  "{repo}/{file}",
+}
+
+// newFileInfo returns an Info with file:// links to the files in dir, which
+// must be absolute.
+func newFileInfo(dir string) *Info {
+	p := filepath.ToSlash(dir)
+	if !strings.HasPrefix(p, "/") {
+		// A Windows path like C:/dir.
+		p = "/" + p
+	}
+	return &Info{
+		repoURL:   (&url.URL{Scheme: "file", Path: p}).String(),
+		templates: fileURLTemplates,
+	}
+}
+
+// gitInfo returns an Info for the module in dir, which must be absolute, from
+// the remote and HEAD of the git repository containing it. It returns nil if
+// the repository's remote is not on a known code host, and an error wrapping
+// derrors.NotFound if dir is not in a git repository or it has no remote.
+func gitInfo(dir string) (_ *Info, err error) {
+	root, gitDir, err := findGitDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	commonDir := gitDir
+	if data, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		// A linked worktree shares the config and refs of the main one.
+		commonDir = resolveGitPath(gitDir, strings.TrimSpace(string(data)))
+	}
+	config, err := ioutil.ReadFile(filepath.Join(commonDir, "config"))
+	if err != nil {
+		return nil, err
+	}
+	remote := gitRemoteURL(config)
+	if remote == "" {
+		return nil, fmt.Errorf("%s: no remote: %w", commonDir, derrors.NotFound)
+	}
+	commit, err := gitHead(gitDir, commonDir)
+	if err != nil {
+		return nil, err
+	}
+	moduleDir, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	moduleDir = filepath.ToSlash(moduleDir)
+	if moduleDir == "." {
+		moduleDir = ""
+	}
+
+	repoPath := remotePath(remote)
+	repo, _, templates, transformCommit, _ := matchStatic(strings.TrimSuffix(repoPath, ".git"))
+	if templates != (urlTemplates{}) {
+		if transformCommit != nil {
+			commit = transformCommit(commit, true)
+		}
+		return &Info{
+			repoURL:   "https://" + repo,
+			moduleDir: moduleDir,
+			commit:    commit,
+			templates: templates,
+		}, nil
+	}
+	if newInfo, _ := matchProvider(repoPath); newInfo != nil {
+		return newInfo(moduleDir, commit, true), nil
+	}
+	return nil, nil
+}
+
+// findGitDir returns the root of the git working tree containing dir, and
+// its git directory.
+func findGitDir(dir string) (root, gitDir string, err error) {
+	for d := dir; ; {
+		p := filepath.Join(d, ".git")
+		fi, err := os.Stat(p)
+		switch {
+		case err == nil && fi.IsDir():
+			return d, p, nil
+		case err == nil:
+			// In a linked worktree or submodule, .git is a file containing
+			// "gitdir: <path>".
+			data, err := ioutil.ReadFile(p)
+			if err != nil {
+				return "", "", err
+			}
+			s := strings.TrimSpace(string(data))
+			if !strings.HasPrefix(s, "gitdir:") {
+				return "", "", fmt.Errorf("%s: malformed .git file", p)
+			}
+			return d, resolveGitPath(d, strings.TrimSpace(strings.TrimPrefix(s, "gitdir:"))), nil
+		case !os.IsNotExist(err):
+			return "", "", err
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", "", fmt.Errorf("%s: not in a git repository: %w", dir, derrors.NotFound)
+		}
+		d = parent
+	}
+}
+
+// resolveGitPath resolves a path read from a file in git metadata, which may
+// be relative to dir.
+func resolveGitPath(dir, p string) string {
+	p = filepath.FromSlash(p)
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+var (
+	gitSectionRegexp = regexp.MustCompile(`^\[\s*([^\s\]"]+)(?:\s+"([^"]*)")?\s*\]`)
+	gitHashRegexp    = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// gitRemoteURL returns the URL of the "origin" remote in the git config
+// file contents, or of the first remote if there is no origin. It returns
+// the empty string if there are no remotes.
+func gitRemoteURL(config []byte) string {
+	var section, subsection, first string
+	scan := bufio.NewScanner(bytes.NewReader(config))
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		if m := gitSectionRegexp.FindStringSubmatch(line); m != nil {
+			section, subsection = strings.ToLower(m[1]), m[2]
+			continue
+		}
+		if section != "remote" {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "url" {
+			continue
+		}
+		u := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		if subsection == "origin" {
+			return u
+		}
+		if first == "" {
+			first = u
+		}
+	}
+	return first
+}
+
+// gitHead returns the hash of the commit at HEAD.
+func gitHead(gitDir, commonDir string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	head := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(head, "ref:") {
+		// A detached HEAD.
+		if !gitHashRegexp.MatchString(head) {
+			return "", fmt.Errorf("%s: malformed HEAD %q", gitDir, head)
+		}
+		return head, nil
+	}
+	ref := strings.TrimSpace(strings.TrimPrefix(head, "ref:"))
+	for _, d := range []string{gitDir, commonDir} {
+		data, err := ioutil.ReadFile(filepath.Join(d, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	// The ref may have been packed.
+	data, err = ioutil.ReadFile(filepath.Join(commonDir, "packed-refs"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref && gitHashRegexp.MatchString(fields[0]) {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%s: ref %q not found: %w", gitDir, ref, derrors.NotFound)
+}
+
+// remotePath converts a git remote URL to a host and path, like a module path
+// or a repo URL without its scheme. It handles URLs with a scheme, like
+// "https://github.com/a/b.git" or "ssh://git@host:7999/a/b.git", and scp-like
+// addresses, like "git@github.com:a/b.git".
+func remotePath(remote string) string {
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		host := u.Host
+		if u.Scheme != "http" && u.Scheme != "https" {
+			// The port of an SSH server is not the port of the web server.
+			host = u.Hostname()
+		}
+		return host + "/" + strings.Trim(u.Path, "/")
+	}
+	// An scp-like address: [user@]host:path.
+	i := strings.IndexByte(remote, ':')
+	if i < 0 || strings.Contains(remote[:i], "/") {
+		return ""
+	}
+	host := remote[:i]
+	if j := strings.LastIndexByte(host, '@'); j >= 0 {
+		host = host[j+1:]
+	}
+	return host + "/" + strings.Trim(remote[i+1:], "/")
+}
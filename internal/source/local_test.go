@@ -0,0 +1,128 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testHash = "0123456789abcdef0123456789abcdef01234567"
+
+func TestLocalInfo(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		files map[string]string
+		dir   string // module directory, relative to the temporary directory
+		// Wanted URLs; "ROOT" is replaced by the file URL of the temporary
+		// directory.
+		wantModule, wantLine string
+	}{
+		{
+			name: "github ssh remote",
+			files: map[string]string{
+				".git/config":          "[core]\n\tbare = false\n[remote \"upstream\"]\n\turl = https://github.com/fork/b\n[remote \"origin\"]\n\turl = git@github.com:a/b.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n",
+				".git/HEAD":            "ref: refs/heads/main\n",
+				".git/refs/heads/main": testHash + "\n",
+				"sub/go.mod":           "module example.com/b/sub\n",
+			},
+			dir:        "sub",
+			wantModule: "https://github.com/a/b/tree/" + testHash + "/sub",
+			wantLine:   "https://github.com/a/b/blob/" + testHash + "/sub/a.go#L3",
+		},
+		{
+			name: "packed ref",
+			files: map[string]string{
+				".git/config":      "[remote \"origin\"]\n\turl = https://gitlab.com/a/b.git\n",
+				".git/HEAD":        "ref: refs/heads/main\n",
+				".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + testHash + " refs/heads/main\n",
+			},
+			wantModule: "https://gitlab.com/a/b/tree/" + testHash,
+			wantLine:   "https://gitlab.com/a/b/blob/" + testHash + "/a.go#L3",
+		},
+		{
+			name: "worktree with detached head",
+			files: map[string]string{
+				"main/.git/config":                 "[remote \"origin\"]\n\turl = https://bitbucket.example.com/scm/proj/repo.git\n",
+				"main/.git/worktrees/wt/HEAD":      testHash + "\n",
+				"main/.git/worktrees/wt/commondir": "../..\n",
+				"wt/.git":                          "gitdir: ../main/.git/worktrees/wt\n",
+			},
+			dir:        "wt",
+			wantModule: "https://bitbucket.example.com/projects/proj/repos/repo/browse/?at=" + testHash,
+			wantLine:   "https://bitbucket.example.com/projects/proj/repos/repo/browse/a.go?at=" + testHash + "#3",
+		},
+		{
+			name: "unknown host",
+			files: map[string]string{
+				".git/config": "[remote \"origin\"]\n\turl = https://git.example.com/repo\n",
+				".git/HEAD":   testHash + "\n",
+			},
+			wantModule: "ROOT",
+			wantLine:   "ROOT/a.go#L3",
+		},
+		{
+			name:       "no git repository",
+			files:      map[string]string{"go.mod": "module example.com/m\n"},
+			wantModule: "ROOT",
+			wantLine:   "ROOT/a.go#L3",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tmp, err := ioutil.TempDir("", "localinfo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tmp)
+			for name, contents := range test.files {
+				p := filepath.Join(tmp, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dir := filepath.Join(tmp, test.dir)
+			info, err := LocalInfo(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root := newFileInfo(dir).RepoURL()
+			replaceRoot := func(s string) string {
+				if len(s) >= 4 && s[:4] == "ROOT" {
+					return root + s[4:]
+				}
+				return s
+			}
+			if got, want := info.ModuleURL(), replaceRoot(test.wantModule); got != want {
+				t.Errorf("ModuleURL:\ngot  %s\nwant %s", got, want)
+			}
+			if got, want := info.LineURL("a.go", 3), replaceRoot(test.wantLine); got != want {
+				t.Errorf("LineURL:\ngot  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestRemotePath(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"https://github.com/a/b.git", "github.com/a/b.git"},
+		{"https://user@gitlab.example.com:8443/a/b/", "gitlab.example.com:8443/a/b"},
+		{"ssh://git@bitbucket.example.com:7999/proj/repo.git", "bitbucket.example.com/proj/repo.git"},
+		{"git@github.com:a/b.git", "github.com/a/b.git"},
+		{"github.com:a/b", "github.com/a/b"},
+		{"/local/path/repo", ""},
+		{"../relative/repo", ""},
+	} {
+		if got := remotePath(test.in); got != test.want {
+			t.Errorf("remotePath(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// A provider constructs source information for repositories on a kind of code
+// host whose URLs can't be described by a pattern and URL templates alone,
+// because the repo URL must be rewritten or the form of a URL depends on
+// whether the version refers to a tag or a commit hash.
+//
+// Providers are consulted when no pattern with URL templates matches a module
+// path or repo URL. They recognize hosts by the structure of their paths, so
+// they work for self-hosted instances whose host names are not known in
+// advance.
+type provider struct {
+	name string
+	// Each regexp must match a prefix of the target string.
+	res []*regexp.Regexp
+	// newInfo returns the Info for the module in directory moduleDir of the
+	// repo, at the given commit. match holds the submatches of the regexp by
+	// group name.
+	newInfo func(match map[string]string, moduleDir, commit string, isHash bool) *Info
+}
+
+var providers = []*provider{
+	{
+		// Gitiles, the web interface for Gerrit and googlesource.com. It
+		// is recognized by the "/+/" in the URL templates of go-source meta
+		// tags, or the path under which Gerrit serves it.
+		name: "gitiles",
+		res: []*regexp.Regexp{
+			regexp.MustCompile(`^(?P<repo>[a-z0-9.\-]+(:[0-9]+)?/plugins/gitiles(/[A-Za-z0-9_.\-]+)+?)(\.git)?(/\+/|$)`),
+			regexp.MustCompile(`^(?P<repo>[a-z0-9.\-]+(:[0-9]+)?(/[A-Za-z0-9_.\-]+)+?)(\.git)?/\+/`),
+		},
+		newInfo: func(m map[string]string, moduleDir, commit string, isHash bool) *Info {
+			if !isHash {
+				// Qualify tags, so that they are not mistaken for branches.
+				commit = "refs/tags/" + commit
+			}
+			return &Info{
+				repoURL:   "https://" + m["repo"],
+				moduleDir: moduleDir,
+				commit:    commit,
+				templates: gitilesURLTemplates,
+			}
+		},
+	},
+	{
+		// Azure DevOps. Files are selected by query parameters, and the
+		// version parameter is prefixed with "GT" for tags and "GC" for
+		// commits. Module paths may omit the "_git" element of the repo URL.
+		name: "azure",
+		res: []*regexp.Regexp{
+			regexp.MustCompile(`^(?P<host>dev\.azure\.com/(?P<org>[A-Za-z0-9_.\-]+))/(?P<project>[A-Za-z0-9_.\-]+)/_git/(?P<name>[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*?)(\.git|/|$)`),
+			regexp.MustCompile(`^(?P<host>dev\.azure\.com/(?P<org>[A-Za-z0-9_.\-]+))/(?P<project>[A-Za-z0-9_.\-]+)/(?P<name>[A-Za-z0-9_.\-]+?)\.git`),
+			regexp.MustCompile(`^(?P<host>(?P<org>[a-z0-9\-]+)\.visualstudio\.com)/(?P<project>[A-Za-z0-9_.\-]+)/_git/(?P<name>[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*?)(\.git|/|$)`),
+		},
+		newInfo: func(m map[string]string, moduleDir, commit string, isHash bool) *Info {
+			versionType, prefix := "tag", "GT"
+			if isHash {
+				versionType, prefix = "commit", "GC"
+			}
+			templates := azureURLTemplates
+			templates.Raw = fmt.Sprintf("https://%s/%s/_apis/git/repositories/%s/items?path=/{file}&versionDescriptor.versionType=%s&versionDescriptor.version=%s",
+				m["host"], m["project"], m["name"], versionType, commit)
+			return &Info{
+				repoURL:   fmt.Sprintf("https://%s/%s/_git/%s", m["host"], m["project"], m["name"]),
+				moduleDir: moduleDir,
+				commit:    prefix + commit,
+				templates: templates,
+			}
+		},
+	},
+	{
+		// Bitbucket Server (formerly Stash). Repos are cloned from
+		// /scm/<project>/<repo>.git but browsed at
+		// /projects/<project>/repos/<repo>.
+		name: "bitbucketserver",
+		res: []*regexp.Regexp{
+			regexp.MustCompile(`^(?P<host>[a-z0-9.\-]+(:[0-9]+)?(/[A-Za-z0-9_.\-]+)*?)/scm/(?P<project>~?[A-Za-z0-9_.\-]+)/(?P<name>[A-Za-z0-9_.\-]+?)\.git`),
+			regexp.MustCompile(`^(?P<host>[a-z0-9.\-]+(:[0-9]+)?(/[A-Za-z0-9_.\-]+)*?)/(?P<kind>projects|users)/(?P<project>[A-Za-z0-9_.\-]+)/repos/(?P<name>[A-Za-z0-9_.\-]+)(/browse|/?$)`),
+		},
+		newInfo: func(m map[string]string, moduleDir, commit string, isHash bool) *Info {
+			if !isHash {
+				commit = "refs/tags/" + commit
+			}
+			project := m["project"]
+			if m["kind"] == "users" {
+				// Personal repos belong to the project "~<user>".
+				project = "~" + project
+			}
+			return &Info{
+				repoURL:   fmt.Sprintf("https://%s/projects/%s/repos/%s", m["host"], project, m["name"]),
+				moduleDir: moduleDir,
+				commit:    commit,
+				templates: bitbucketServerURLTemplates,
+			}
+		},
+	},
+}
+
+var (
+	gitilesURLTemplates = urlTemplates{
+		Directory: "{repo}/+/{commit}/{dir}",
+		File:      "{repo}/+/{commit}/{file}",
+		Line:      "{repo}/+/{commit}/{file}#{line}",
+		// Gitiles serves file contents only base64-encoded.
+	}
+	azureURLTemplates = urlTemplates{
+		Directory: "{repo}?path=/{dir}&version={commit}",
+		File:      "{repo}?path=/{file}&version={commit}",
+		Line:      "{repo}?path=/{file}&version={commit}&line={line}&lineEnd={line}&lineStartColumn=1&lineEndColumn=1",
+		// Raw depends on the kind of commit; see the provider.
+	}
+	bitbucketServerURLTemplates = urlTemplates{
+		Directory: "{repo}/browse/{dir}?at={commit}",
+		File:      "{repo}/browse/{file}?at={commit}",
+		Line:      "{repo}/browse/{file}?at={commit}#{line}",
+		Raw:       "{repo}/raw/{file}?at={commit}",
+	}
+)
+
+// matchProvider matches moduleOrRepoPath against the providers. If one matches,
+// it returns a function that constructs the Info for a module directory and
+// commit in the matched repo, and the part of moduleOrRepoPath after the repo.
+// Otherwise it returns nil.
+func matchProvider(moduleOrRepoPath string) (newInfo func(moduleDir, commit string, isHash bool) *Info, rest string) {
+	for _, p := range providers {
+		for _, re := range p.res {
+			matches := re.FindStringSubmatch(moduleOrRepoPath)
+			if matches == nil {
+				continue
+			}
+			m := map[string]string{}
+			for i, n := range re.SubexpNames() {
+				if n != "" {
+					m[n] = matches[i]
+				}
+			}
+			p := p
+			newInfo = func(moduleDir, commit string, isHash bool) *Info {
+				return p.newInfo(m, moduleDir, commit, isHash)
+			}
+			return newInfo, moduleOrRepoPath[len(matches[0]):]
+		}
+	}
+	return nil, ""
+}
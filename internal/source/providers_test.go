@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	// testdata/providers.json holds the web pages served to the client, which
+	// provide meta tags for vanity import paths, and the URLs expected for
+	// each module.
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "providers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture struct {
+		Web   map[string]string
+		Tests []struct {
+			ModulePath, Version     string
+			Repo, Module, Line, Raw string
+		}
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(testTimeout)
+	client.httpClient.Transport = testTransport(fixture.Web)
+	for _, test := range fixture.Tests {
+		t.Run(test.ModulePath, func(t *testing.T) {
+			info, err := ModuleInfo(context.Background(), client, test.ModulePath, test.Version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range []struct {
+				name, got, want string
+			}{
+				{"repo", info.RepoURL(), test.Repo},
+				{"module", info.ModuleURL(), test.Module},
+				{"line", info.LineURL("a.go", 5), test.Line},
+				{"raw", info.RawURL("a.go"), test.Raw},
+			} {
+				if c.got != c.want {
+					t.Errorf("%s URL:\ngot  %s\nwant %s", c.name, c.got, c.want)
+				}
+			}
+
+			// The Info survives a round trip through the database encoding.
+			data, err := json.Marshal(info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Info
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != *info {
+				t.Errorf("after JSON round trip, got %#v, want %#v", got, *info)
+			}
+		})
+	}
+}
+
+func TestMatchProviderNoMatch(t *testing.T) {
+	for _, path := range []string{
+		"github.com/a/b",
+		"example.com/scm/a/b",
+		"example.com/projects/a/b",
+		"dev.azure.com/org/proj",
+		"git.example.com/repo.git",
+	} {
+		if newInfo, _ := matchProvider(path); newInfo != nil {
+			t.Errorf("matchProvider(%q) matched, want no match", path)
+		}
+	}
+}
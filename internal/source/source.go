@@ -63,6 +63,15 @@ func (i *Info) RepoURL() string {
 	})
 }
 
+// ModuleDir returns the directory of the module relative to the root of the
+// repository.
+func (i *Info) ModuleDir() string {
+	if i == nil {
+		return ""
+	}
+	return i.moduleDir
+}
+
 // ModuleURL returns a URL for the home page of the module.
 func (i *Info) ModuleURL() string {
 	return i.DirectoryURL("")
@@ -206,6 +215,10 @@ func (i *Info) UnmarshalJSON(data []byte) (err error) {
 type Client struct {
 	// client used for HTTP requests. It is mutable for testing purposes.
 	httpClient *http.Client
+	// If non-nil, discoveryCache stores the results of fetching meta tags for
+	// discoveryTTL. See SetDiscoveryCache.
+	discoveryCache DiscoveryCache
+	discoveryTTL   time.Duration
 }
 
 // New constructs a *Client using the provided timeout.
@@ -256,12 +269,24 @@ func ModuleInfo(ctx context.Context, client *Client, modulePath, version string)
 		return newStdlibInfo(version)
 	}
 	repo, relativeModulePath, templates, transformCommit, err := matchStatic(modulePath)
-	if err != nil {
+	var newInfo func(moduleDir, commit string, isHash bool) *Info
+	if templates == (urlTemplates{}) {
+		// No pattern provides URL templates, but a provider may.
+		var rest string
+		if newInfo, rest = matchProvider(modulePath); newInfo != nil {
+			relativeModulePath = strings.TrimPrefix(rest, "/")
+		}
+	}
+	switch {
+	case newInfo != nil:
+		commit, isHash := commitFromVersion(version, relativeModulePath)
+		info = newInfo(relativeModulePath, commit, isHash)
+	case err != nil:
 		info, err = moduleInfoDynamic(ctx, client, modulePath, version)
 		if err != nil {
 			return nil, err
 		}
-	} else {
+	default:
 		commit, isHash := commitFromVersion(version, relativeModulePath)
 		if transformCommit != nil {
 			commit = transformCommit(commit, isHash)
@@ -326,10 +351,15 @@ func matchStatic(moduleOrRepoPath string) (repo, relativeModulePath string, _ ur
 			continue
 		}
 		var repo string
+		templates := pat.templates
 		for i, n := range pat.re.SubexpNames() {
-			if n == "repo" {
+			switch n {
+			case "":
+			case "repo":
 				repo = matches[i]
-				break
+			default:
+				// Other named groups are template variables.
+				templates = templates.expand(map[string]string{n: matches[i]})
 			}
 		}
 		// Special case: git.apache.org has a go-import tag that points to
@@ -341,7 +371,7 @@ func matchStatic(moduleOrRepoPath string) (repo, relativeModulePath string, _ ur
 		}
 		relativeModulePath = strings.TrimPrefix(moduleOrRepoPath, matches[0])
 		relativeModulePath = strings.TrimPrefix(relativeModulePath, "/")
-		return repo, relativeModulePath, pat.templates, pat.transformCommit, nil
+		return repo, relativeModulePath, templates, pat.transformCommit, nil
 	}
 	return "", "", urlTemplates{}, nil, derrors.NotFound
 }
@@ -350,7 +380,7 @@ func matchStatic(moduleOrRepoPath string) (repo, relativeModulePath string, _ ur
 func moduleInfoDynamic(ctx context.Context, client *Client, modulePath, version string) (_ *Info, err error) {
 	defer derrors.Wrap(&err, "source.moduleInfoDynamic(ctx, client, %q, %q)", modulePath, version)
 
-	sourceMeta, err := fetchMeta(ctx, client, modulePath)
+	sourceMeta, err := discoverMeta(ctx, client, modulePath)
 	if err != nil {
 		return nil, err
 	}
@@ -369,15 +399,24 @@ func moduleInfoDynamic(ctx context.Context, client *Client, modulePath, version
 	// We resolve these problems as follows:
 	// 1. First look at the repo URL from the tag. If that matches a known hosting site, use the
 	//    URL templates corresponding to that site and ignore whatever's in the tag.
-	// 2. Then look at the URL templates to see if they match a known pattern, and use the templates
+	// 2. Then see if a provider recognizes the repo URL or the URL templates, and let it construct
+	//    the Info. Providers handle hosts like Bitbucket Server whose URLs need custom logic.
+	// 3. Then look at the URL templates to see if they match a known pattern, and use the templates
 	//    from that pattern. For example, the meta tags for gopkg.in/yaml.v2 only mention github
 	//    in the URL templates, like "https://github.com/go-yaml/yaml/tree/v2.2.3{/dir}". We can observe
 	//    that that template begins with a known pattern--a GitHub repo, ignore the rest of it, and use the
 	//    GitHub URL templates that we know.
 	repoURL := sourceMeta.repoURL
+	dir := strings.TrimPrefix(strings.TrimPrefix(modulePath, sourceMeta.repoRootPrefix), "/")
 	_, _, templates, transformCommit, _ := matchStatic(removeHTTPScheme(repoURL))
 	// If err != nil, templates will be the zero value, so we can ignore it (same just below).
 	if templates == (urlTemplates{}) {
+		for _, u := range []string{repoURL, sourceMeta.dirTemplate} {
+			if newInfo, _ := matchProvider(removeHTTPScheme(u)); newInfo != nil {
+				commit, isHash := commitFromVersion(version, dir)
+				return newInfo(dir, commit, isHash), nil
+			}
+		}
 		var repo string
 		repo, _, templates, transformCommit, _ = matchStatic(removeHTTPScheme(sourceMeta.dirTemplate))
 		if templates == (urlTemplates{}) {
@@ -387,7 +426,6 @@ func moduleInfoDynamic(ctx context.Context, client *Client, modulePath, version
 			repoURL = "https://" + repo
 		}
 	}
-	dir := strings.TrimPrefix(strings.TrimPrefix(modulePath, sourceMeta.repoRootPrefix), "/")
 	commit, isHash := commitFromVersion(version, dir)
 	if transformCommit != nil {
 		commit = transformCommit(commit, isHash)
@@ -452,13 +490,9 @@ func removeVersionSuffix(s string) string {
 // Patterns for determining repo and URL templates from module paths or repo
 // URLs. Each regexp must match a prefix of the target string, and must have a
 // group named "repo".
-var patterns = []struct {
-	pattern   string // uncompiled regexp
-	templates urlTemplates
-	re        *regexp.Regexp
-	// transformCommit may alter the commit before substitution
-	transformCommit func(commit string, isHash bool) string
-}{
+//
+// Additional patterns can be added with AddHosts.
+var patterns = []hostPattern{
 	{
 		pattern:   `^(?P<repo>github\.com/[a-z0-9A-Z_.\-]+/[a-z0-9A-Z_.\-]+)`,
 		templates: githubURLTemplates,
@@ -575,24 +609,41 @@ var patterns = []struct {
 	},
 }
 
+// hostPattern associates a pattern for module paths or repo URLs with the URL
+// templates for the code host that serves them.
+type hostPattern struct {
+	pattern   string // uncompiled regexp
+	templates urlTemplates
+	re        *regexp.Regexp
+	// transformCommit may alter the commit before substitution
+	transformCommit func(commit string, isHash bool) string
+}
+
 func init() {
 	for i := range patterns {
-		re := regexp.MustCompile(patterns[i].pattern)
-		// The pattern regexp must contain a group named "repo".
-		found := false
-		for _, n := range re.SubexpNames() {
-			if n == "repo" {
-				found = true
-				break
-			}
-		}
-		if !found {
-			panic(fmt.Sprintf("pattern %s missing <repo> group", patterns[i].pattern))
+		re, err := compilePattern(patterns[i].pattern)
+		if err != nil {
+			panic(err)
 		}
 		patterns[i].re = re
 	}
 }
 
+// compilePattern compiles a pattern regexp, which must contain a group named
+// "repo".
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range re.SubexpNames() {
+		if n == "repo" {
+			return re, nil
+		}
+	}
+	return nil, fmt.Errorf("pattern %s missing <repo> group", pattern)
+}
+
 // giteaTransformCommit transforms commits for the Gitea code hosting system.
 func giteaTransformCommit(commit string, isHash bool) string {
 	// Hashes use "commit", tags use "tag".
@@ -624,6 +675,18 @@ type urlTemplates struct {
 	Raw       string // Optional URL template for the raw contents of a file, with {repo}, {commit}, {file}.
 }
 
+// expand returns a copy of t with the variables in match replaced in each
+// template.
+func (t urlTemplates) expand(match map[string]string) urlTemplates {
+	return urlTemplates{
+		Repo:      expand(t.Repo, match),
+		Directory: expand(t.Directory, match),
+		File:      expand(t.File, match),
+		Line:      expand(t.Line, match),
+		Raw:       expand(t.Raw, match),
+	}
+}
+
 var (
 	githubURLTemplates = urlTemplates{
 		Directory: "{repo}/tree/{commit}/{dir}",
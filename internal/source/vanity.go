@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A VanityImport describes the repositories of the modules under a vanity
+// import path prefix, for serving their go-import and go-source meta tags.
+type VanityImport struct {
+	// Prefix is the import path prefix, like "go.example.com/tools".
+	Prefix string
+	// VCS is the version control system of the repositories, like "git".
+	VCS string
+	// RepoURL is the URL of the repository whose root is Prefix. If it
+	// contains "{name}", then Prefix holds many repositories: the repository
+	// root for an import path is Prefix followed by the next path element,
+	// and {name} is replaced by that element.
+	RepoURL string
+}
+
+// ParseVanityImport parses s, which has the same form as the content of a
+// go-import meta tag: a prefix, a VCS and a repo URL, separated by spaces.
+func ParseVanityImport(s string) (*VanityImport, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("vanity import %q: want three fields, got %d", s, len(fields))
+	}
+	v := &VanityImport{Prefix: strings.TrimSuffix(fields[0], "/"), VCS: fields[1], RepoURL: fields[2]}
+	if !strings.Contains(v.RepoURL, "://") {
+		return nil, fmt.Errorf("vanity import %q: repo URL %q has no scheme", s, v.RepoURL)
+	}
+	if strings.Count(v.RepoURL, "{") != strings.Count(v.RepoURL, "{name}") {
+		return nil, fmt.Errorf("vanity import %q: repo URL may only contain the variable {name}", s)
+	}
+	return v, nil
+}
+
+// MetaTags returns the contents of the go-import and go-source meta tags for
+// importPath, and true, if importPath is under v.Prefix. goSource is empty if
+// the repo is not on a code host whose URLs are known.
+//
+// The go-source URL templates refer to the HEAD of the repo, since the
+// go-source tag has no way to specify a version.
+func (v *VanityImport) MetaTags(importPath string) (goImport, goSource string, ok bool) {
+	if importPath != v.Prefix && !strings.HasPrefix(importPath, v.Prefix+"/") {
+		return "", "", false
+	}
+	root, repoURL := v.Prefix, v.RepoURL
+	if strings.Contains(repoURL, "{name}") {
+		rest := strings.TrimPrefix(strings.TrimPrefix(importPath, v.Prefix), "/")
+		if rest == "" {
+			return "", "", false
+		}
+		name := strings.SplitN(rest, "/", 2)[0]
+		root = v.Prefix + "/" + name
+		repoURL = strings.ReplaceAll(repoURL, "{name}", name)
+	}
+	goImport = fmt.Sprintf("%s %s %s", root, v.VCS, repoURL)
+
+	repo, _, templates, _, _ := matchStatic(strings.TrimSuffix(removeHTTPScheme(repoURL), ".git"))
+	if templates == (urlTemplates{}) {
+		return goImport, "", true
+	}
+	// Convert the templates to the format of the go-source tag, described at
+	// https://github.com/golang/gddo/wiki/Source-Code-Links.
+	match := map[string]string{
+		"repo":   "https://" + repo,
+		"commit": "HEAD",
+		"dir":    "{dir}",
+		"file":   "{file}",
+		"line":   "{line}",
+	}
+	dir := strings.Replace(expand(templates.Directory, match), "/{dir}", "{/dir}", 1)
+	file := expand(templates.Line, match)
+	goSource = fmt.Sprintf("%s %s %s %s", root, "https://"+repo, dir, file)
+	return goImport, goSource, true
+}
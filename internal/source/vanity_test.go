@@ -0,0 +1,69 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import "testing"
+
+func TestVanityImportMetaTags(t *testing.T) {
+	for _, test := range []struct {
+		spec, importPath       string
+		wantImport, wantSource string
+		wantOK                 bool
+	}{
+		{
+			"go.example.com/tools git https://github.com/example/tools",
+			"go.example.com/tools/cmd/x",
+			"go.example.com/tools git https://github.com/example/tools",
+			"go.example.com/tools https://github.com/example/tools https://github.com/example/tools/tree/HEAD{/dir} https://github.com/example/tools/blob/HEAD/{file}#L{line}",
+			true,
+		},
+		{
+			"go.example.com git https://gitlab.com/example/{name}.git",
+			"go.example.com/lib/sub",
+			"go.example.com/lib git https://gitlab.com/example/lib.git",
+			"go.example.com/lib https://gitlab.com/example/lib https://gitlab.com/example/lib/tree/HEAD{/dir} https://gitlab.com/example/lib/blob/HEAD/{file}#L{line}",
+			true,
+		},
+		{
+			"go.example.com hg https://hg.example.com/{name}",
+			"go.example.com/lib",
+			"go.example.com/lib hg https://hg.example.com/lib",
+			"",
+			true,
+		},
+		{
+			"go.example.com git https://git.example.com/{name}",
+			"go.example.com",
+			"", "", false,
+		},
+		{
+			"go.example.com/tools git https://github.com/example/tools",
+			"go.example.com/toolsx",
+			"", "", false,
+		},
+	} {
+		v, err := ParseVanityImport(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotImport, gotSource, gotOK := v.MetaTags(test.importPath)
+		if gotImport != test.wantImport || gotSource != test.wantSource || gotOK != test.wantOK {
+			t.Errorf("%q, %q:\ngot  (%q, %q, %t)\nwant (%q, %q, %t)", test.spec, test.importPath,
+				gotImport, gotSource, gotOK, test.wantImport, test.wantSource, test.wantOK)
+		}
+	}
+}
+
+func TestParseVanityImportInvalid(t *testing.T) {
+	for _, s := range []string{
+		"go.example.com git",
+		"go.example.com git github.com/example/tools",
+		"go.example.com git https://github.com/example/{repo}",
+	} {
+		if _, err := ParseVanityImport(s); err == nil {
+			t.Errorf("%q: got nil, want error", s)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdlib
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// LocalRoots, if non-empty, lists directories from which the standard library
+// is read instead of from the Go repo, for machines that cannot reach it.
+// Each directory is either a GOROOT, with a VERSION file at its root, or a
+// directory of extracted Go release archives, each of whose subdirectories is
+// a GOROOT or contains one named "go".
+//
+// LocalRoots is meant to be set at startup.
+var LocalRoots []string
+
+// A localRoot is a GOROOT of a Go release.
+type localRoot struct {
+	dir        string
+	commitTime time.Time
+}
+
+// localVersions returns the versions of the Go releases in LocalRoots.
+func localVersions() (_ []string, err error) {
+	roots, err := localGoRoots()
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for v := range roots {
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// localGoRoots returns the GOROOTs in LocalRoots, keyed by semantic version.
+// If there is more than one GOROOT for a version, the first is used.
+func localGoRoots() (_ map[string]*localRoot, err error) {
+	defer derrors.Wrap(&err, "localGoRoots()")
+
+	roots := map[string]*localRoot{}
+	add := func(dir string) error {
+		v, r, err := readLocalRoot(dir)
+		if err != nil || v == "" {
+			return err
+		}
+		if roots[v] == nil {
+			roots[v] = r
+		}
+		return nil
+	}
+	for _, dir := range LocalRoots {
+		if _, err := os.Stat(filepath.Join(dir, "VERSION")); err == nil {
+			if err := add(dir); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range fis {
+			if !fi.IsDir() {
+				continue
+			}
+			sub := filepath.Join(dir, fi.Name())
+			if _, err := os.Stat(filepath.Join(sub, "go", "VERSION")); err == nil {
+				// An archive extracted into its own directory.
+				sub = filepath.Join(sub, "go")
+			}
+			if err := add(sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return roots, nil
+}
+
+// readLocalRoot reads the VERSION file of the GOROOT dir. It returns the
+// semantic version of the release, or the empty string if dir has no VERSION
+// file or it is not for a release, as in a development build.
+//
+// The first line of the file is the tag of the release. Newer releases also
+// have a line "time <RFC 3339 time>", which is used as the commit time;
+// otherwise the modification time of the file is used.
+func readLocalRoot(dir string) (string, *localRoot, error) {
+	f := filepath.Join(dir, "VERSION")
+	data, err := ioutil.ReadFile(f)
+	if os.IsNotExist(err) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	v := VersionForTag(strings.TrimSpace(lines[0]))
+	if v == "" {
+		return "", nil, nil
+	}
+	r := &localRoot{dir: dir}
+	for _, line := range lines[1:] {
+		if t := strings.TrimPrefix(line, "time "); t != line {
+			r.commitTime, err = time.Parse(time.RFC3339, strings.TrimSpace(t))
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %v", f, err)
+			}
+		}
+	}
+	if r.commitTime.IsZero() {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return "", nil, err
+		}
+		r.commitTime = fi.ModTime().UTC()
+	}
+	return v, r, nil
+}
+
+// localZip is like Zip, but reads the standard library from the GOROOT in
+// LocalRoots for resolvedVersion.
+func localZip(resolvedVersion string) (_ *zip.Reader, commitTime time.Time, err error) {
+	roots, err := localGoRoots()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	root := roots[resolvedVersion]
+	if root == nil {
+		return nil, time.Time{}, fmt.Errorf("no local GOROOT for %s: %w", resolvedVersion, derrors.NotFound)
+	}
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	prefixPath := ModulePath + "@" + resolvedVersion
+	// Add top-level files.
+	if err := addLocalFiles(z, root.dir, prefixPath, false); err != nil {
+		return nil, time.Time{}, err
+	}
+	// Add files from the stdlib directory.
+	libdir := filepath.Join(root.dir, filepath.FromSlash(Directory(resolvedVersion)))
+	if err := addLocalFiles(z, libdir, prefixPath, true); err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := z.Close(); err != nil {
+		return nil, time.Time{}, err
+	}
+	br := bytes.NewReader(buf.Bytes())
+	zr, err := zip.NewReader(br, int64(br.Len()))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return zr, root.commitTime, nil
+}
+
+// addLocalFiles is like addFiles, but adds the files in the directory dir.
+func addLocalFiles(z *zip.Writer, dir, dirpath string, recursive bool) (err error) {
+	defer derrors.Wrap(&err, "addLocalFiles(zip, %q, %q, %t)", dir, dirpath, recursive)
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if excludeFile(fi.Name(), dirpath) {
+			continue
+		}
+		switch {
+		case fi.Mode().IsRegular():
+			f, err := os.Open(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				return err
+			}
+			err = writeZipFile(z, path.Join(dirpath, fi.Name()), f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case fi.IsDir():
+			if !recursive || fi.Name() == "testdata" {
+				continue
+			}
+			if err := addLocalFiles(z, filepath.Join(dir, fi.Name()), path.Join(dirpath, fi.Name()), recursive); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdlib
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+// copyDir copies the files in the directory src to dst.
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dst, rel), data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// zipFileNames returns the names of the files in zr, except for the top-level
+// VERSION file, which is missing from some of the test data.
+func zipFileNames(zr *zip.Reader) []string {
+	var names []string
+	for _, f := range zr.File {
+		if path.Base(f.Name) == "VERSION" && strings.Count(f.Name, "/") == 1 {
+			continue
+		}
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestLocalRoots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stdlib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A directory of extracted release archives, one of them with a time in
+	// its VERSION file, and a GOROOT.
+	archives := filepath.Join(dir, "archives")
+	goroot := filepath.Join(dir, "goroot")
+	for _, r := range []struct {
+		version, dir, contents string
+	}{
+		{"v1.14.6", filepath.Join(archives, "go1.14.6", "go"), "go1.14.6\ntime 2020-07-16T22:00:00Z\n"},
+		{"v1.3.2", filepath.Join(archives, "go1.3.2"), "go1.3.2"},
+		{"v1.12.5", goroot, "go1.12.5"},
+	} {
+		copyDir(t, filepath.Join(testhelper.TestDataPath("testdata"), r.version), r.dir)
+		if err := ioutil.WriteFile(filepath.Join(r.dir, "VERSION"), []byte(r.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A development build is ignored.
+	if err := os.MkdirAll(filepath.Join(archives, "devel"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(archives, "devel", "VERSION"), []byte("devel +abcdef"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	LocalRoots = []string{archives, goroot}
+	defer func() { LocalRoots = nil }()
+
+	got, err := Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"v1.12.5", "v1.14.6", "v1.3.2"}, got); diff != "" {
+		t.Errorf("Versions() mismatch (-want +got):\n%s", diff)
+	}
+	if v, _, err := ZipInfo("latest"); err != nil || v != "v1.14.6" {
+		t.Errorf(`ZipInfo("latest") = %q, %v, want "v1.14.6", nil`, v, err)
+	}
+
+	for _, v := range []string{"v1.14.6", "v1.12.5", "v1.3.2"} {
+		t.Run(v, func(t *testing.T) {
+			zr, commitTime, err := Zip(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v == "v1.14.6" {
+				if want := time.Date(2020, 7, 16, 22, 0, 0, 0, time.UTC); !commitTime.Equal(want) {
+					t.Errorf("commit time: got %s, want %s", commitTime, want)
+				}
+			}
+			// The layout must match that of a zip made from the Go repo.
+			LocalRoots, UseTestData = nil, true
+			wantZR, _, err := Zip(v)
+			LocalRoots, UseTestData = []string{archives, goroot}, false
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(zipFileNames(wantZR), zipFileNames(zr)); diff != "" {
+				t.Errorf("zip files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, _, err := Zip("v1.15.0"); err == nil {
+		t.Error("Zip(v1.15.0): got nil, want error")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdlib
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/zipio"
+)
+
+// ZipDir, if non-empty, is a directory in which the worker saves the zips it
+// creates with Zip, so that the frontend can read the source of the standard
+// library without cloning the Go repo. It is laid out like the module cache,
+// with the zip of each version at ZipDir/std/@v/<escaped version>.zip.
+//
+// ZipDir is meant to be set at startup.
+var ZipDir string
+
+// savedZipPath returns the name of the file in ZipDir holding the zip of
+// resolvedVersion.
+func savedZipPath(resolvedVersion string) (string, error) {
+	ev, err := module.EscapeVersion(resolvedVersion)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(ZipDir, ModulePath, "@v", ev+".zip"), nil
+}
+
+// SaveZip writes zr, the zip of resolvedVersion returned by Zip, to ZipDir.
+// It does nothing if ZipDir is empty. Concurrent readers never see a partial
+// zip.
+func SaveZip(zr *zip.Reader, resolvedVersion string) (err error) {
+	defer derrors.Wrap(&err, "stdlib.SaveZip(%q)", resolvedVersion)
+
+	if ZipDir == "" {
+		return nil
+	}
+	filename, err := savedZipPath(resolvedVersion)
+	if err != nil {
+		return err
+	}
+	return zipio.WriteFile(filename, zr)
+}
+
+// SavedZip returns the zip of resolvedVersion saved in ZipDir by SaveZip. It
+// returns an error wrapping derrors.NotFound if there is no such zip.
+func SavedZip(resolvedVersion string) (_ *zip.Reader, err error) {
+	defer derrors.Wrap(&err, "stdlib.SavedZip(%q)", resolvedVersion)
+
+	if ZipDir == "" {
+		return nil, fmt.Errorf("no directory of saved zips: %w", derrors.NotFound)
+	}
+	filename, err := savedZipPath(resolvedVersion)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zipio.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%v: %w", err, derrors.NotFound)
+	}
+	return zr, err
+}
@@ -18,6 +18,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/mod/semver"
@@ -37,6 +38,12 @@ import (
 // ModulePath is the name of the module for the standard library.
 const ModulePath = "std"
 
+// MasterVersion is the requested version for the head of the master branch of
+// the Go repo. It resolves to a pseudo-version like
+// "v0.0.0-20201015141213-abcdefabcdef", which names the commit it was built
+// from.
+const MasterVersion = "master"
+
 var (
 	// Regexp for matching go tags. The groups are:
 	// 1  the major.minor version
@@ -48,7 +55,8 @@ var (
 )
 
 // VersionForTag returns the semantic version for the Go tag, or "" if
-// tag doesn't correspond to a Go release or beta tag.
+// tag doesn't correspond to a Go release or beta tag. The tag "master" and
+// pseudo-versions of the master branch are returned unchanged.
 // Examples:
 //   "go1" => "v1.0.0"
 //   "go1.2" => "v1.2.0"
@@ -62,9 +70,12 @@ func VersionForTag(tag string) string {
 	if tag == "go1.0" {
 		return ""
 	}
-	// Special case for latest.
-	if tag == "latest" {
-		return "latest"
+	// Special cases for latest and master.
+	if tag == "latest" || tag == MasterVersion {
+		return tag
+	}
+	if version.IsPseudo(tag) {
+		return tag
 	}
 	m := tagRegexp.FindStringSubmatch(tag)
 	if m == nil {
@@ -85,17 +96,23 @@ func VersionForTag(tag string) string {
 // TagForVersion returns the Go standard library repository tag corresponding
 // to semver. The Go tags differ from standard semantic versions in a few ways,
 // such as beginning with "go" instead of "v".
-func TagForVersion(version string) (_ string, err error) {
-	defer derrors.Wrap(&err, "TagForVersion(%q)", version)
+//
+// The Go repo has no tags for pseudo-versions, which name commits on the
+// master branch; for them, TagForVersion returns the commit hash.
+func TagForVersion(v string) (_ string, err error) {
+	defer derrors.Wrap(&err, "TagForVersion(%q)", v)
 
 	// Special case: v1.0.0 => go1.
-	if version == "v1.0.0" {
+	if v == "v1.0.0" {
 		return "go1", nil
 	}
-	if !semver.IsValid(version) {
-		return "", fmt.Errorf("%w: requested version is not a valid semantic version: %q ", derrors.InvalidArgument, version)
+	if version.IsPseudo(v) {
+		return pseudoVersionRev(v), nil
 	}
-	goVersion := semver.Canonical(version)
+	if !semver.IsValid(v) {
+		return "", fmt.Errorf("%w: requested version is not a valid semantic version: %q", derrors.InvalidArgument, v)
+	}
+	goVersion := semver.Canonical(v)
 	prerelease := semver.Prerelease(goVersion)
 	versionWithoutPrerelease := strings.TrimSuffix(goVersion, prerelease)
 	patch := strings.TrimPrefix(versionWithoutPrerelease, semver.MajorMinor(goVersion)+".")
@@ -122,11 +139,15 @@ func TagForVersion(version string) (_ string, err error) {
 }
 
 // MajorVersionForVersion returns the Go major version for version.
-// E.g. "v1.13.3" => "go1".
-func MajorVersionForVersion(version string) (_ string, err error) {
-	defer derrors.Wrap(&err, "MajorVersionForVersion(%q)", version)
+// E.g. "v1.13.3" => "go1". Pseudo-versions of the master branch have the
+// major version "master", since they may precede a new major version.
+func MajorVersionForVersion(v string) (_ string, err error) {
+	defer derrors.Wrap(&err, "MajorVersionForVersion(%q)", v)
 
-	tag, err := TagForVersion(version)
+	if version.IsPseudo(v) {
+		return MasterVersion, nil
+	}
+	tag, err := TagForVersion(v)
 	if err != nil {
 		return "", err
 	}
@@ -140,6 +161,17 @@ func MajorVersionForVersion(version string) (_ string, err error) {
 	return tag[:i], nil
 }
 
+// IsPrerelease reports whether version is a prerelease of Go: a beta, a
+// release candidate, or a pseudo-version of the master branch.
+func IsPrerelease(v string) bool {
+	return v == MasterVersion || semver.Prerelease(v) != ""
+}
+
+// pseudoVersionRev returns the commit hash at the end of the pseudo-version v.
+func pseudoVersionRev(v string) string {
+	return v[strings.LastIndex(v, "-")+1:]
+}
+
 // finalDigitsIndex returns the index of the first digit in the sequence of digits ending s.
 // If s doesn't end in digits, it returns -1.
 func finalDigitsIndex(s string) int {
@@ -168,17 +200,29 @@ var UseTestData = false
 // TestCommitTime is the time used for all commits when UseTestData is true.
 var TestCommitTime = time.Date(2019, 9, 4, 1, 2, 3, 0, time.UTC)
 
-// getGoRepo returns a repo object for the Go repo at version.
-func getGoRepo(version string) (_ *git.Repository, err error) {
-	defer derrors.Wrap(&err, "getGoRepo(%q)", version)
+// testMasterVersion is the version in the testdata directory that serves as
+// the master branch when UseTestData is true.
+const testMasterVersion = "v1.14.6"
 
-	tag, err := TagForVersion(version)
-	if err != nil {
-		return nil, err
+// getGoRepo returns a repo object for the Go repo at version. For
+// MasterVersion and pseudo-versions, the repo is at the head of the master
+// branch.
+func getGoRepo(v string) (_ *git.Repository, err error) {
+	defer derrors.Wrap(&err, "getGoRepo(%q)", v)
+
+	var ref plumbing.ReferenceName
+	if v == MasterVersion || version.IsPseudo(v) {
+		ref = plumbing.NewBranchReferenceName("master")
+	} else {
+		tag, err := TagForVersion(v)
+		if err != nil {
+			return nil, err
+		}
+		ref = plumbing.NewTagReferenceName(tag)
 	}
 	return git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
 		URL:           GoRepoURL,
-		ReferenceName: plumbing.NewTagReferenceName(tag),
+		ReferenceName: ref,
 		SingleBranch:  true,
 		Depth:         1,
 		Tags:          git.NoTags,
@@ -186,10 +230,13 @@ func getGoRepo(version string) (_ *git.Repository, err error) {
 }
 
 // getTestGoRepo gets a Go repo for testing.
-func getTestGoRepo(version string) (_ *git.Repository, err error) {
-	defer derrors.Wrap(&err, "getTestGoRepo(%q)", version)
+func getTestGoRepo(v string) (_ *git.Repository, err error) {
+	defer derrors.Wrap(&err, "getTestGoRepo(%q)", v)
 
-	fs := osfs.New(filepath.Join(testhelper.TestDataPath("testdata"), version))
+	if v == MasterVersion || version.IsPseudo(v) {
+		v = testMasterVersion
+	}
+	fs := osfs.New(filepath.Join(testhelper.TestDataPath("testdata"), v))
 	repo, err := git.Init(memory.NewStorage(), fs)
 	if err != nil {
 		return nil, err
@@ -220,6 +267,9 @@ func getTestGoRepo(version string) (_ *git.Repository, err error) {
 func Versions() (_ []string, err error) {
 	defer derrors.Wrap(&err, "Versions()")
 
+	if len(LocalRoots) > 0 {
+		return localVersions()
+	}
 	var refNames []plumbing.ReferenceName
 	if UseTestData {
 		refNames = testRefs
@@ -250,9 +300,12 @@ func Versions() (_ []string, err error) {
 }
 
 // Directory returns the directory of the standard library relative to the repo root.
-func Directory(version string) string {
+func Directory(v string) string {
+	if version.IsPseudo(v) {
+		return "src"
+	}
 	// For versions older than v1.4.0-beta.1, the stdlib is in src/pkg.
-	if semver.Compare(version, "v1.4.0-beta.1") < 0 {
+	if semver.Compare(v, "v1.4.0-beta.1") < 0 {
 		return "src/pkg"
 	}
 	return "src"
@@ -261,9 +314,22 @@ func Directory(version string) string {
 // Approximate size of Zip("v1.15.2").
 const estimatedZipSize = 16 * 1024 * 1024
 
+// ZipInfo resolves requestedVersion, which may be "latest", MasterVersion, a
+// pseudo-version or a version returned by Versions, and returns it along with
+// the approximate size of its zip.
 func ZipInfo(requestedVersion string) (resolvedVersion string, zipSize int64, err error) {
 	defer derrors.Wrap(&err, "stdlib.ZipInfo(%q)", requestedVersion)
 
+	if requestedVersion == MasterVersion {
+		if len(LocalRoots) > 0 {
+			return "", 0, fmt.Errorf("no master branch for local GOROOTs: %w", derrors.NotFound)
+		}
+		resolvedVersion, err = masterVersion()
+		if err != nil {
+			return "", 0, err
+		}
+		return resolvedVersion, estimatedZipSize, nil
+	}
 	resolvedVersion, err = semanticVersion(requestedVersion)
 	if err != nil {
 		return "", 0, err
@@ -278,7 +344,11 @@ func ZipInfo(requestedVersion string) (resolvedVersion string, zipSize int64, er
 // version.
 //
 // Zip reads the standard library at the Go repository tag corresponding to to
-// the given semantic version.
+// the given semantic version, or from a local GOROOT if LocalRoots is set.
+// For a pseudo-version, Zip reads the commit of the master branch that was
+// cloned by ZipInfo to resolve it. If that clone is gone, Zip clones the head
+// of master again, and returns an error wrapping derrors.NotFound if that is
+// no longer the commit named by the pseudo-version.
 //
 // Zip ignores go.mod files in the standard library, treating it as if it were a
 // single module named "std" at the given version.
@@ -287,25 +357,26 @@ func Zip(resolvedVersion string) (_ *zip.Reader, commitTime time.Time, err error
 	// https://github.com/shurcooL/play/blob/master/256/moduleproxy/std/std.go.
 	defer derrors.Wrap(&err, "stdlib.Zip(%q)", resolvedVersion)
 
-	var repo *git.Repository
-	if UseTestData {
-		repo, err = getTestGoRepo(resolvedVersion)
+	if len(LocalRoots) > 0 {
+		return localZip(resolvedVersion)
+	}
+	var (
+		repo   *git.Repository
+		commit *object.Commit
+	)
+	if version.IsPseudo(resolvedVersion) {
+		repo, commit, err = masterCommit(resolvedVersion)
 	} else {
-		repo, err = getGoRepo(resolvedVersion)
+		repo, err = cloneGoRepo(resolvedVersion)
+		if err == nil {
+			commit, err = headCommit(repo)
+		}
 	}
 	if err != nil {
 		return nil, time.Time{}, err
 	}
 	var buf bytes.Buffer
 	z := zip.NewWriter(&buf)
-	head, err := repo.Head()
-	if err != nil {
-		return nil, time.Time{}, err
-	}
-	commit, err := repo.CommitObject(head.Hash())
-	if err != nil {
-		return nil, time.Time{}, err
-	}
 	root, err := repo.TreeObject(commit.TreeHash)
 	if err != nil {
 		return nil, time.Time{}, err
@@ -337,11 +408,104 @@ func Zip(resolvedVersion string) (_ *zip.Reader, commitTime time.Time, err error
 	return zr, commit.Committer.When, nil
 }
 
+// lastMaster holds the clone of the master branch made by the last call to
+// masterVersion, so that Zip of the pseudo-version it returns reads the same
+// commit without cloning the Go repo again. The clone is released by Zip, or
+// after lastMasterTTL if Zip is never called.
+var lastMaster struct {
+	mu      sync.Mutex
+	version string
+	repo    *git.Repository
+}
+
+// lastMasterTTL is how long the clone in lastMaster is kept if it is not
+// used. It is a variable for testing.
+var lastMasterTTL = 10 * time.Minute
+
+// releaseLastMaster releases the clone in lastMaster if it is of
+// pseudoVersion, and returns it.
+func releaseLastMaster(pseudoVersion string) *git.Repository {
+	lastMaster.mu.Lock()
+	defer lastMaster.mu.Unlock()
+	if lastMaster.version != pseudoVersion {
+		return nil
+	}
+	repo := lastMaster.repo
+	lastMaster.version = ""
+	lastMaster.repo = nil
+	return repo
+}
+
+// masterVersion clones the head of the master branch, and returns its
+// pseudo-version.
+func masterVersion() (_ string, err error) {
+	defer derrors.Wrap(&err, "masterVersion()")
+
+	repo, err := cloneGoRepo(MasterVersion)
+	if err != nil {
+		return "", err
+	}
+	commit, err := headCommit(repo)
+	if err != nil {
+		return "", err
+	}
+	v := fmt.Sprintf("v0.0.0-%s-%s", commit.Committer.When.UTC().Format("20060102150405"), commit.Hash.String()[:12])
+	lastMaster.mu.Lock()
+	defer lastMaster.mu.Unlock()
+	lastMaster.version = v
+	lastMaster.repo = repo
+	time.AfterFunc(lastMasterTTL, func() { releaseLastMaster(v) })
+	return v, nil
+}
+
+// masterCommit returns a clone of the master branch and the commit in it
+// named by pseudoVersion. It uses, and releases, the clone made by
+// masterVersion if it resolved pseudoVersion.
+func masterCommit(pseudoVersion string) (_ *git.Repository, _ *object.Commit, err error) {
+	repo := releaseLastMaster(pseudoVersion)
+	if repo == nil {
+		repo, err = cloneGoRepo(pseudoVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	commit, err := headCommit(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasPrefix(commit.Hash.String(), pseudoVersionRev(pseudoVersion)) {
+		return nil, nil, fmt.Errorf("master is now at %s: %w", commit.Hash, derrors.NotFound)
+	}
+	return repo, commit, nil
+}
+
+// cloneGoRepo returns the Go repo at version, or a test repo if UseTestData is
+// true.
+func cloneGoRepo(v string) (*git.Repository, error) {
+	if UseTestData {
+		return getTestGoRepo(v)
+	}
+	return getGoRepo(v)
+}
+
+// headCommit returns the commit at the head of repo.
+func headCommit(repo *git.Repository) (*object.Commit, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CommitObject(head.Hash())
+}
+
 // semanticVersion returns the semantic version corresponding to the
 // requestedVersion.
 func semanticVersion(requestedVersion string) (_ string, err error) {
 	defer derrors.Wrap(&err, "semanticVersion(%q)", requestedVersion)
 
+	if version.IsPseudo(requestedVersion) {
+		// Zip checks that the pseudo-version names the head of master.
+		return requestedVersion, nil
+	}
 	knownVersions, err := Versions()
 	if err != nil {
 		return "", err
@@ -378,22 +542,7 @@ func addFiles(z *zip.Writer, r *git.Repository, t *object.Tree, dirpath string,
 	defer derrors.Wrap(&err, "addFiles(zip, repository, tree, %q, %t)", dirpath, recursive)
 
 	for _, e := range t.Entries {
-		if strings.HasPrefix(e.Name, ".") || strings.HasPrefix(e.Name, "_") {
-			continue
-		}
-		if e.Name == "go.mod" {
-			// ignore; we'll synthesize our own
-			continue
-		}
-		if e.Name == "README.vendor" && !strings.Contains(dirpath, "/") {
-			// For versions newer than v1.4.0-beta.1, the stdlib is in src/pkg.
-			// This means that our construction of the zip files will return
-			// two READMEs at the root:
-			// https://golang.org/README.md and
-			// https://golang.org/src/README.vendor
-			// We only want to display the README.md, so ignore README.vendor.
-			// However, we do want to store the README.vendor in
-			// std@<version>/cmd.
+		if excludeFile(e.Name, dirpath) {
 			continue
 		}
 		switch e.Mode {
@@ -429,6 +578,30 @@ func addFiles(z *zip.Writer, r *git.Repository, t *object.Tree, dirpath string,
 	return nil
 }
 
+// excludeFile reports whether the file or directory with the given name in
+// dirpath is left out of the zip.
+func excludeFile(name, dirpath string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+	if name == "go.mod" {
+		// ignore; we'll synthesize our own
+		return true
+	}
+	if name == "README.vendor" && !strings.Contains(dirpath, "/") {
+		// For versions newer than v1.4.0-beta.1, the stdlib is in src/pkg.
+		// This means that our construction of the zip files will return
+		// two READMEs at the root:
+		// https://golang.org/README.md and
+		// https://golang.org/src/README.vendor
+		// We only want to display the README.md, so ignore README.vendor.
+		// However, we do want to store the README.vendor in
+		// std@<version>/cmd.
+		return true
+	}
+	return false
+}
+
 func writeZipFile(z *zip.Writer, pathname string, src io.Reader) (err error) {
 	defer derrors.Wrap(&err, "writeZipFile(zip, %q, src)", pathname)
 
@@ -5,12 +5,15 @@
 package stdlib
 
 import (
+	"errors"
 	"io/ioutil"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal/derrors"
 )
 
 func TestTagForVersion(t *testing.T) {
@@ -55,6 +58,11 @@ func TestTagForVersion(t *testing.T) {
 			version: "v1.13.0",
 			want:    "go1.13",
 		},
+		{
+			name:    "pseudo-version of master",
+			version: "v0.0.0-20201015141213-0123456789ab",
+			want:    "0123456789ab",
+		},
 		{
 			name:    "bad std semver",
 			version: "v1.x",
@@ -100,6 +108,7 @@ func TestMajorVersionForVersion(t *testing.T) {
 		{"v1.13.3", "go1"},
 		{"v1.9.0-rc.2", "go1"},
 		{"v2.1.3", "go2"},
+		{"v0.0.0-20201015141213-0123456789ab", "master"},
 	} {
 		got, err := MajorVersionForVersion(test.in)
 		if (err != nil) != (test.want == "") {
@@ -190,6 +199,67 @@ func TestZipInfo(t *testing.T) {
 	}
 }
 
+func TestZipMaster(t *testing.T) {
+	UseTestData = true
+	defer func() { UseTestData = false }()
+
+	gotVersion, _, err := ZipInfo(MasterVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPrefix := "v0.0.0-" + TestCommitTime.Format("20060102150405") + "-"
+	if !strings.HasPrefix(gotVersion, wantPrefix) {
+		t.Fatalf("version: got %q, want prefix %q", gotVersion, wantPrefix)
+	}
+	// Zip reads the clone made by ZipInfo, and releases it.
+	if lastMaster.version != gotVersion || lastMaster.repo == nil {
+		t.Fatalf("ZipInfo did not keep its clone of master for %s", gotVersion)
+	}
+	zr, gotTime, err := Zip(gotVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastMaster.repo != nil {
+		t.Error("Zip did not release the clone of master")
+	}
+	if !gotTime.Equal(TestCommitTime) {
+		t.Errorf("commit time: got %s, want %s", gotTime, TestCommitTime)
+	}
+	want := "std@" + gotVersion + "/errors/errors.go"
+	found := false
+	for _, f := range zr.File {
+		if f.Name == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("zip missing %q", want)
+	}
+
+	// A clone that Zip does not use is released after lastMasterTTL.
+	defer func(d time.Duration) { lastMasterTTL = d }(lastMasterTTL)
+	lastMasterTTL = time.Millisecond
+	if _, _, err := ZipInfo(MasterVersion); err != nil {
+		t.Fatal(err)
+	}
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		lastMaster.mu.Lock()
+		released := lastMaster.repo == nil
+		lastMaster.mu.Unlock()
+		if released {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("the unused clone of master was not released")
+		}
+	}
+
+	// A pseudo-version for another commit can no longer be read from master.
+	if _, _, err := Zip(wantPrefix + "0123456789ab"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("Zip of old master: got %v, want NotFound", err)
+	}
+}
+
 func TestVersions(t *testing.T) {
 	UseTestData = true
 	defer func() { UseTestData = false }()
@@ -230,6 +300,8 @@ func TestVersionForTag(t *testing.T) {
 		{"go1.0", ""},
 		{"weekly.2012-02-14", ""},
 		{"latest", "latest"},
+		{"master", "master"},
+		{"v0.0.0-20201015141213-0123456789ab", "v0.0.0-20201015141213-0123456789ab"},
 	} {
 		got := VersionForTag(test.in)
 		if got != test.want {
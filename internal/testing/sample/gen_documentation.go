@@ -47,7 +47,7 @@ func main() {
 		ResolvedVersion: sample.VersionString,
 		ModulePackages:  nil,
 	}
-	_, _, html, err := p.Render(ctx, "p", si, mi, "", "")
+	_, _, _, html, err := p.Render(ctx, "p", si, mi, "", "")
 	if err != nil {
 		log.Fatal(err)
 	}
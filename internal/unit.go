@@ -27,6 +27,12 @@ type UnitMeta struct {
 	CommitTime time.Time
 	SourceInfo *source.Info
 	HasGoMod   bool
+
+	// See the fields of the same names in ModuleInfo.
+	Deprecated          bool
+	DeprecationComment  string
+	Retracted           bool
+	RetractionRationale string
 }
 
 // IsPackage reports whether the path represents a package path.
@@ -67,9 +73,43 @@ type Documentation struct {
 	GOOS     string
 	GOARCH   string
 	Synopsis string
+	// Deprecated reports whether the package documentation contains a
+	// paragraph beginning with "Deprecated:".
+	Deprecated bool
+	// Coverage describes how much of the package's exported API is
+	// documented. It is nil if it was not computed when the package was
+	// fetched.
+	Coverage *DocCoverage
 	Source   []byte // encoded ast.Files; see godoc.Package.Encode
 }
 
+// DocCoverage summarizes how much of the exported API of one or more packages
+// has doc comments. Coverage for a module is the sum of the coverage of its
+// packages.
+type DocCoverage struct {
+	NumPackages           int // number of packages counted
+	NumMissingPackageDocs int // number of those packages without a package comment
+	NumExported           int // number of exported identifiers, including methods
+	NumDocumented         int // number of exported identifiers with doc comments
+}
+
+// Fraction returns the fraction of exported identifiers that are documented.
+// It is 1 if there are no exported identifiers.
+func (c *DocCoverage) Fraction() float64 {
+	if c.NumExported == 0 {
+		return 1
+	}
+	return float64(c.NumDocumented) / float64(c.NumExported)
+}
+
+// Add adds the counts of d to c.
+func (c *DocCoverage) Add(d *DocCoverage) {
+	c.NumPackages += d.NumPackages
+	c.NumMissingPackageDocs += d.NumMissingPackageDocs
+	c.NumExported += d.NumExported
+	c.NumDocumented += d.NumDocumented
+}
+
 // Readme is a README at the specified filepath.
 type Readme struct {
 	Filepath string
@@ -81,6 +121,8 @@ type PackageMeta struct {
 	Path              string
 	Name              string
 	Synopsis          string
+	Deprecated        bool         // see Documentation.Deprecated
+	Coverage          *DocCoverage // see Documentation.Coverage
 	IsRedistributable bool
 	Licenses          []*licenses.Metadata // metadata of applicable licenses
 }
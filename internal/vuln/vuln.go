@@ -0,0 +1,385 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vuln reads vulnerability advisories in the OSV format
+// (https://ossf.github.io/osv-schema) and matches them against module
+// versions and packages.
+package vuln
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// goEcosystem is the OSV ecosystem of Go modules.
+const goEcosystem = "Go"
+
+// An Entry is an OSV advisory. Only the fields used by pkgsite are decoded.
+type Entry struct {
+	ID        string     `json:"id"`
+	Modified  time.Time  `json:"modified"`
+	Published time.Time  `json:"published"`
+	Withdrawn *time.Time `json:"withdrawn,omitempty"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Summary   string     `json:"summary,omitempty"`
+	Details   string     `json:"details"`
+	Affected  []Affected `json:"affected"`
+	// References are links to more information, like the advisory of a
+	// vulnerability database and the fixing commit.
+	References []Reference `json:"references,omitempty"`
+
+	// Raw is the entry as it was read.
+	Raw json.RawMessage `json:"-"`
+}
+
+// Affected describes a package, which for Go is a module, that is affected by
+// the vulnerability of an Entry.
+type Affected struct {
+	Package           Package           `json:"package"`
+	Ranges            []Range           `json:"ranges,omitempty"`
+	Versions          []string          `json:"versions,omitempty"`
+	EcosystemSpecific EcosystemSpecific `json:"ecosystem_specific"`
+}
+
+// Package identifies a package of an ecosystem.
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// A Range is a range of affected versions, given by the events at which
+// versions become affected or unaffected. Versions may or may not have a
+// leading "v".
+type Range struct {
+	Type   string       `json:"type"`
+	Events []RangeEvent `json:"events"`
+}
+
+// A RangeEvent is an event of a Range. Only one of its fields is set. An
+// Introduced version of "0" means that all versions are affected up to the
+// next event.
+type RangeEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// EcosystemSpecific holds the information of Affected that is specific to Go.
+type EcosystemSpecific struct {
+	// Imports are the affected packages of the module. If there are none,
+	// the whole module is affected.
+	Imports []Import `json:"imports,omitempty"`
+}
+
+// Import is an affected package.
+type Import struct {
+	Path string `json:"path"`
+	// Symbols are the affected functions, types and methods of the package,
+	// like "Parse" or "Reader.Read". If there are none, the whole package is
+	// affected.
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// A Reference is a link to information about a vulnerability.
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// OSV names of the standard library and of the Go toolchain.
+const (
+	stdlibName    = "stdlib"
+	toolchainName = "toolchain"
+)
+
+// ModulePath returns the path of the affected module. The standard library
+// and the Go toolchain, which OSV names "stdlib" and "toolchain", are both
+// returned as the std module, since the commands of the toolchain are stored
+// as its cmd/... packages.
+func (a *Affected) ModulePath() string {
+	switch a.Package.Name {
+	case stdlibName, toolchainName:
+		return stdlib.ModulePath
+	default:
+		return a.Package.Name
+	}
+}
+
+// affectsAll reports whether all of the packages in the directory path of the
+// module modulePath are affected, given that a names no affected packages.
+// For the toolchain, those are the packages under cmd, and the directories
+// containing them.
+func (a *Affected) affectsAll(modulePath, path string) bool {
+	if a.Package.Name != toolchainName {
+		return true
+	}
+	return path == modulePath || path == "cmd" || strings.HasPrefix(path, "cmd/")
+}
+
+// AffectsVersion reports whether version v of the module is affected.
+func (a *Affected) AffectsVersion(v string) bool {
+	for _, av := range a.Versions {
+		if canonicalVersion(av) == v {
+			return true
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.isSemver() && r.affects(v) {
+			return true
+		}
+	}
+	// Without ranges or versions, all versions are affected.
+	return len(a.Ranges) == 0 && len(a.Versions) == 0
+}
+
+// FixedVersion returns the lowest version greater than v in which the
+// vulnerability is fixed, or the empty string if there is none.
+func (a *Affected) FixedVersion(v string) string {
+	var fixed string
+	for _, r := range a.Ranges {
+		if !r.isSemver() {
+			continue
+		}
+		for _, e := range r.Events {
+			f := canonicalVersion(e.Fixed)
+			if f != "" && semver.Compare(f, v) > 0 && (fixed == "" || semver.Compare(f, fixed) < 0) {
+				fixed = f
+			}
+		}
+	}
+	return fixed
+}
+
+// isSemver reports whether the events of r are versions, rather than commit
+// hashes, which are not supported.
+func (r *Range) isSemver() bool {
+	return r.Type == "SEMVER" || r.Type == "ECOSYSTEM"
+}
+
+// affects reports whether v is in r. The events of r are applied in order of
+// their versions.
+func (r *Range) affects(v string) bool {
+	events := append([]RangeEvent(nil), r.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return semver.Compare(events[i].version(), events[j].version()) < 0
+	})
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || semver.Compare(v, canonicalVersion(e.Introduced)) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if semver.Compare(v, canonicalVersion(e.Fixed)) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if semver.Compare(v, canonicalVersion(e.LastAffected)) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+// version returns the version of e, or the empty string, which sorts first,
+// if all versions are introduced.
+func (e RangeEvent) version() string {
+	for _, v := range []string{e.Introduced, e.Fixed, e.LastAffected} {
+		if v != "" && v != "0" {
+			return canonicalVersion(v)
+		}
+	}
+	return ""
+}
+
+// canonicalVersion returns v with a leading "v", as used by pkgsite and the
+// semver package.
+func canonicalVersion(v string) string {
+	if v == "" || strings.HasPrefix(v, "v") {
+		return v
+	}
+	return "v" + v
+}
+
+// Affects reports whether the version of the module modulePath is affected by
+// e in the package or directory path, which may be modulePath itself. If the
+// affected packages are known, only they and the directories containing them
+// are affected. It also returns the affected symbols of the package at path,
+// which are empty if it is a directory or if all of its symbols are affected.
+func (e *Entry) Affects(modulePath, version, path string) (symbols []string, affected bool) {
+	if e.Withdrawn != nil {
+		return nil, false
+	}
+	for i := range e.Affected {
+		a := &e.Affected[i]
+		if a.Package.Ecosystem != goEcosystem || a.ModulePath() != modulePath || !a.AffectsVersion(version) {
+			continue
+		}
+		if len(a.EcosystemSpecific.Imports) == 0 {
+			if a.affectsAll(modulePath, path) {
+				return nil, true
+			}
+			continue
+		}
+		for _, imp := range a.EcosystemSpecific.Imports {
+			switch {
+			case imp.Path == path:
+				symbols = append(symbols, imp.Symbols...)
+				affected = true
+			case path == modulePath || strings.HasPrefix(imp.Path, path+"/"):
+				affected = true
+			}
+		}
+	}
+	return symbols, affected
+}
+
+// FixedVersion returns the lowest version of modulePath greater than
+// version in which e is fixed, or the empty string if there is none.
+func (e *Entry) FixedVersion(modulePath, version string) string {
+	var fixed string
+	for i := range e.Affected {
+		a := &e.Affected[i]
+		if a.ModulePath() != modulePath {
+			continue
+		}
+		if f := a.FixedVersion(version); f != "" && (fixed == "" || semver.Compare(f, fixed) < 0) {
+			fixed = f
+		}
+	}
+	return fixed
+}
+
+// ModulePaths returns the sorted paths of the Go modules affected by e.
+func (e *Entry) ModulePaths() []string {
+	seen := map[string]bool{}
+	var paths []string
+	for i := range e.Affected {
+		a := &e.Affected[i]
+		if a.Package.Ecosystem != goEcosystem || seen[a.ModulePath()] {
+			continue
+		}
+		seen[a.ModulePath()] = true
+		paths = append(paths, a.ModulePath())
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// URL returns a link to the advisory of e: its ADVISORY reference, or else
+// its first reference. It returns the empty string if e has no references.
+func (e *Entry) URL() string {
+	for _, r := range e.References {
+		if r.Type == "ADVISORY" {
+			return r.URL
+		}
+	}
+	if len(e.References) > 0 {
+		return e.References[0].URL
+	}
+	return ""
+}
+
+// Parse parses an OSV entry.
+func Parse(data []byte) (_ *Entry, err error) {
+	defer derrors.Wrap(&err, "vuln.Parse")
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.ID == "" {
+		return nil, fmt.Errorf("missing id: %w", derrors.InvalidArgument)
+	}
+	e.Raw = append(json.RawMessage(nil), data...)
+	return &e, nil
+}
+
+// ReadDir reads the OSV entries in the tree rooted at dir, like a checkout
+// of a vulnerability database. Every file whose name ends in ".json" and
+// whose contents are a JSON object with an "id" field, or an array of such
+// objects, is read; other JSON files, like indexes, are ignored. If an entry
+// appears more than once, the most recently modified copy is kept. The
+// entries are sorted by ID.
+func ReadDir(dir string) (_ []*Entry, err error) {
+	defer derrors.Wrap(&err, "vuln.ReadDir(%q)", dir)
+
+	byID := map[string]*Entry{}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		entries, err := parseFile(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		for _, e := range entries {
+			if prev := byID[e.ID]; prev == nil || e.Modified.After(prev.Modified) {
+				byID[e.ID] = e
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, e := range byID {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// parseFile returns the OSV entries in a JSON file, which may hold one entry
+// or an array of them. It returns no entries for other JSON values.
+func parseFile(data []byte) ([]*Entry, error) {
+	var raws []json.RawMessage
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		raws = []json.RawMessage{trimmed}
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	var entries []*Entry
+	for _, raw := range raws {
+		var probe struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil || probe.ID == "" {
+			// Not an entry.
+			continue
+		}
+		e, err := Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vuln
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadDir(t *testing.T) {
+	entries, err := ReadDir("testdata/vulndb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"GO-2020-0001", "GO-2020-0002", "GO-2020-0003"}; !cmp.Equal(ids, want) {
+		t.Fatalf("got IDs %v, want %v", ids, want)
+	}
+	// GO-2020-0002 appears twice; the most recently modified copy is kept.
+	if got, want := entries[1].Details, "All versions of example.com/b are affected. The advisory was updated."; got != want {
+		t.Errorf("GO-2020-0002: got details %q, want %q", got, want)
+	}
+	if len(entries[0].Raw) == 0 {
+		t.Error("GO-2020-0001: Raw is empty")
+	}
+}
+
+func TestAffects(t *testing.T) {
+	entries, err := ReadDir("testdata/vulndb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]*Entry{}
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+	for _, test := range []struct {
+		id, modulePath, version, path string
+		wantAffected                  bool
+		wantSymbols                   []string
+		wantFixed                     string
+	}{
+		{"GO-2020-0001", "example.com/a", "v1.1.0", "example.com/a/internal/parse", true, []string{"Parse", "Reader.Read"}, "v1.2.0"},
+		{"GO-2020-0001", "example.com/a", "v1.2.0", "example.com/a/internal/parse", false, nil, "v1.3.2"},
+		{"GO-2020-0001", "example.com/a", "v1.3.1", "example.com/a/internal/parse", true, []string{"Parse", "Reader.Read"}, "v1.3.2"},
+		{"GO-2020-0001", "example.com/a", "v1.3.2", "example.com/a/internal/parse", false, nil, ""},
+		{"GO-2020-0001", "example.com/a", "v1.1.0", "example.com/a/internal", true, nil, "v1.2.0"},
+		{"GO-2020-0001", "example.com/a", "v1.1.0", "example.com/a", true, nil, "v1.2.0"},
+		{"GO-2020-0001", "example.com/a", "v1.1.0", "example.com/a/other", false, nil, "v1.2.0"},
+		{"GO-2020-0001", "example.com/b", "v1.1.0", "example.com/b", false, nil, ""},
+		{"GO-2020-0002", "example.com/b", "v0.1.0", "example.com/b/sub", true, nil, ""},
+		{"GO-2020-0003", "example.com/b", "v0.1.0", "example.com/b", false, nil, ""},
+	} {
+		e := byID[test.id]
+		gotSymbols, gotAffected := e.Affects(test.modulePath, test.version, test.path)
+		if gotAffected != test.wantAffected || !cmp.Equal(gotSymbols, test.wantSymbols) {
+			t.Errorf("%s.Affects(%q, %q, %q) = %v, %t; want %v, %t", test.id, test.modulePath, test.version, test.path,
+				gotSymbols, gotAffected, test.wantSymbols, test.wantAffected)
+		}
+		if got := e.FixedVersion(test.modulePath, test.version); got != test.wantFixed {
+			t.Errorf("%s.FixedVersion(%q, %q) = %q, want %q", test.id, test.modulePath, test.version, got, test.wantFixed)
+		}
+	}
+}
+
+func TestAffectsToolchain(t *testing.T) {
+	e, err := Parse([]byte(`{"id": "GO-1", "affected": [{"package": {"ecosystem": "Go", "name": "toolchain"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.ModulePaths(), []string{"std"}; !cmp.Equal(got, want) {
+		t.Errorf("ModulePaths() = %v, want %v", got, want)
+	}
+	for _, test := range []struct {
+		path string
+		want bool
+	}{
+		{"std", true},
+		{"cmd", true},
+		{"cmd/go", true},
+		{"cmd/go/internal/get", true},
+		{"net/http", false},
+		{"command", false},
+	} {
+		if _, got := e.Affects("std", "v1.15.0", test.path); got != test.want {
+			t.Errorf("Affects(%q) = %t, want %t", test.path, got, test.want)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	for _, test := range []struct {
+		refs []Reference
+		want string
+	}{
+		{nil, ""},
+		{[]Reference{{Type: "FIX", URL: "f"}}, "f"},
+		{[]Reference{{Type: "FIX", URL: "f"}, {Type: "ADVISORY", URL: "a"}}, "a"},
+	} {
+		e := &Entry{References: test.refs}
+		if got := e.URL(); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.refs, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse([]byte(`{"details": "no id"}`)); err == nil {
+		t.Error("got nil error for entry without id")
+	}
+	e, err := Parse([]byte(`{"id": "GO-1", "affected": [{"package": {"ecosystem": "Go", "name": "stdlib"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.ModulePaths(), []string{"std"}; !cmp.Equal(got, want) {
+		t.Errorf("ModulePaths() = %v, want %v", got, want)
+	}
+}
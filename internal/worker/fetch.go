@@ -23,6 +23,8 @@ import (
 	"golang.org/x/pkgsite/internal/postgres"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/version"
 )
 
 // ProxyRemoved is a set of module@version that have been removed from the proxy,
@@ -191,6 +193,18 @@ func (f *Fetcher) fetchAndInsertModule(ctx context.Context, modulePath, requeste
 		return ft
 	}
 	log.Infof(ctx, "db.InsertModule succeeded for %s@%s", ft.ModulePath, ft.RequestedVersion)
+	if ft.ModulePath == stdlib.ModulePath && version.IsPseudo(ft.ResolvedVersion) {
+		// A pseudo-version of the standard library is a build of the master
+		// branch, and replaces the previous ones.
+		start = time.Now()
+		deleted, err := f.DB.DeleteOldStdlibMasterVersions(ctx, ft.ResolvedVersion)
+		ft.timings["db.DeleteOldStdlibMasterVersions"] = time.Since(start)
+		if err != nil {
+			log.Errorf(ctx, "deleting old builds of std@master: %v", err)
+		} else if len(deleted) > 0 {
+			log.Infof(ctx, "deleted old builds of std@master: %s", strings.Join(deleted, ", "))
+		}
+	}
 	return ft
 }
 
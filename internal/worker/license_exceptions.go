@@ -0,0 +1,204 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/poller"
+	"golang.org/x/pkgsite/internal/postgres"
+	"google.golang.org/api/idtoken"
+)
+
+// iapJWTHeader is the header in which the Identity-Aware Proxy passes a JWT
+// that identifies the authenticated user.
+// See https://cloud.google.com/iap/docs/signed-headers-howto.
+const iapJWTHeader = "X-Goog-IAP-JWT-Assertion"
+
+// validateIDToken validates an ID token and returns its payload. It is a
+// variable so that tests can replace it.
+var validateIDToken = idtoken.Validate
+
+// PollLicenseExceptions loads the license exceptions of db into the licenses
+// package, and reloads them at the given period until ctx is done, so that
+// exceptions added through any worker instance are applied by all of them.
+func PollLicenseExceptions(ctx context.Context, db *postgres.DB, period time.Duration) {
+	p := poller.New(
+		[]*licenses.Exception(nil),
+		func(ctx context.Context) (interface{}, error) {
+			return loadLicenseExceptions(ctx, db)
+		},
+		func(err error) {
+			log.Errorf(ctx, "loading license exceptions: %v", err)
+		})
+	p.Poll(ctx)
+	p.Start(ctx, period)
+}
+
+// loadLicenseExceptions reads the license exceptions of db and passes them to
+// licenses.SetExceptions.
+func loadLicenseExceptions(ctx context.Context, db *postgres.DB) ([]*licenses.Exception, error) {
+	excs, err := db.GetLicenseExceptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	licenses.SetExceptions(excs)
+	return excs, nil
+}
+
+// handleLicenseExceptions lists the license exceptions, one per line.
+func (s *Server) handleLicenseExceptions(w http.ResponseWriter, r *http.Request) error {
+	excs, err := s.db.GetLicenseExceptions(r.Context())
+	if err != nil {
+		return err
+	}
+	for _, e := range excs {
+		fmt.Fprintf(w, "%s %s %s by %s: %s\n", e.Key, strings.Join(e.Types, ","),
+			e.CreatedAt.Format(time.RFC3339), e.Reviewer, e.Reason)
+	}
+	return nil
+}
+
+// handleAddLicenseException adds an exception for the license file whose key
+// is the "key" param, giving it the comma-separated license types of the
+// "types" param, and schedules the module versions that have the file for
+// reprocessing. The "reason" param is required. The reviewer is the user
+// authenticated by the IAP, whose JWT must be valid for the audience of the
+// worker's configuration; requests without one are rejected.
+func (s *Server) handleAddLicenseException(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return &serverError{http.StatusMethodNotAllowed, errors.New("must use POST")}
+	}
+	ctx := r.Context()
+	reviewer, err := iapUser(ctx, r, s.cfg.IAPAudience)
+	if err != nil {
+		return &serverError{http.StatusUnauthorized, err}
+	}
+	e, err := parseLicenseException(r, reviewer)
+	if err != nil {
+		return &serverError{http.StatusBadRequest, err}
+	}
+	if err := s.db.InsertLicenseException(ctx, e); err != nil {
+		return err
+	}
+	log.Infof(ctx, "%s added license exception %s (%s): %s", e.Reviewer, e.Key, strings.Join(e.Types, ", "), e.Reason)
+	n, err := s.applyLicenseExceptions(ctx, e.Key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Added license exception %s. Scheduled %d module versions to be reprocessed.", e.Key, n)
+	return nil
+}
+
+// handleDeleteLicenseException deletes the exception for the license file
+// whose key is the "key" param, and schedules the module versions that have
+// the file for reprocessing. Like handleAddLicenseException, it requires a
+// user authenticated by the IAP.
+func (s *Server) handleDeleteLicenseException(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		return &serverError{http.StatusMethodNotAllowed, errors.New("must use POST")}
+	}
+	ctx := r.Context()
+	reviewer, err := iapUser(ctx, r, s.cfg.IAPAudience)
+	if err != nil {
+		return &serverError{http.StatusUnauthorized, err}
+	}
+	key, err := parseExceptionKey(r.FormValue("key"))
+	if err != nil {
+		return &serverError{http.StatusBadRequest, err}
+	}
+	if err := s.db.DeleteLicenseException(ctx, key); err != nil {
+		if errors.Is(err, derrors.NotFound) {
+			return &serverError{http.StatusNotFound, err}
+		}
+		return err
+	}
+	log.Infof(ctx, "%s deleted license exception %s", reviewer, key)
+	n, err := s.applyLicenseExceptions(ctx, key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Deleted license exception %s. Scheduled %d module versions to be reprocessed.", key, n)
+	return nil
+}
+
+// applyLicenseExceptions reloads the license exceptions, so that this
+// instance applies a change to the exception for key right away, and
+// schedules the module versions with a license file whose key is key for
+// reprocessing. It returns the number of module versions scheduled.
+func (s *Server) applyLicenseExceptions(ctx context.Context, key string) (int64, error) {
+	if _, err := loadLicenseExceptions(ctx, s.db); err != nil {
+		return 0, err
+	}
+	return s.db.UpdateModuleVersionStatesForLicenseKey(ctx, key)
+}
+
+// iapUser returns the email address of the user authenticated by the IAP,
+// from the JWT in the iapJWTHeader header of r. It returns an error if
+// audience is empty, or if the JWT is missing or is not valid for audience.
+func iapUser(ctx context.Context, r *http.Request, audience string) (string, error) {
+	if audience == "" {
+		return "", errors.New("the IAP audience is not configured")
+	}
+	token := r.Header.Get(iapJWTHeader)
+	if token == "" {
+		return "", errors.New("missing IAP token")
+	}
+	payload, err := validateIDToken(ctx, token, audience)
+	if err != nil {
+		return "", fmt.Errorf("validating IAP token: %v", err)
+	}
+	email, _ := payload.Claims["email"].(string)
+	if email == "" {
+		return "", errors.New("IAP token has no email claim")
+	}
+	return email, nil
+}
+
+// parseLicenseException returns the exception described by the params of r,
+// reviewed by reviewer.
+func parseLicenseException(r *http.Request, reviewer string) (*licenses.Exception, error) {
+	key, err := parseExceptionKey(r.FormValue("key"))
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	for _, t := range strings.Split(r.FormValue("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return nil, errors.New("types was not specified")
+	}
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	if reason == "" {
+		return nil, errors.New("reason was not specified")
+	}
+	return &licenses.Exception{Key: key, Types: types, Reviewer: reviewer, Reason: reason}, nil
+}
+
+// parseExceptionKey checks that key is a license exception key, as returned
+// by licenses.ExceptionKey, and returns it in lower case.
+func parseExceptionKey(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("key was not specified")
+	}
+	b, err := hex.DecodeString(key)
+	if err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("key is invalid: %q", key)
+	}
+	return strings.ToLower(key), nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/licenses"
+	"google.golang.org/api/idtoken"
+)
+
+func TestParseLicenseException(t *testing.T) {
+	key := licenses.ExceptionKey([]byte("license"))
+	for _, test := range []struct {
+		name   string
+		params url.Values
+		want   *licenses.Exception // nil if the params are invalid
+	}{
+		{
+			name:   "valid",
+			params: url.Values{"key": {strings.ToUpper(key)}, "types": {"MIT, BSD-3-Clause"}, "reason": {"reviewed"}},
+			want:   &licenses.Exception{Key: key, Types: []string{"MIT", "BSD-3-Clause"}, Reviewer: "admin@example.com", Reason: "reviewed"},
+		},
+		{
+			name:   "reviewer param is ignored",
+			params: url.Values{"key": {key}, "types": {"MIT"}, "reason": {"reviewed"}, "reviewer": {"gopher"}},
+			want:   &licenses.Exception{Key: key, Types: []string{"MIT"}, Reviewer: "admin@example.com", Reason: "reviewed"},
+		},
+		{
+			name:   "bad key",
+			params: url.Values{"key": {"abc"}, "types": {"MIT"}, "reason": {"reviewed"}},
+		},
+		{
+			name:   "no types",
+			params: url.Values{"key": {key}, "types": {" , "}, "reason": {"reviewed"}},
+		},
+		{
+			name:   "no reason",
+			params: url.Values{"key": {key}, "types": {"MIT"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/license-exceptions/add", strings.NewReader(test.params.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			got, err := parseLicenseException(r, "admin@example.com")
+			if test.want == nil {
+				if err == nil {
+					t.Errorf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIAPUser(t *testing.T) {
+	const audience = "/projects/1/apps/worker"
+	defer func(v func(context.Context, string, string) (*idtoken.Payload, error)) { validateIDToken = v }(validateIDToken)
+	validateIDToken = func(_ context.Context, token, aud string) (*idtoken.Payload, error) {
+		if aud != audience {
+			return nil, fmt.Errorf("audience %q does not match", aud)
+		}
+		switch token {
+		case "valid":
+			return &idtoken.Payload{Claims: map[string]interface{}{"email": "admin@example.com"}}, nil
+		case "no email":
+			return &idtoken.Payload{Claims: map[string]interface{}{}}, nil
+		default:
+			return nil, errors.New("invalid token")
+		}
+	}
+
+	for _, test := range []struct {
+		name, token, audience string
+		want                  string // empty if the request must be rejected
+	}{
+		{"valid", "valid", audience, "admin@example.com"},
+		{"no token", "", audience, ""},
+		{"invalid token", "forged", audience, ""},
+		{"no email", "no email", audience, ""},
+		{"wrong audience", "valid", "/projects/2/apps/worker", ""},
+		{"no audience", "valid", "", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/license-exceptions/add", nil)
+			// The unsigned header set by the IAP is never trusted.
+			r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:gopher@example.com")
+			if test.token != "" {
+				r.Header.Set(iapJWTHeader, test.token)
+			}
+			got, err := iapUser(context.Background(), r, test.audience)
+			if test.want == "" {
+				if err == nil {
+					t.Errorf("got %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLicenseExceptionHandlersRequireIAPUser(t *testing.T) {
+	// The handlers must reject the request before they use the database,
+	// which s does not have.
+	s := &Server{cfg: &config.Config{IAPAudience: "/projects/1/apps/worker"}}
+	key := licenses.ExceptionKey([]byte("license"))
+	params := url.Values{"key": {key}, "types": {"MIT"}, "reason": {"reviewed"}}
+	for _, test := range []struct {
+		name    string
+		handler func(http.ResponseWriter, *http.Request) error
+	}{
+		{"add", s.handleAddLicenseException},
+		{"delete", s.handleDeleteLicenseException},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/license-exceptions/"+test.name, strings.NewReader(params.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("X-Goog-Authenticated-User-Email", "accounts.google.com:gopher@example.com")
+			err := test.handler(httptest.NewRecorder(), r)
+			var serr *serverError
+			if !errors.As(err, &serr) || serr.status != http.StatusUnauthorized {
+				t.Errorf("got %v, want status %d", err, http.StatusUnauthorized)
+			}
+		})
+	}
+}
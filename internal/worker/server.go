@@ -142,6 +142,11 @@ func (s *Server) Install(handle func(string, http.Handler)) {
 	// set(s) used in auto-completion.
 	handle("/update-redis-indexes", rmw(s.errorHandler(s.handleUpdateRedisIndexes)))
 
+	// scheduled: update-vulns reads the vulnerability database in the
+	// directory of the VulnDBDir config field, and makes the vulns table
+	// match it.
+	handle("/update-vulns", rmw(s.errorHandler(s.handleUpdateVulns)))
+
 	// task-queue: fetch fetches a module version from the Module Mirror, and
 	// processes the contents, and inserts it into the database. If a fetch
 	// request fails for any reason other than an http.StatusInternalServerError,
@@ -183,10 +188,15 @@ func (s *Server) Install(handle func(string, http.Handler)) {
 	// manual: populate-stdlib inserts all modules of the Go standard
 	// library into the tasks queue to be processed and inserted into the
 	// database. handlePopulateStdLib should be updated whenever a new
-	// version of Go is released.
+	// version of Go is released. The head of the master branch is included.
 	// see the comments on duplicate tasks for "/requeue", above.
 	handle("/populate-stdlib", rmw(s.errorHandler(s.handlePopulateStdLib)))
 
+	// scheduled: refresh-stdlib-master inserts the head of the master branch
+	// of the Go repo into the tasks queue, so that std@master follows it.
+	// see the comments on duplicate tasks for "/requeue", above.
+	handle("/refresh-stdlib-master", rmw(s.errorHandler(s.handleRefreshStdLibMaster)))
+
 	// manual: populate-search-documents repopulates every row in the
 	// search_documents table that was last updated before the time in the
 	// "before" query parameter.
@@ -198,6 +208,15 @@ func (s *Server) Install(handle func(string, http.Handler)) {
 	// manual: delete the specified module version.
 	handle("/delete/", http.StripPrefix("/delete", rmw(s.errorHandler(s.handleDelete))))
 
+	// manual: license-exceptions lists the license files that were reviewed
+	// and found acceptable although they are not detected as such.
+	// license-exceptions/add adds such a file, identified by its key (see
+	// licenses.ExceptionKey), and license-exceptions/delete deletes one. Both
+	// schedule the module versions with the file for reprocessing.
+	handle("/license-exceptions", rmw(s.errorHandler(s.handleLicenseExceptions)))
+	handle("/license-exceptions/add", rmw(s.errorHandler(s.handleAddLicenseException)))
+	handle("/license-exceptions/delete", rmw(s.errorHandler(s.handleDeleteLicenseException)))
+
 	handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(s.staticPath.String()))))
 
 	// returns an HTML page displaying information about recent versions that were processed.
@@ -445,6 +464,11 @@ func (s *Server) doPopulateStdLib(ctx context.Context, suffix string) (string, e
 	if err != nil {
 		return "", err
 	}
+	// There is no master branch to fetch when the standard library is read
+	// from local GOROOTs.
+	if len(stdlib.LocalRoots) == 0 {
+		versions = append(versions, stdlib.MasterVersion)
+	}
 	for _, v := range versions {
 		if _, err := s.queue.ScheduleFetch(ctx, stdlib.ModulePath, v, suffix, false); err != nil {
 			return "", fmt.Errorf("error scheduling fetch for %s: %w", v, err)
@@ -453,6 +477,18 @@ func (s *Server) doPopulateStdLib(ctx context.Context, suffix string) (string, e
 	return fmt.Sprintf("Scheduling modules to be fetched: %s.\n", strings.Join(versions, ", ")), nil
 }
 
+func (s *Server) handleRefreshStdLibMaster(w http.ResponseWriter, r *http.Request) error {
+	if len(stdlib.LocalRoots) > 0 {
+		_, _ = io.WriteString(w, "The standard library is read from local GOROOTs, which have no master branch.\n")
+		return nil
+	}
+	if _, err := s.queue.ScheduleFetch(r.Context(), stdlib.ModulePath, stdlib.MasterVersion, r.FormValue("suffix"), false); err != nil {
+		return fmt.Errorf("error scheduling fetch for %s@%s: %w", stdlib.ModulePath, stdlib.MasterVersion, err)
+	}
+	_, _ = io.WriteString(w, "Scheduling std@master to be fetched.\n")
+	return nil
+}
+
 func (s *Server) handleReprocess(w http.ResponseWriter, r *http.Request) error {
 	appVersion := r.FormValue("app_version")
 	if appVersion == "" {
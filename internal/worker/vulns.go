@@ -0,0 +1,36 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// handleUpdateVulns reads the advisories of the vulnerability database in
+// s.cfg.VulnDBDir and stores them, so that they are shown by the frontend.
+// Advisories that are no longer in the database are removed.
+func (s *Server) handleUpdateVulns(w http.ResponseWriter, r *http.Request) error {
+	if s.cfg.VulnDBDir == "" {
+		return errors.New("vulnerability database directory is not configured")
+	}
+	entries, err := vuln.ReadDir(s.cfg.VulnDBDir)
+	if err != nil {
+		return err
+	}
+	ctx := r.Context()
+	changed, removed, err := s.db.UpdateVulnEntries(ctx, entries)
+	if err != nil {
+		return err
+	}
+	log.Infof(ctx, "updated vulnerabilities from %s: %d entries, %d added or changed, %d removed",
+		s.cfg.VulnDBDir, len(entries), changed, removed)
+	fmt.Fprintf(w, "Read %d advisories: %d added or changed, %d removed.", len(entries), changed, removed)
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package zipio reads and writes zip files on disk.
+package zipio
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// ReadFile reads the zip in filename into memory. If the file does not exist,
+// the error satisfies os.IsNotExist.
+func ReadFile(filename string) (*zip.Reader, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// WriteFile writes the files of zr to a zip in filename, creating its
+// directory if necessary. It writes to a temporary file in the same directory
+// first and then renames it, so that concurrent readers never see a partial
+// zip.
+func WriteFile(filename string, zr *zip.Reader) (err error) {
+	defer derrors.Wrap(&err, "zipio.WriteFile(%q)", filename)
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(filename), "tmp-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	zw := zip.NewWriter(f)
+	for _, zf := range zr.File {
+		if err := copyFile(zw, zf); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// copyFile copies zf to zw, keeping its header.
+func copyFile(zw *zip.Writer, zf *zip.File) error {
+	hdr := zf.FileHeader
+	w, err := zw.CreateHeader(&hdr)
+	if err != nil {
+		return err
+	}
+	r, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
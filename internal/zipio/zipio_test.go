@@ -0,0 +1,82 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package zipio
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteReadFile(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"m@v1.0.0/go.mod": "module m\n",
+		"m@v1.0.0/m.go":   "package m\n",
+	}
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "zipio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "m", "@v", "v1.0.0.zip")
+
+	if _, err := ReadFile(filename); !os.IsNotExist(err) {
+		t.Fatalf("ReadFile before WriteFile: got %v, want a not-exist error", err)
+	}
+	if err := WriteFile(filename, zr); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.File) != len(files) {
+		t.Fatalf("got %d files, want %d", len(got.File), len(files))
+	}
+	for _, f := range got.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := files[f.Name]; string(b) != want {
+			t.Errorf("%s: got %q, want %q", f.Name, b, want)
+		}
+	}
+	// Only the zip remains in the directory; the temporary file was renamed.
+	entries, err := ioutil.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files in the directory, want 1", len(entries))
+	}
+}
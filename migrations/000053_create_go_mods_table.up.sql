@@ -0,0 +1,20 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE go_mods (
+    module_id INTEGER NOT NULL PRIMARY KEY REFERENCES modules(id) ON DELETE CASCADE,
+    go_version TEXT NOT NULL,
+    requires JSONB NOT NULL,
+    replaces JSONB NOT NULL,
+    excludes JSONB NOT NULL,
+    retracts JSONB NOT NULL
+);
+COMMENT ON TABLE go_mods IS
+'TABLE go_mods contains the directives of the go.mod file for a module version. Modules without a go.mod file have no row.';
+COMMENT ON COLUMN go_mods.go_version IS
+'COLUMN go_version is the version from the go directive, or the empty string if there is none.';
+
+END;
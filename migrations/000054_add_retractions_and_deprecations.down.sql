@@ -0,0 +1,21 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP FUNCTION popular_search(text, integer, integer, real, real, real);
+
+ALTER TABLE search_documents DROP COLUMN deprecated;
+
+ALTER TABLE modules
+    DROP COLUMN deprecated,
+    DROP COLUMN deprecation_comment,
+    DROP COLUMN retracted,
+    DROP COLUMN retraction_rationale;
+
+ALTER TABLE go_mods
+    DROP COLUMN deprecated,
+    DROP COLUMN deprecation_comment;
+
+END;
@@ -0,0 +1,14 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE documentation ADD COLUMN deprecated BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN documentation.deprecated IS
+'COLUMN deprecated says whether the package documentation contains a paragraph beginning with "Deprecated:".';
+
+COMMENT ON COLUMN search_documents.deprecated IS
+'COLUMN deprecated says whether the module or the package is deprecated. Deprecated packages are ranked lower in search.';
+
+END;
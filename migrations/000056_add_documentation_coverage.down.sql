@@ -0,0 +1,14 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE search_documents DROP COLUMN doc_coverage;
+
+ALTER TABLE documentation
+    DROP COLUMN has_package_doc,
+    DROP COLUMN num_exported,
+    DROP COLUMN num_documented;
+
+END;
@@ -0,0 +1,20 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE documentation
+    ADD COLUMN has_package_doc BOOLEAN,
+    ADD COLUMN num_exported INTEGER,
+    ADD COLUMN num_documented INTEGER;
+COMMENT ON COLUMN documentation.num_exported IS
+'COLUMN num_exported is the number of exported identifiers in the package. It is NULL if documentation coverage was not computed when the package was processed.';
+COMMENT ON COLUMN documentation.num_documented IS
+'COLUMN num_documented is the number of exported identifiers in the package that have doc comments.';
+
+ALTER TABLE search_documents ADD COLUMN doc_coverage REAL;
+COMMENT ON COLUMN search_documents.doc_coverage IS
+'COLUMN doc_coverage is the fraction of exported identifiers in the package that have doc comments, or NULL if unknown.';
+
+END;
@@ -0,0 +1,20 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE source_discovery (
+    module_path TEXT NOT NULL PRIMARY KEY,
+    data JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE source_discovery IS
+'TABLE source_discovery caches the results of fetching the go-import and go-source meta tags of module paths, so that they are not fetched for every version of a module.';
+COMMENT ON COLUMN source_discovery.data IS
+'COLUMN data holds the repo and URL templates from the meta tags, or the reason no meta tags were found.';
+COMMENT ON COLUMN source_discovery.expires_at IS
+'COLUMN expires_at is the time after which the row is ignored and the meta tags are fetched again.';
+
+END;
@@ -0,0 +1,25 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE license_changes (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    file_path TEXT NOT NULL,
+    previous_module_path TEXT NOT NULL,
+    previous_version TEXT NOT NULL,
+    old_types TEXT[],
+    new_types TEXT[],
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (module_id, file_path)
+);
+CREATE INDEX idx_license_changes_created_at ON license_changes(created_at DESC);
+COMMENT ON TABLE license_changes IS
+'TABLE license_changes contains the license files of a module version that were added, removed or whose license types changed, compared with the previous version in the same series.';
+COMMENT ON COLUMN license_changes.old_types IS
+'COLUMN old_types holds the license types of the file in the previous version, or NULL if the file was added.';
+COMMENT ON COLUMN license_changes.new_types IS
+'COLUMN new_types holds the license types of the file in this version, or NULL if the file was removed.';
+
+END;
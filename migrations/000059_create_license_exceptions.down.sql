@@ -0,0 +1,11 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+DROP TABLE license_exceptions;
+DROP INDEX idx_licenses_contents_key;
+ALTER TABLE licenses DROP COLUMN contents_key;
+
+END;
@@ -0,0 +1,22 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE licenses ADD COLUMN contents_key TEXT;
+CREATE INDEX idx_licenses_contents_key ON licenses(contents_key);
+COMMENT ON COLUMN licenses.contents_key IS
+'COLUMN contents_key holds the hex-encoded SHA-256 of the normalized contents of the license file, as computed by licenses.ExceptionKey. It is kept when the contents are not stored, so that the file can be matched with license_exceptions.';
+
+CREATE TABLE license_exceptions (
+    contents_key TEXT PRIMARY KEY,
+    types TEXT[] NOT NULL,
+    reviewer TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE license_exceptions IS
+'TABLE license_exceptions contains license files that were reviewed and found acceptable, although they are not detected as such. A license file whose contents_key is in the table has the license types of its row.';
+
+END;
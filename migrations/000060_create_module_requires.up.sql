@@ -0,0 +1,25 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_requires (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    required_path TEXT NOT NULL,
+    required_version TEXT NOT NULL,
+    indirect BOOLEAN NOT NULL,
+    PRIMARY KEY (module_id, required_path, required_version)
+);
+CREATE INDEX idx_module_requires_required_path_version ON module_requires(required_path, required_version);
+COMMENT ON TABLE module_requires IS
+'TABLE module_requires contains the edges of the module requirement graph: a row for each require directive in the go.mod file of a module version.';
+
+INSERT INTO module_requires (module_id, required_path, required_version, indirect)
+SELECT DISTINCT ON (g.module_id, r->>'ModulePath', r->>'Version')
+    g.module_id, r->>'ModulePath', r->>'Version', COALESCE((r->>'Indirect')::BOOLEAN, FALSE)
+FROM go_mods g,
+    -- A go.mod file without requirements has null requires.
+    jsonb_array_elements(CASE WHEN jsonb_typeof(g.requires) = 'array' THEN g.requires ELSE '[]' END) r;
+
+END;
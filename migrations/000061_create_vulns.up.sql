@@ -0,0 +1,24 @@
+-- Copyright 2020 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE vulns (
+    id TEXT PRIMARY KEY,
+    modified TIMESTAMP WITH TIME ZONE NOT NULL,
+    entry JSONB NOT NULL
+);
+COMMENT ON TABLE vulns IS
+'TABLE vulns contains the vulnerability advisories of the vulnerability database, in the OSV format.';
+
+CREATE TABLE vuln_modules (
+    vuln_id TEXT NOT NULL REFERENCES vulns(id) ON DELETE CASCADE,
+    module_path TEXT NOT NULL,
+    PRIMARY KEY (vuln_id, module_path)
+);
+CREATE INDEX idx_vuln_modules_module_path ON vuln_modules(module_path);
+COMMENT ON TABLE vuln_modules IS
+'TABLE vuln_modules contains the modules affected by each advisory of the vulns table, so that the advisories of a module can be found.';
+
+END;